@@ -57,6 +57,19 @@ const (
 	ErrOperateCode                 = 10119
 	ErrForbiddenCode               = 10120
 	ErrUnprocessableCode           = 10121
+	ErrOrderPriceChangedCode       = 10122
+	ErrCouponUnavailableCode       = 10123
+	ErrOrderStatusIllegalCode      = 10124
+	ErrOrderStatusChangedCode      = 10125
+	ErrStockNotEnoughCode          = 10126
+	ErrOrderNotPayableCode         = 10127
+	ErrOrderAlreadyPaidCode        = 10128
+	ErrIdempotencyConflictCode     = 10129
+	ErrIdempotencyProcessingCode   = 10130
+	ErrRegionNotDeliverableCode    = 10131
+	ErrWithdrawLimitCode           = 10132
+	ErrMarketPriceLimitCode        = 10133
+	ErrMarketEditLimitCode         = 10134
 )
 
 var (
@@ -108,6 +121,19 @@ var (
 	MsgBannerListError       = "获取Banner列表错误"
 	MsgMonitorListError      = "获取Monitor列表失败"
 	MsgNewsListError         = "获取新闻资讯列表失败"
+	MsgOrderPriceChanged     = "商品价格已变动，请刷新后重新下单"
+	MsgCouponUnavailable     = "优惠券不可用"
+	MsgOrderStatusIllegal    = "非法的状态转换"
+	MsgOrderStatusChanged    = "订单状态已变更，请刷新后重试"
+	MsgStockNotEnough        = "商品库存不足"
+	MsgOrderNotPayable       = "订单不可支付"
+	MsgOrderAlreadyPaid      = "订单已支付"
+	MsgIdempotencyConflict   = "幂等键已被其他请求使用"
+	MsgIdempotencyProcessing = "请求正在处理中，请勿重复提交"
+	MsgRegionNotDeliverable  = "该地区暂不支持配送"
+	MsgWithdrawLimit         = "提现金额超出限制"
+	MsgMarketPriceLimit      = "挂单价格超出今日价格范围"
+	MsgMarketEditLimit       = "今日改价次数已达上限"
 )
 
 var (
@@ -130,6 +156,17 @@ var (
 	ErrAuthorizationExpire   = NewCustomError(ErrAuthorizationExpireCode, MsgAuthorizationExpire, nil)
 	ErrActionNotFound        = NewCustomError(ErrActionNotFoundCode, MessageActionNotFound, nil)
 	ErrGenStringError        = NewCustomError(ErrGenStringErrorCode, MsgGenStringError, nil)
+	ErrOrderPriceChanged     = NewCustomError(ErrOrderPriceChangedCode, MsgOrderPriceChanged, nil)
+	ErrCouponUnavailable     = NewCustomError(ErrCouponUnavailableCode, MsgCouponUnavailable, nil)
+	ErrOrderStatusIllegal    = NewCustomError(ErrOrderStatusIllegalCode, MsgOrderStatusIllegal, nil)
+	ErrOrderStatusChanged    = NewCustomError(ErrOrderStatusChangedCode, MsgOrderStatusChanged, nil)
+	ErrStockNotEnough        = NewCustomError(ErrStockNotEnoughCode, MsgStockNotEnough, nil)
+	ErrOrderNotPayable       = NewCustomError(ErrOrderNotPayableCode, MsgOrderNotPayable, nil)
+	ErrOrderAlreadyPaid      = NewCustomError(ErrOrderAlreadyPaidCode, MsgOrderAlreadyPaid, nil)
+	ErrRegionNotDeliverable  = NewCustomError(ErrRegionNotDeliverableCode, MsgRegionNotDeliverable, nil)
+	ErrWithdrawLimit         = NewCustomError(ErrWithdrawLimitCode, MsgWithdrawLimit, nil)
+	ErrMarketPriceLimit      = NewCustomError(ErrMarketPriceLimitCode, MsgMarketPriceLimit, nil)
+	ErrMarketEditLimit       = NewCustomError(ErrMarketEditLimitCode, MsgMarketEditLimit, nil)
 )
 
 // CustomError 定义一个自定义错误类型
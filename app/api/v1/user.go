@@ -2,6 +2,7 @@ package v1
 
 import (
 	"app/internal/common"
+	"app/pkg/money"
 )
 
 // RegisterRequest godoc
@@ -90,9 +91,9 @@ type UpdateProfileRequest struct {
 }
 
 type UserAsset struct {
-	Points      int     `json:"points"`
-	CouponCount int     `json:"coupon_count"`
-	Balance     float64 `json:"balance"`
+	Points      int         `json:"points"`
+	CouponCount int         `json:"coupon_count"`
+	Balance     money.Money `json:"balance"`
 }
 
 type GetProfileResponseData struct {
@@ -2,16 +2,14 @@ package main
 
 import (
 	"context"
-	"flag"
-	"flash/cmd/migration/wire"
-	"flash/pkg/config"
-	"flash/pkg/log"
+
+	"app/cmd/migration/wire"
+	"app/pkg/config"
+	"app/pkg/log"
 )
 
 func main() {
-	var envConf = flag.String("conf", "config/local.yml", "config path, eg: -conf ./config/local.yml")
-	flag.Parse()
-	conf := config.NewConfig(*envConf)
+	conf := config.NewConfig()
 
 	logger := log.NewLog(conf)
 
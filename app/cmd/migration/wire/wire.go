@@ -4,10 +4,10 @@
 package wire
 
 import (
-	"flash/internal/repository"
-	"flash/internal/server"
-	"flash/pkg/app"
-	"flash/pkg/log"
+	"app/internal/repository"
+	"app/internal/server"
+	"app/pkg/app"
+	"app/pkg/log"
 	"github.com/google/wire"
 	"github.com/spf13/viper"
 )
@@ -15,8 +15,6 @@ import (
 var repositorySet = wire.NewSet(
 	repository.NewDB,
 	//repository.NewRedis,
-	repository.NewRepository,
-	repository.NewUserRepository,
 )
 var serverSet = wire.NewSet(
 	server.NewMigrate,
@@ -7,10 +7,10 @@
 package wire
 
 import (
-	"flash/internal/repository"
-	"flash/internal/server"
-	"flash/pkg/app"
-	"flash/pkg/log"
+	"app/internal/repository"
+	"app/internal/server"
+	"app/pkg/app"
+	"app/pkg/log"
 	"github.com/google/wire"
 	"github.com/spf13/viper"
 )
@@ -18,8 +18,8 @@ import (
 // Injectors from wire.go:
 
 func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), error) {
-	db := repository.NewDB(viperViper, logger)
-	migrate := server.NewMigrate(db, logger)
+	v := repository.NewDB(viperViper, logger)
+	migrate := server.NewMigrate(v, logger)
 	appApp := newApp(migrate)
 	return appApp, func() {
 	}, nil
@@ -27,7 +27,7 @@ func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), err
 
 // wire.go:
 
-var repositorySet = wire.NewSet(repository.NewDB, repository.NewRepository, repository.NewUserRepository)
+var repositorySet = wire.NewSet(repository.NewDB)
 
 var serverSet = wire.NewSet(server.NewMigrate)
 
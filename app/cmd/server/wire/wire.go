@@ -29,6 +29,7 @@ var repositorySet = wire.NewSet(
 	repository.NewSettingsRepository,
 	repository.NewResourceRepository,
 	repository.NewTurntableRepository,
+	repository.NewLedgerRepository,
 	repository.NewUserAssetRepository,
 	repository.NewUserZodiacRepository,
 	repository.NewUserAssetRecordRepository,
@@ -14,6 +14,7 @@ import (
 	"app/pkg/app"
 	"app/pkg/jwt"
 	"app/pkg/log"
+	"app/pkg/payment"
 	"app/pkg/server/http"
 	"app/pkg/sid"
 	"github.com/google/wire"
@@ -32,12 +33,20 @@ func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), err
 	serviceService := service.NewService(transaction, logger, sidSid, jwtJWT)
 	settingsRepository := repository.NewSettingsRepository(repositoryRepository)
 	userAssetRecordRepository := repository.NewUserAssetRecordRepository(repositoryRepository)
-	userAssetRepository := repository.NewUserAssetRepository(repositoryRepository, userAssetRecordRepository)
+	ledgerRepository := repository.NewLedgerRepository(repositoryRepository)
+	userAssetRepository := repository.NewUserAssetRepository(repositoryRepository)
 	userCouponRepository := repository.NewUserCouponRepository(repositoryRepository)
 	accountRepository := repository.NewAccountRepository(repositoryRepository, settingsRepository)
-	userAssetService := service.NewUserAssetService(serviceService, userAssetRepository, userCouponRepository, userAssetRecordRepository, accountRepository)
+	rechargeOrderRepository := repository.NewRechargeOrderRepository(repositoryRepository, settingsRepository, ledgerRepository)
+	paymentRepository := repository.NewPaymentRepository(repositoryRepository)
+	provider, err := payment.NewProvider(viperViper)
+	if err != nil {
+		return nil, nil, err
+	}
+	userAssetService := service.NewUserAssetService(serviceService, userAssetRepository, userCouponRepository, userAssetRecordRepository, accountRepository, rechargeOrderRepository, paymentRepository, provider)
 	userAssetHandler := handler.NewUserAssetHandler(handlerHandler, userAssetService)
-	accountService := service.NewAccountService(serviceService, accountRepository, userAssetRepository, userCouponRepository, settingsRepository)
+	memberTierRepository := repository.NewMemberTierRepository(repositoryRepository, settingsRepository)
+	accountService := service.NewAccountService(serviceService, accountRepository, userAssetRepository, userCouponRepository, settingsRepository, memberTierRepository)
 	accountHandler := handler.NewAccountHandler(handlerHandler, accountService)
 	resourceRepository := repository.NewResourceRepository(repositoryRepository)
 	resourceService := service.NewResourceService(serviceService, resourceRepository)
@@ -58,42 +67,60 @@ func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), err
 	productRepository := repository.NewProductRepository(repositoryRepository)
 	productService := service.NewProductService(serviceService, productRepository)
 	productHandler := handler.NewProductHandler(handlerHandler, productService)
-	freeMarketMineRepository := repository.NewFreeMarketMineRepository(repositoryRepository)
+	freeMarketMineRepository := repository.NewFreeMarketMineRepository(repositoryRepository, ledgerRepository, settingsRepository)
 	freeMarketMineService := service.NewFreeMarketMineService(serviceService, freeMarketMineRepository)
 	freeMarketMineHandler := handler.NewFreeMarketMineHandler(handlerHandler, freeMarketMineService)
-	userCartRepository := repository.NewUserCartRepository(repositoryRepository)
+	storeRepository := repository.NewStoreRepository(repositoryRepository)
+	userCartRepository := repository.NewUserCartRepository(repositoryRepository, storeRepository)
 	userCartService := service.NewUserCartService(serviceService, userCartRepository)
 	userCartHandler := handler.NewUserCartHandler(handlerHandler, userCartService)
-	userOrderRepository := repository.NewUserOrderRepository(repositoryRepository, userCartRepository, userAssetRepository)
-	userOrderService := service.NewUserOrderService(serviceService, userOrderRepository)
-	userOrderHandler := handler.NewUserOrderHandler(handlerHandler, userOrderService)
+	freightRepository := repository.NewFreightRepository(repositoryRepository)
+	orderPricingRepository := repository.NewOrderPricingRepository(repositoryRepository, freightRepository, userCouponRepository)
+	orderStatusRepository := repository.NewOrderStatusRepository(repositoryRepository)
+	pointRepository := repository.NewPointRepository(repositoryRepository, settingsRepository, ledgerRepository)
+	ostrichRepository := repository.NewOstrichRepository(repositoryRepository, settingsRepository)
+	userOrderRepository := repository.NewUserOrderRepository(repositoryRepository, userCartRepository, userAssetRepository, orderPricingRepository, orderStatusRepository, settingsRepository, productRepository, memberTierRepository, storeRepository, ledgerRepository, pointRepository, userCouponRepository, ostrichRepository)
 	userAddressRepository := repository.NewUserAddressRepository(repositoryRepository)
+	userOrderService := service.NewUserOrderService(serviceService, userOrderRepository, userAddressRepository, freightRepository)
+	userOrderHandler := handler.NewUserOrderHandler(handlerHandler, userOrderService)
 	userAddressService := service.NewUserAddressService(serviceService, userAddressRepository)
 	userAddressHandler := handler.NewUserAddressHandler(handlerHandler, userAddressService)
-	userCouponService := service.NewUserCouponService(serviceService, userCouponRepository)
+	userCouponService := service.NewUserCouponService(serviceService, userCouponRepository, userCartRepository, orderPricingRepository)
 	userCouponHandler := handler.NewUserCouponHandler(handlerHandler, userCouponService)
-	pointExchangeConfigRepository := repository.NewPointExchangeConfigRepository(repositoryRepository)
+	pointExchangeConfigRepository := repository.NewPointExchangeConfigRepository(repositoryRepository, ledgerRepository)
 	pointExchangeConfigService := service.NewPointExchangeConfigService(serviceService, pointExchangeConfigRepository)
 	pointExchangeConfigHandler := handler.NewPointExchangeConfigHandler(handlerHandler, pointExchangeConfigService)
-	refundOrderRepository := repository.NewRefundOrderRepository(repositoryRepository)
-	refundOrderService := service.NewRefundOrderService(serviceService, refundOrderRepository)
+	refundOrderRepository := repository.NewRefundOrderRepository(repositoryRepository, orderStatusRepository, productRepository, memberTierRepository, userAssetRepository, userCouponRepository, ostrichRepository, ledgerRepository)
+	refundOrderService := service.NewRefundOrderService(serviceService, refundOrderRepository, paymentRepository, provider)
 	refundOrderHandler := handler.NewRefundOrderHandler(handlerHandler, refundOrderService)
-	withdrawOrderRepository := repository.NewWithdrawOrderRepository(repositoryRepository)
-	withdrawOrderService := service.NewWithdrawOrderService(serviceService, withdrawOrderRepository)
+	payoutAccountRepository := repository.NewPayoutAccountRepository(repositoryRepository)
+	withdrawOrderRepository := repository.NewWithdrawOrderRepository(repositoryRepository, ledgerRepository, settingsRepository, payoutAccountRepository)
+	withdrawOrderService := service.NewWithdrawOrderService(serviceService, withdrawOrderRepository, payoutAccountRepository)
 	withdrawOrderHandler := handler.NewWithdrawOrderHandler(handlerHandler, withdrawOrderService)
-	productReviewRepository := repository.NewProductReviewRepository(repositoryRepository)
+	productReviewRepository := repository.NewProductReviewRepository(repositoryRepository, orderStatusRepository, pointRepository)
 	productReviewService := service.NewProductReviewService(serviceService, productReviewRepository)
 	productReviewHandler := handler.NewProductReviewHandler(handlerHandler, productReviewService)
 	productEvaluateRepository := repository.NewProductEvaluateRepository(repositoryRepository)
 	productEvaluateService := service.NewProductEvaluateService(serviceService, productEvaluateRepository)
 	productEvaluateHandler := handler.NewProductEvaluateHandler(handlerHandler, productEvaluateService)
-	userEarningRepository := repository.NewUserEarningRepository(repositoryRepository)
+	userEarningRepository := repository.NewUserEarningRepository(repositoryRepository, ledgerRepository)
 	userEarningService := service.NewUserEarningService(serviceService, userEarningRepository)
 	userEarningHandler := handler.NewUserEarningHandler(handlerHandler, userEarningService)
+	paymentService := service.NewPaymentService(serviceService, provider, paymentRepository, userOrderRepository, refundOrderRepository, accountRepository, rechargeOrderRepository)
+	paymentHandler := handler.NewPaymentHandler(handlerHandler, paymentService)
+	storeService := service.NewStoreService(serviceService, storeRepository)
+	storeHandler := handler.NewStoreHandler(handlerHandler, storeService)
+	pointService := service.NewPointService(serviceService, pointRepository)
+	pointHandler := handler.NewPointHandler(handlerHandler, pointService)
+	ostrichService := service.NewOstrichService(serviceService, ostrichRepository)
+	ostrichHandler := handler.NewOstrichHandler(handlerHandler, ostrichService)
+	reconciliationRepository := repository.NewReconciliationRepository(repositoryRepository, ledgerRepository)
+	reconciliationService := service.NewReconciliationService(serviceService, reconciliationRepository, settingsRepository)
+	reconciliationHandler := handler.NewReconciliationHandler(handlerHandler, reconciliationService)
 	httpServer := server.NewHTTPServer(logger, viperViper, jwtJWT, accountHandler, resourceHandler, 
 		settingsHandler, smsHandler, bannerHandler, monitorHandler, 
 		newsHandler, productHandler, freeMarketMineHandler, userCartHandler, userOrderHandler, 
-		userAddressHandler, userAssetHandler, userCouponHandler, pointExchangeConfigHandler, refundOrderHandler, withdrawOrderHandler, productReviewHandler, productEvaluateHandler, userEarningHandler)
+		userAddressHandler, userAssetHandler, userCouponHandler, pointExchangeConfigHandler, refundOrderHandler, withdrawOrderHandler, productReviewHandler, productEvaluateHandler, userEarningHandler, paymentHandler, storeHandler, pointHandler, ostrichHandler, reconciliationHandler)
 	job := server.NewJob(logger)
 	appApp := newApp(httpServer, job)
 	return appApp, func() {
@@ -104,8 +131,8 @@ func NewWire(viperViper *viper.Viper, logger *log.Logger) (*app.App, func(), err
 
 var repositorySet = wire.NewSet(repository.NewDB, repository.NewRepository, repository.NewTransaction, 
 	 repository.NewAccountRepository, repository.NewSettingsRepository, 
-	repository.NewResourceRepository, repository.NewUserAssetRepository, repository.NewUserAssetRecordRepository, 
-	repository.NewUserCouponRepository)
+	repository.NewResourceRepository, repository.NewLedgerRepository, repository.NewUserAssetRepository, repository.NewUserAssetRecordRepository, 
+	repository.NewUserCouponRepository, repository.NewOrderPricingRepository, repository.NewOrderStatusRepository)
 
 var serviceSet = wire.NewSet(service.NewService, service.NewAccountService, service.NewSettingsService, service.NewResourceService)
 
@@ -34,7 +34,7 @@ func GetStream(localLog *log.Logger) *lgrpc.PushMessageService_StreamMessagesCli
 		localLog.Info("Did not connect: " + err.Error())
 		return nil
 	}
-	defer conn.Close()
+	// 推送流在任务进程整个生命周期内保持，不能在此关闭连接
 	// 创建 gRPC 客户端
 	client := lgrpc.NewPushMessageServiceClient(conn)
 	// 创建流式请求，启动流连接
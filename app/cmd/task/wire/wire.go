@@ -4,6 +4,7 @@
 package wire
 
 import (
+	lgrpc "app/internal/grpc"
 	"app/internal/handler"
 	"app/internal/repository"
 	"app/internal/server"
@@ -22,21 +23,41 @@ var repositorySet = wire.NewSet(
 	repository.NewDB,
 	repository.NewRepository,
 	repository.NewTransaction,
-	repository.NewActivityRepository,
 	repository.NewSettingsRepository,
-	repository.NewAccountRepository,
-	repository.NewEscortRecordRepository,
-	repository.NewUnionMemberRepository,
+	repository.NewLedgerRepository,
+	repository.NewUserAssetRepository,
+	repository.NewStoreRepository,
+	repository.NewUserCartRepository,
+	repository.NewUserAddressRepository,
+	repository.NewFreightRepository,
+	repository.NewUserCouponRepository,
+	repository.NewOrderPricingRepository,
+	repository.NewOrderStatusRepository,
+	repository.NewProductRepository,
+	repository.NewMemberTierRepository,
+	repository.NewUserOrderRepository,
+	repository.NewReconciliationRepository,
+	repository.NewPayoutAccountRepository,
+	repository.NewWithdrawOrderRepository,
+	repository.NewPointRepository,
+	repository.NewOstrichRepository,
+	repository.NewUserEarningRepository,
 )
 
 var serviceSet = wire.NewSet(
 	service.NewService,
-	service.NewActivityService,
+	service.NewUserOrderService,
+	service.NewReconciliationService,
+	service.NewWithdrawOrderService,
+	service.NewPointService,
+	service.NewUserCouponService,
+	service.NewOstrichService,
+	service.NewUserEarningService,
 )
 
 var handlerSet = wire.NewSet(
 	handler.NewHandler,
-	handler.NewActivityHandler,
+	handler.NewTaskHandler,
 )
 
 var serverSet = wire.NewSet(
@@ -53,7 +74,7 @@ func newApp(
 	)
 }
 
-func NewWire(*viper.Viper, *log.Logger) (*app.App, func(), error) {
+func NewWire(*viper.Viper, *lgrpc.PushMessageService_StreamMessagesClient, *log.Logger) (*app.App, func(), error) {
 	panic(
 		wire.Build(
 			repositorySet,
@@ -32,13 +32,33 @@ func NewWire(viperViper *viper.Viper, s *lgrpc.PushMessageService_StreamMessages
 	jwtJWT := jwt.NewJwt(viperViper)
 	serviceService := service.NewService(transaction, logger, sidSid, jwtJWT)
 	settingsRepository := repository.NewSettingsRepository(repositoryRepository)
-	accountRepository := repository.NewAccountRepository(repositoryRepository, settingsRepository)
-	escortRecordRepository := repository.NewEscortRecordRepository(repositoryRepository)
-	unionMemberRepository := repository.NewUnionMemberRepository(repositoryRepository, accountRepository, settingsRepository)
-	activityRepository := repository.NewActivityRepository(repositoryRepository, settingsRepository, accountRepository, escortRecordRepository, unionMemberRepository)
-	activityService := service.NewActivityService(serviceService, activityRepository)
-	activityHandler := handler.NewActivityHandler(handlerHandler, activityService, *s)
-	task := server.NewTask(logger, activityHandler)
+	ledgerRepository := repository.NewLedgerRepository(repositoryRepository)
+	userAssetRepository := repository.NewUserAssetRepository(repositoryRepository)
+	storeRepository := repository.NewStoreRepository(repositoryRepository)
+	userCartRepository := repository.NewUserCartRepository(repositoryRepository, storeRepository)
+	freightRepository := repository.NewFreightRepository(repositoryRepository)
+	userCouponRepository := repository.NewUserCouponRepository(repositoryRepository)
+	orderPricingRepository := repository.NewOrderPricingRepository(repositoryRepository, freightRepository, userCouponRepository)
+	productRepository := repository.NewProductRepository(repositoryRepository)
+	orderStatusRepository := repository.NewOrderStatusRepository(repositoryRepository)
+	memberTierRepository := repository.NewMemberTierRepository(repositoryRepository, settingsRepository)
+	pointRepository := repository.NewPointRepository(repositoryRepository, settingsRepository, ledgerRepository)
+	ostrichRepository := repository.NewOstrichRepository(repositoryRepository, settingsRepository)
+	userOrderRepository := repository.NewUserOrderRepository(repositoryRepository, userCartRepository, userAssetRepository, orderPricingRepository, orderStatusRepository, settingsRepository, productRepository, memberTierRepository, storeRepository, ledgerRepository, pointRepository, userCouponRepository, ostrichRepository)
+	userAddressRepository := repository.NewUserAddressRepository(repositoryRepository)
+	userOrderService := service.NewUserOrderService(serviceService, userOrderRepository, userAddressRepository, freightRepository)
+	reconciliationRepository := repository.NewReconciliationRepository(repositoryRepository, ledgerRepository)
+	reconciliationService := service.NewReconciliationService(serviceService, reconciliationRepository, settingsRepository)
+	payoutAccountRepository := repository.NewPayoutAccountRepository(repositoryRepository)
+	withdrawOrderRepository := repository.NewWithdrawOrderRepository(repositoryRepository, ledgerRepository, settingsRepository, payoutAccountRepository)
+	withdrawOrderService := service.NewWithdrawOrderService(serviceService, withdrawOrderRepository, payoutAccountRepository)
+	pointService := service.NewPointService(serviceService, pointRepository)
+	userCouponService := service.NewUserCouponService(serviceService, userCouponRepository, userCartRepository, orderPricingRepository)
+	ostrichService := service.NewOstrichService(serviceService, ostrichRepository)
+	userEarningRepository := repository.NewUserEarningRepository(repositoryRepository, ledgerRepository)
+	userEarningService := service.NewUserEarningService(serviceService, userEarningRepository)
+	taskHandler := handler.NewTaskHandler(handlerHandler, userOrderService, reconciliationService, withdrawOrderService, pointService, userCouponService, ostrichService, userEarningService, s)
+	task := server.NewTask(logger, taskHandler)
 	appApp := newApp(task)
 	return appApp, func() {
 	}, nil
@@ -46,11 +66,11 @@ func NewWire(viperViper *viper.Viper, s *lgrpc.PushMessageService_StreamMessages
 
 // wire.go:
 
-var repositorySet = wire.NewSet(repository.NewDB, repository.NewRepository, repository.NewTransaction, repository.NewActivityRepository, repository.NewSettingsRepository, repository.NewAccountRepository, repository.NewEscortRecordRepository, repository.NewUnionMemberRepository)
+var repositorySet = wire.NewSet(repository.NewDB, repository.NewRepository, repository.NewTransaction, repository.NewSettingsRepository, repository.NewLedgerRepository, repository.NewUserAssetRepository, repository.NewStoreRepository, repository.NewUserCartRepository, repository.NewUserAddressRepository, repository.NewFreightRepository, repository.NewUserCouponRepository, repository.NewOrderPricingRepository, repository.NewOrderStatusRepository, repository.NewProductRepository, repository.NewMemberTierRepository, repository.NewUserOrderRepository, repository.NewReconciliationRepository, repository.NewPayoutAccountRepository, repository.NewWithdrawOrderRepository, repository.NewPointRepository, repository.NewOstrichRepository, repository.NewUserEarningRepository)
 
-var serviceSet = wire.NewSet(service.NewService, service.NewActivityService)
+var serviceSet = wire.NewSet(service.NewService, service.NewUserOrderService, service.NewReconciliationService, service.NewWithdrawOrderService, service.NewPointService, service.NewUserCouponService, service.NewOstrichService, service.NewUserEarningService)
 
-var handlerSet = wire.NewSet(handler.NewHandler, handler.NewActivityHandler)
+var handlerSet = wire.NewSet(handler.NewHandler, handler.NewTaskHandler)
 
 var serverSet = wire.NewSet(server.NewTask)
 
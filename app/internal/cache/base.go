@@ -7,6 +7,7 @@ package cache
 
 import (
 	"context"
+	"os"
 	"strconv"
 	"time"
 
@@ -40,6 +41,12 @@ func (c *Cache) Set(key string, value any) error {
 	return nil
 }
 
+// SetNX 仅在 key 不存在时写入，返回是否写入成功
+func (c *Cache) SetNX(key string, value any) (bool, error) {
+	key = c.Prefix + key
+	return c.Client.SetNX(c.Ctx, key, value, c.Expired).Result()
+}
+
 func (c *Cache) Del(key string) error {
 	key = c.Prefix + key
 	err := c.Client.Del(c.Ctx, key).Err()
@@ -149,3 +156,28 @@ func (c *Cache) HDel(key string, field string) error {
 	}
 	return nil
 }
+
+// unlockScript 仅删除自己持有的锁
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock 获取分布式锁，获取成功返回锁标识
+func (c *Cache) Lock(key string, ttl time.Duration) (string, bool, error) {
+	key = c.Prefix + key
+	token := strconv.Itoa(os.Getpid()) + "-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	ok, err := c.Client.SetNX(c.Ctx, key, token, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	return token, ok, nil
+}
+
+// Unlock 释放分布式锁
+func (c *Cache) Unlock(key string, token string) error {
+	key = c.Prefix + key
+	return unlockScript.Run(c.Ctx, c.Client, []string{key}, token).Err()
+}
@@ -97,6 +97,9 @@ const (
 
 	MODULE_TYPE_USER_INFO = "user_info"
 	MODULE_TYPE_ESCORT    = "escort"
+	MODULE_TYPE_ORDER     = "order"
+	MODULE_TYPE_POINT     = "point"
+	MODULE_TYPE_COUPON    = "coupon"
 
 	// 查询缓存prefix
 	PREFFIX_USER_INFO = "user_info."
@@ -114,6 +117,32 @@ const (
 	PREFFIX_TURNTABLE = "turntable.percent"
 	// 打卡奖励配置
 	PUNCHING_REWARD_SETTINGS = "punching_reward.settings"
+	// 定时任务锁
+	PREFFIX_TASK_LOCK = "task_lock."
+	// 幂等请求记录
+	PREFFIX_IDEMPOTENCY = "idempotency."
+	// 自由市场撮合锁
+	PREFFIX_MARKET_LOCK = "market_lock."
+
+	// 订单支付超时时间配置（分钟）
+	SETTINGS_ORDER_PAY_TIMEOUT = "order_pay_timeout"
+	ORDER_PAY_TIMEOUT_DEFAULT  = 25
+	// 会员等级规则配置（JSON 数组）
+	SETTINGS_MEMBER_TIER_RULES = "member_tier_rules"
+	// 充值赠送档位配置（JSON 数组）
+	SETTINGS_RECHARGE_BONUS_TIERS = "recharge_bonus_tiers"
+	// 对账发现差异时是否自动补记调整流水（1:是）
+	SETTINGS_RECONCILE_AUTO_CORRECT = "reconcile_auto_correct"
+	// 对账告警的机器人 Webhook 地址
+	SETTINGS_RECONCILE_ALERT_WEBHOOK = "reconcile_alert_webhook"
+	// 提现手续费与限额配置（JSON 对象）
+	SETTINGS_WITHDRAW_CONFIG = "withdraw_config"
+	// 积分获取与过期规则配置（JSON 对象）
+	SETTINGS_POINT_RULES = "point_rules"
+	// 自由市场交易手续费配置（JSON 对象）
+	SETTINGS_MARKET_CONFIG = "market_config"
+	// 认养种鸟的二级分类ID（JSON 数组），其余认养商品登记为商品鸟
+	SETTINGS_ADOPT_BREEDING_CATEGORIES = "adopt_breeding_categories"
 
 	PUBLISH_PRODUCT_STATUS_NORMAL = 1 // 挂单中
 	PUBLISH_PRODUCT_STATUS_BARGIN = 2 // 已成交
@@ -122,18 +151,33 @@ const (
 	PUBLISH_TYPE_SELL = 1 // 出售
 	PUBLISH_TYPE_BUY  = 2 // 求购
 
-	// 资产类型(1:积分 2:余额)
-	ASSET_TYPE_POINT   = 1
-	ASSET_TYPE_BALANCE = 2
-	ASSET_TYPE_COUPON  = 3
-
-	// 业务类型(1:充值 2:提现 3:兑换 4:订单)
-	BUSINESS_TYPE_RECHARGE = 1
-	BUSINESS_TYPE_WITHDRAW = 2
-	BUSINESS_TYPE_EXCHANGE = 3
-	BUSINESS_TYPE_ORDER    = 4
-
-	// 动作类型(1:使用 2:奖励 3:购买 4:提现 5:充值 6:兑换 7:退款)
+	// 自由市场K线周期
+	MARKET_CANDLE_HOUR = "1h"
+	MARKET_CANDLE_DAY  = "1d"
+	MARKET_CANDLE_WEEK = "1w"
+
+	// 资产类型(1:积分 2:余额 4:冻结余额 5:鸟蛋 6:冻结鸟蛋)
+	ASSET_TYPE_POINT      = 1
+	ASSET_TYPE_BALANCE    = 2
+	ASSET_TYPE_COUPON     = 3
+	ASSET_TYPE_FROZEN     = 4
+	ASSET_TYPE_EGG        = 5
+	ASSET_TYPE_FROZEN_EGG = 6
+
+	// 业务类型(1:充值 2:提现 3:兑换 4:订单 5:对账调整 6:晒图评价 7:签到 8:首次购买 9:积分过期 10:收益 11:自由市场)
+	BUSINESS_TYPE_RECHARGE       = 1
+	BUSINESS_TYPE_WITHDRAW       = 2
+	BUSINESS_TYPE_EXCHANGE       = 3
+	BUSINESS_TYPE_ORDER          = 4
+	BUSINESS_TYPE_ADJUST         = 5
+	BUSINESS_TYPE_REVIEW         = 6
+	BUSINESS_TYPE_CHECK_IN       = 7
+	BUSINESS_TYPE_FIRST_PURCHASE = 8
+	BUSINESS_TYPE_POINT_EXPIRE   = 9
+	BUSINESS_TYPE_EARNING        = 10
+	BUSINESS_TYPE_MARKET         = 11
+
+	// 动作类型(1:使用 2:奖励 3:购买 4:提现 5:充值 6:兑换 7:退款 8:调整 9:过期 10:出售 11:手续费)
 	ACTION_TYPE_USE      = 1
 	ACTION_TYPE_REWARD   = 2
 	ACTION_TYPE_BUY      = 3
@@ -141,6 +185,54 @@ const (
 	ACTION_TYPE_RECHARGE = 5
 	ACTION_TYPE_EXCHANGE = 6
 	ACTION_TYPE_REFUND   = 7
+	ACTION_TYPE_ADJUST   = 8
+	ACTION_TYPE_EXPIRE   = 9
+	ACTION_TYPE_SELL     = 10
+	ACTION_TYPE_FEE      = 11
+
+	// 记账账户类型(1:用户账户 2:系统账户)
+	LEDGER_ACCOUNT_USER   = 1
+	LEDGER_ACCOUNT_SYSTEM = 2
+	// 系统账户ID前缀，后接业务类型
+	LEDGER_SYSTEM_ACCOUNT_PREFIX = "system."
+
+	// 对账差异类型(1:资产与流水不符 2:订单扣款与流水不符 3:提现扣款与流水不符 4:缓存与资产不符 5:冻结余额与冻结记录不符 6:积分与积分批次不符)
+	RECONCILE_TYPE_LEDGER   = 1
+	RECONCILE_TYPE_ORDER    = 2
+	RECONCILE_TYPE_WITHDRAW = 3
+	RECONCILE_TYPE_CACHE    = 4
+	RECONCILE_TYPE_FROZEN   = 5
+	RECONCILE_TYPE_POINT    = 6
+
+	// 资金冻结状态(1:冻结中 2:已解冻 3:已扣除)
+	HOLD_STATUS_HELD     = 1
+	HOLD_STATUS_RELEASED = 2
+	HOLD_STATUS_CAPTURED = 3
+
+	// 优惠券类型(1:立减 2:折扣 3:满减 4:免运费)
+	COUPON_KIND_FIXED         = 1
+	COUPON_KIND_PERCENT       = 2
+	COUPON_KIND_THRESHOLD     = 3
+	COUPON_KIND_FREE_SHIPPING = 4
+
+	// 优惠券适用范围(1:全部商品 2:指定分类 3:指定商品)
+	COUPON_SCOPE_ALL      = 1
+	COUPON_SCOPE_CATEGORY = 2
+	COUPON_SCOPE_PRODUCT  = 3
+
+	// 用户优惠券状态(0:未使用 1:已使用 2:已过期)
+	USER_COUPON_STATUS_UNUSED  = 0
+	USER_COUPON_STATUS_USED    = 1
+	USER_COUPON_STATUS_EXPIRED = 2
+
+	// 优惠券领取结果(1:成功 2:失败)
+	COUPON_CLAIM_RESULT_SUCCESS = 1
+	COUPON_CLAIM_RESULT_FAILED  = 2
+
+	// 对账任务状态(1:进行中 2:已完成 3:失败)
+	RECONCILE_STATUS_RUNNING = 1
+	RECONCILE_STATUS_DONE    = 2
+	RECONCILE_STATUS_FAILED  = 3
 
 	// 产品类型(1:生肖 2:命数)
 	PRODUCT_TYPE_ZODIAC = 1
@@ -205,9 +297,9 @@ const (
 	SMS_SIGN     = "深圳市壹号熊网络科技"
 	SMS_TEMPLATE = "SMS_305080252"
 
-	STORE_ID   = 1
-	STORE_NAME = "鸵小妥"
-	STORE_LOGO = "https://txtimages.oss-cn-beijing.aliyuncs.com/icons/dianpu.png"
+	// 店铺状态
+	STORE_STATUS_OPEN   = 1 // 营业中
+	STORE_STATUS_CLOSED = 2 // 已关闭
 
 	// 订单状态
 	ORDER_STATUS_PENDING  = 0 // 待付款
@@ -218,4 +310,78 @@ const (
 	ORDER_STATUS_CLOSED   = 5 // 已关闭
 	ORDER_STATUS_EXPIRED  = 6 // 已过期
 	ORDER_STATUS_REFUNDED = 7 // 已退款
+
+	// 提现状态
+	WITHDRAW_STATUS_PENDING    = 0 // 待审核
+	WITHDRAW_STATUS_PROCESSING = 1 // 处理中
+	WITHDRAW_STATUS_COMPLETE   = 2 // 已完成
+	WITHDRAW_STATUS_REJECTED   = 3 // 已拒绝
+
+	// 提现收款方式
+	PAYOUT_TYPE_BANK   = 1 // 银行卡
+	PAYOUT_TYPE_WECHAT = 2 // 微信零钱
+
+	// 支付方式
+	PAYMENT_METHOD_WECHAT  = 2 // 微信支付
+	PAYMENT_METHOD_BALANCE = 3 // 余额支付
+
+	// 支付流水状态
+	PAYMENT_STATUS_PENDING = 0 // 待支付
+	PAYMENT_STATUS_PAID    = 1 // 已支付
+	PAYMENT_STATUS_CLOSED  = 2 // 已关闭
+	// 订单已失效但收到支付，需原路退回
+	PAYMENT_STATUS_REFUNDING = 3 // 待退回
+	PAYMENT_STATUS_REFUNDED  = 4 // 已退回
+
+	// 充值状态
+	RECHARGE_STATUS_PENDING = 0 // 待支付
+	RECHARGE_STATUS_PAID    = 1 // 已到账
+	RECHARGE_STATUS_CLOSED  = 2 // 已关闭
+	// 充值单支付超时时间（分钟）
+	RECHARGE_PAY_TIMEOUT = 30
+
+	// 退款状态
+	REFUND_STATUS_PENDING  = 0 // 退款中
+	REFUND_STATUS_SUCCESS  = 1 // 已退款
+	REFUND_STATUS_REJECTED = 2 // 已拒绝
+
+	// 订单状态变更操作方
+	ORDER_ACTOR_USER   = 1 // 用户
+	ORDER_ACTOR_SYSTEM = 2 // 系统
+	ORDER_ACTOR_ADMIN  = 3 // 管理员
+
+	// 商品库存为该值时不限库存，未维护库存的商品默认不限
+	PRODUCT_STOCK_UNLIMITED = -1
+
+	// 认养鸵鸟的一级分类
+	CATEGORY_ADOPT = 3
+
+	// 认养鸵鸟类型
+	OSTRICH_TYPE_COMMODITY = 1 // 商品鸟
+	OSTRICH_TYPE_BREEDING  = 2 // 种鸟
+
+	// 认养鸵鸟状态，雏鸟、育成期、成年为生长阶段
+	OSTRICH_STATUS_CHICK     = 1 // 雏鸟
+	OSTRICH_STATUS_JUVENILE  = 2 // 育成期
+	OSTRICH_STATUS_ADULT     = 3 // 成年
+	OSTRICH_STATUS_SOLD      = 4 // 已出售
+	OSTRICH_STATUS_DECEASED  = 5 // 已死亡
+	OSTRICH_STATUS_CANCELLED = 6 // 已退订
+	// 按日龄进入育成期和成年的天数
+	OSTRICH_JUVENILE_DAYS = 90
+	OSTRICH_ADULT_DAYS    = 365
+
+	// 产蛋分配状态
+	PRODUCTION_ALLOCATION_PENDING  = 1 // 待入账
+	PRODUCTION_ALLOCATION_CREDITED = 2 // 已入账
+	PRODUCTION_ALLOCATION_SKIPPED  = 3 // 鸵鸟当天已有收益，已跳过
+
+	// 运费模板计费方式
+	FREIGHT_CHARGE_PIECE  = 1 // 按件数
+	FREIGHT_CHARGE_WEIGHT = 2 // 按重量
+
+	// 会员等级统计的消费类型
+	TIER_SPEND_BUY   = "buy"   // 购买消费，不含认养
+	TIER_SPEND_ADOPT = "adopt" // 认养鸵鸟
+	TIER_SPEND_ALL   = "all"   // 全部消费
 )
@@ -13,6 +13,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 
 	"app/pkg/config"
 )
@@ -95,6 +96,16 @@ func Md5Encrypt(input string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// AesDecryptText 解密 AesEncrypt 加密的文本，并去掉加密时追加的 salt
+func AesDecryptText(cipherText string) (string, error) {
+	plainText, err := AesDecrypt(cipherText)
+	if err != nil {
+		return "", err
+	}
+	salt := config.ConfigInstance.GetString("security.api_sign.salt")
+	return strings.TrimSuffix(plainText, salt), nil
+}
+
 func CompareHashAndPassword(hashedPassword, password string) bool {
 	// 解析出hash中的password
 	decryptPassword, err := AesDecrypt(hashedPassword)
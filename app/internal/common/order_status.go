@@ -0,0 +1,51 @@
+package common
+
+// orderStatusTransitions 订单状态机：当前状态 -> 目标状态 -> 允许的操作方
+// 待付款 -> 待发货(已支付) -> 待收货(已发货) -> 待评价(已收货) -> 已完成，另有关闭、过期、退款分支
+// 支付只能由余额支付或支付回调完成；退款相关的变更只能由退款流程以系统身份执行，用户不能直接修改
+var orderStatusTransitions = map[uint8]map[uint8][]uint8{
+	ORDER_STATUS_PENDING: {
+		ORDER_STATUS_SHIPPED: {ORDER_ACTOR_SYSTEM},                  // 支付回调，余额支付在下单时直接记录
+		ORDER_STATUS_CLOSED:  {ORDER_ACTOR_USER, ORDER_ACTOR_ADMIN}, // 取消订单
+		ORDER_STATUS_EXPIRED: {ORDER_ACTOR_SYSTEM},                  // 超时未支付
+	},
+	ORDER_STATUS_SHIPPED: {
+		ORDER_STATUS_RECEIVED: {ORDER_ACTOR_ADMIN},                     // 发货
+		ORDER_STATUS_REFUNDED: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN}, // 申请退款
+		ORDER_STATUS_CLOSED:   {ORDER_ACTOR_ADMIN},                     // 后台关闭
+	},
+	ORDER_STATUS_RECEIVED: {
+		ORDER_STATUS_EVALUATE: {ORDER_ACTOR_USER, ORDER_ACTOR_SYSTEM}, // 确认收货 / 自动收货
+		ORDER_STATUS_REFUNDED: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+	},
+	ORDER_STATUS_EVALUATE: {
+		ORDER_STATUS_COMPLETE: {ORDER_ACTOR_USER, ORDER_ACTOR_SYSTEM}, // 评价 / 自动完成
+		ORDER_STATUS_REFUNDED: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+	},
+	ORDER_STATUS_COMPLETE: {
+		ORDER_STATUS_REFUNDED: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+	},
+	ORDER_STATUS_REFUNDED: {
+		// 撤销退款 / 拒绝退款，恢复到申请前的状态
+		ORDER_STATUS_SHIPPED:  {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+		ORDER_STATUS_RECEIVED: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+		ORDER_STATUS_EVALUATE: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+		ORDER_STATUS_COMPLETE: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+		// 退款完成
+		ORDER_STATUS_CLOSED: {ORDER_ACTOR_ADMIN, ORDER_ACTOR_SYSTEM},
+	},
+}
+
+// CanTransitOrderStatus 判断操作方是否可以将订单从 from 状态变更为 to 状态
+func CanTransitOrderStatus(from, to, actor uint8) bool {
+	actors, ok := orderStatusTransitions[from][to]
+	if !ok {
+		return false
+	}
+	for _, a := range actors {
+		if a == actor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package common
+
+// ostrichStatusTransitions 认养鸵鸟状态机：当前状态 -> 目标状态 -> 允许的操作方
+// 雏鸟 -> 育成期 -> 成年，任一生长阶段可出售或死亡，订单退款后退订
+var ostrichStatusTransitions = map[uint8]map[uint8][]uint8{
+	OSTRICH_STATUS_CHICK: {
+		OSTRICH_STATUS_JUVENILE:  {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN}, // 按日龄自动进入 / 后台调整
+		OSTRICH_STATUS_SOLD:      {ORDER_ACTOR_ADMIN},
+		OSTRICH_STATUS_DECEASED:  {ORDER_ACTOR_ADMIN},
+		OSTRICH_STATUS_CANCELLED: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN}, // 订单退款
+	},
+	OSTRICH_STATUS_JUVENILE: {
+		OSTRICH_STATUS_ADULT:     {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+		OSTRICH_STATUS_SOLD:      {ORDER_ACTOR_ADMIN},
+		OSTRICH_STATUS_DECEASED:  {ORDER_ACTOR_ADMIN},
+		OSTRICH_STATUS_CANCELLED: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+	},
+	OSTRICH_STATUS_ADULT: {
+		OSTRICH_STATUS_SOLD:      {ORDER_ACTOR_ADMIN},
+		OSTRICH_STATUS_DECEASED:  {ORDER_ACTOR_ADMIN},
+		OSTRICH_STATUS_CANCELLED: {ORDER_ACTOR_SYSTEM, ORDER_ACTOR_ADMIN},
+	},
+}
+
+// CanTransitOstrichStatus 判断操作方是否可以将认养鸵鸟从 from 状态变更为 to 状态
+func CanTransitOstrichStatus(from, to, actor uint8) bool {
+	actors, ok := ostrichStatusTransitions[from][to]
+	if !ok {
+		return false
+	}
+	for _, a := range actors {
+		if a == actor {
+			return true
+		}
+	}
+	return false
+}
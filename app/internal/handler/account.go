@@ -158,6 +158,36 @@ func (h *AccountHandler) UpdateProfileSettings(ctx *gin.Context) {
 	v1.HandleSuccess(ctx, true)
 }
 
+// AssignRole godoc
+// @Summary 指定用户身份
+// @Description 运营管理接口，手工指定用户的会员身份，变更会记录身份变更日志
+// @Tags 运营管理
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "管理接口令牌"
+// @Param X-Admin-Operator header string false "操作人"
+// @Param request body model.AssignRoleRequest true "身份信息"
+// @Success 200 {object} v1.Response
+// @Router /admin/account/role [post]
+func (h *AccountHandler) AssignRole(ctx *gin.Context) {
+	var req model.AssignRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(ctx, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.userService.AssignRole(ctx, req); err != nil {
+		if err.Error() == "用户不存在" || err.Error() == "身份不合法" {
+			v1.HandleError(ctx, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(ctx, v1.ErrRegisterCode, "指定用户身份失败", err)
+		return
+	}
+
+	v1.HandleSuccess(ctx, nil)
+}
+
 // WeChatLoginCallback godoc
 // @Summary 微信登录回调
 // @Schemes
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 
 	v1 "app/api/v1"
@@ -44,14 +46,14 @@ func (h *FreeMarketMineHandler) GetUserEggsSummary(c *gin.Context) {
 }
 
 // UpdateEggPrice godoc
-// @Summary 更新用户鸡蛋价格
-// @Description 更新用户未售出的鸡蛋价格
+// @Summary 修改出售挂单价格
+// @Description 修改自己出售挂单的价格，价格需在今日价格范围内且每日改价次数有限，改价后按新价格与求购挂单撮合；求购挂单需撤单后重新挂单
 // @Tags 自由市场
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param request body model.UpdateEggPriceRequest true "更新鸡蛋价格请求"
-// @Success 200 {object} v1.Response
+// @Success 200 {object} model.MarketOrderItem
 // @Router /market/update-price [post]
 func (h *FreeMarketMineHandler) UpdateEggPrice(c *gin.Context) {
 	// Create a request instance
@@ -63,14 +65,137 @@ func (h *FreeMarketMineHandler) UpdateEggPrice(c *gin.Context) {
 		return
 	}
 
-	// Use the bound parameters
-	id := req.ID
-	// Update price
-	err := h.freeMarketMineService.UpdateEggPrice(c, req.Price, id)
+	order, err := h.freeMarketMineService.UpdateEggPrice(c, req.Price, req.ID)
 	if err != nil {
-		v1.HandleError(c, v1.ErrRegisterCode, "更新价格失败", nil)
+		handleMarketError(c, err, "更新价格失败")
+		return
+	}
+
+	v1.HandleSuccess(c, order)
+}
+
+// PublishOrder godoc
+// @Summary 发布挂单
+// @Description 发布出售或求购鸟蛋的挂单，出售冻结鸟蛋、求购冻结余额，发布后立即与价格可成交的挂单撮合，可部分成交
+// @Tags 自由市场
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.PublishMarketOrderRequest true "发布挂单请求"
+// @Success 200 {object} model.MarketOrderItem
+// @Router /market/publish [post]
+func (h *FreeMarketMineHandler) PublishOrder(c *gin.Context) {
+	var req model.PublishMarketOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	order, err := h.freeMarketMineService.PublishOrder(c, req)
+	if err != nil {
+		handleMarketError(c, err, "发布挂单失败")
+		return
+	}
+
+	v1.HandleSuccess(c, order)
+}
+
+// CancelOrder godoc
+// @Summary 撤销挂单
+// @Description 撤销自己的挂单，未成交部分冻结的鸟蛋或余额退回
+// @Tags 自由市场
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.CancelMarketOrderRequest true "撤单请求"
+// @Success 200 {object} v1.Response
+// @Router /market/cancel [post]
+func (h *FreeMarketMineHandler) CancelOrder(c *gin.Context) {
+	var req model.CancelMarketOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.freeMarketMineService.CancelOrder(c, req); err != nil {
+		handleMarketError(c, err, "撤销挂单失败")
 		return
 	}
 
 	v1.HandleSuccess(c, nil)
 }
+
+// GetOrderBook godoc
+// @Summary 获取挂单簿
+// @Description 分页获取挂单中的出售或求购挂单，出售按价格从低到高、求购按价格从高到低排列
+// @Tags 自由市场
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param publish_type query int true "挂单类型(1:出售 2:求购)"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页条数"
+// @Success 200 {object} model.MarketOrderBookResponse
+// @Router /market/book [get]
+func (h *FreeMarketMineHandler) GetOrderBook(c *gin.Context) {
+	var req model.MarketOrderBookRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	book, err := h.freeMarketMineService.GetOrderBook(c, req)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取挂单簿失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, book)
+}
+
+// GetCandles godoc
+// @Summary 获取鸟蛋价格K线
+// @Description 按1小时、1天、1周汇总成交的开盘、最高、最低、收盘价和成交量，同时返回今日参考价
+// @Tags 自由市场
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param interval query string true "周期(1h 1d 1w)"
+// @Param end query int false "截止时间戳（秒）"
+// @Param limit query int false "返回的周期数，默认100，最多500"
+// @Success 200 {object} model.MarketCandleResponse
+// @Router /market/kline [get]
+func (h *FreeMarketMineHandler) GetCandles(c *gin.Context) {
+	var req model.MarketCandleRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	candles, err := h.freeMarketMineService.GetCandles(c, req)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取K线失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, candles)
+}
+
+// handleMarketError 挂单、改价、撤单失败时，业务错误直接提示用户，其他错误统一提示 msg
+func handleMarketError(c *gin.Context, err error, msg string) {
+	if errors.Is(err, v1.ErrMarketPriceLimit) {
+		v1.HandleError(c, v1.ErrMarketPriceLimitCode, err.Error(), nil)
+		return
+	}
+	if errors.Is(err, v1.ErrMarketEditLimit) {
+		v1.HandleError(c, v1.ErrMarketEditLimitCode, err.Error(), nil)
+		return
+	}
+	switch err.Error() {
+	case "余额不足", "鸟蛋不足", "挂单不存在", "挂单已成交或已撤销", "挂单金额过大",
+		"求购挂单不支持改价，请撤单后重新挂单", "市场繁忙，请稍后重试":
+		v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+		return
+	}
+	v1.HandleError(c, v1.ErrRegisterCode, msg, err)
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "app/api/v1"
+	"app/internal/model"
+	"app/internal/service"
+)
+
+type OstrichHandler struct {
+	*Handler
+	ostrichService service.OstrichService
+}
+
+func NewOstrichHandler(
+	handler *Handler,
+	ostrichService service.OstrichService,
+) *OstrichHandler {
+	return &OstrichHandler{
+		Handler:        handler,
+		ostrichService: ostrichService,
+	}
+}
+
+// GetUserOstriches godoc
+// @Summary 获取我的鸵鸟
+// @Description 分页获取认养订单支付后登记的鸵鸟，包括耳标号、农场、栏舍、出生日期、类型和生长阶段
+// @Tags 认养鸵鸟
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param status query int false "状态(1:雏鸟 2:育成期 3:成年 4:已出售 5:已死亡 6:已退订)"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页条数"
+// @Success 200 {object} model.OstrichListResponse
+// @Router /ostrich/list [get]
+func (h *OstrichHandler) GetUserOstriches(c *gin.Context) {
+	var req model.OstrichListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	response, err := h.ostrichService.GetUserOstriches(c, req)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取鸵鸟列表失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, response)
+}
+
+// GetUserOstrichDetail godoc
+// @Summary 获取鸵鸟详情
+// @Description 获取认养鸵鸟的详情、所属订单和状态时间线
+// @Tags 认养鸵鸟
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id query uint64 true "鸵鸟ID"
+// @Success 200 {object} model.OstrichDetailResponse
+// @Router /ostrich/detail [get]
+func (h *OstrichHandler) GetUserOstrichDetail(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Query("id"), 10, 64)
+	if err != nil || id == 0 {
+		v1.HandleError(c, v1.ErrParamCode, "鸵鸟ID格式错误", err)
+		return
+	}
+
+	response, err := h.ostrichService.GetUserOstrichDetail(c, id)
+	if err != nil {
+		if err.Error() == "鸵鸟不存在" {
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "获取鸵鸟详情失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, response)
+}
+
+// UpdateOstrich godoc
+// @Summary 补录鸵鸟信息
+// @Description 运营管理接口，批量补录鸵鸟的栏舍和出生日期，字段为空表示不修改
+// @Tags 运营管理
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "管理接口令牌"
+// @Param X-Admin-Operator header string false "操作人"
+// @Param request body model.UpdateOstrichRequest true "鸵鸟信息"
+// @Success 200 {object} v1.Response
+// @Router /admin/ostrich/update [post]
+func (h *OstrichHandler) UpdateOstrich(c *gin.Context) {
+	var req model.UpdateOstrichRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.ostrichService.UpdateInfo(c, req); err != nil {
+		if err.Error() == "鸵鸟不存在" || err.Error() == "出生日期不合法" {
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "补录鸵鸟信息失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, nil)
+}
+
+// ChangeOstrichStatus godoc
+// @Summary 变更鸵鸟状态
+// @Description 运营管理接口，按状态机变更鸵鸟状态，如登记出售或死亡
+// @Tags 运营管理
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "管理接口令牌"
+// @Param X-Admin-Operator header string false "操作人"
+// @Param request body model.ChangeOstrichStatusRequest true "状态信息"
+// @Success 200 {object} v1.Response
+// @Router /admin/ostrich/status [post]
+func (h *OstrichHandler) ChangeOstrichStatus(c *gin.Context) {
+	var req model.ChangeOstrichStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.ostrichService.ChangeStatus(c, req); err != nil {
+		if errors.Is(err, v1.ErrOrderStatusIllegal) {
+			v1.HandleError(c, v1.ErrOrderStatusIllegalCode, v1.MsgOrderStatusIllegal, nil)
+			return
+		}
+		if err.Error() == "鸵鸟不存在" || err.Error() == "鸵鸟状态已变更" {
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "变更鸵鸟状态失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, nil)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "app/api/v1"
+	"app/internal/model"
+	"app/internal/service"
+)
+
+type PaymentHandler struct {
+	*Handler
+	paymentService service.PaymentService
+}
+
+func NewPaymentHandler(
+	handler *Handler,
+	paymentService service.PaymentService,
+) *PaymentHandler {
+	return &PaymentHandler{
+		Handler:        handler,
+		paymentService: paymentService,
+	}
+}
+
+// Prepay godoc
+// @Summary 发起支付
+// @Description 为待付款订单发起微信支付，返回小程序调起支付所需参数
+// @Tags 支付
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.PrepayRequest true "发起支付请求"
+// @Success 200 {object} payment.PrepayResponse
+// @Failure 400 {object} v1.Response "参数错误"
+// @Failure 401 {object} v1.Response "未授权"
+// @Failure 500 {object} v1.Response "服务器内部错误"
+// @Router /pay/prepay [post]
+func (h *PaymentHandler) Prepay(c *gin.Context) {
+	var req model.PrepayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	resp, err := h.paymentService.Prepay(c, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, v1.ErrOrderNotPayable):
+			v1.HandleError(c, v1.ErrOrderNotPayableCode, v1.MsgOrderNotPayable, nil)
+		case errors.Is(err, v1.ErrOrderAlreadyPaid):
+			v1.HandleError(c, v1.ErrOrderAlreadyPaidCode, v1.MsgOrderAlreadyPaid, nil)
+		default:
+			v1.HandleError(c, v1.ErrInternalServerErrorCode, "发起支付失败: "+err.Error(), nil)
+		}
+		return
+	}
+
+	v1.HandleSuccess(c, resp)
+}
+
+// Notify godoc
+// @Summary 支付回调
+// @Description 支付渠道异步通知，验签通过后更新订单状态
+// @Tags 支付
+// @Accept json
+// @Produce json
+// @Success 200
+// @Failure 500 {object} map[string]string "处理失败"
+// @Router /pay/notify [post]
+func (h *PaymentHandler) Notify(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": "FAIL", "message": "读取回调内容失败"})
+		return
+	}
+	// 处理失败时返回非 200，渠道会按策略重新通知
+	if err := h.paymentService.HandleNotify(c, c.Request.Header, body); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": "FAIL", "message": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
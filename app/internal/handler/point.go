@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	v1 "app/api/v1"
+	"app/internal/service"
+)
+
+type PointHandler struct {
+	*Handler
+	pointService service.PointService
+}
+
+func NewPointHandler(
+	handler *Handler,
+	pointService service.PointService,
+) *PointHandler {
+	return &PointHandler{
+		Handler:      handler,
+		pointService: pointService,
+	}
+}
+
+// CheckIn godoc
+// @Summary 每日签到
+// @Description 每日签到领取积分，每天只能签到一次
+// @Tags 积分
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} model.CheckInResponse
+// @Failure 401 {object} v1.Response "未授权"
+// @Failure 403 {object} v1.Response "今日已签到"
+// @Failure 500 {object} v1.Response "服务器内部错误"
+// @Router /point/check-in [post]
+func (h *PointHandler) CheckIn(c *gin.Context) {
+	response, err := h.pointService.CheckIn(c)
+	if err != nil {
+		if err.Error() == "今日已签到" {
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "签到失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, response)
+}
+
+// GetPointLots godoc
+// @Summary 获取积分批次
+// @Description 获取用户积分的获得批次、剩余积分和过期时间，以及即将过期的积分
+// @Tags 积分
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} model.PointLotListResponse
+// @Failure 401 {object} v1.Response "未授权"
+// @Failure 500 {object} v1.Response "服务器内部错误"
+// @Router /point/lots [get]
+func (h *PointHandler) GetPointLots(c *gin.Context) {
+	response, err := h.pointService.GetPointLots(c)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取积分批次失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, response)
+}
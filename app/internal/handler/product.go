@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	v1 "app/api/v1"
+	"app/internal/model"
 	"app/internal/service"
 )
 
@@ -105,3 +106,33 @@ func (h *ProductHandler) GetProductDetailsByCartIDs(c *gin.Context) {
 
 	v1.HandleSuccess(c, response.Products)
 }
+
+// UpdateStock godoc
+// @Summary 设置商品库存
+// @Description 运营管理接口，设置商品的可售库存，-1表示不限库存
+// @Tags 运营管理
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "管理接口令牌"
+// @Param X-Admin-Operator header string false "操作人"
+// @Param request body model.UpdateProductStockRequest true "库存信息"
+// @Success 200 {object} v1.Response
+// @Router /admin/product/stock [post]
+func (h *ProductHandler) UpdateStock(c *gin.Context) {
+	var req model.UpdateProductStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.productService.UpdateStock(c, req); err != nil {
+		if err.Error() == "商品不存在" || err.Error() == "库存不合法" {
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "设置商品库存失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, nil)
+}
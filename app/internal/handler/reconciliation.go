@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	v1 "app/api/v1"
+	"app/internal/model"
+	"app/internal/service"
+)
+
+type ReconciliationHandler struct {
+	*Handler
+	reconciliationService service.ReconciliationService
+}
+
+func NewReconciliationHandler(
+	handler *Handler,
+	reconciliationService service.ReconciliationService,
+) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		Handler:               handler,
+		reconciliationService: reconciliationService,
+	}
+}
+
+// GetRuns godoc
+// @Summary 获取对账任务
+// @Description 运营管理接口，分页获取资产对账任务及其差异数、已调整数
+// @Tags 运营管理
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "管理接口令牌"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页条数"
+// @Success 200 {object} model.ReconciliationRunListResponse
+// @Router /admin/reconcile/runs [get]
+func (h *ReconciliationHandler) GetRuns(c *gin.Context) {
+	var req model.ReconciliationRunListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	response, err := h.reconciliationService.GetRuns(c, req)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取对账任务失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, response)
+}
+
+// GetDiscrepancies godoc
+// @Summary 获取对账差异
+// @Description 运营管理接口，获取指定对账任务发现的差异明细
+// @Tags 运营管理
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "管理接口令牌"
+// @Param run_id query uint64 true "对账任务ID"
+// @Success 200 {object} []model.ReconciliationDiscrepancy
+// @Router /admin/reconcile/discrepancies [get]
+func (h *ReconciliationHandler) GetDiscrepancies(c *gin.Context) {
+	var req model.ReconciliationDiscrepancyRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	discrepancies, err := h.reconciliationService.GetDiscrepancies(c, req.RunID)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取对账差异失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, discrepancies)
+}
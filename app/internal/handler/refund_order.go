@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -214,3 +215,73 @@ func (h *RefundOrderHandler) DeleteRefund(c *gin.Context) {
 
 	v1.HandleSuccess(c, nil)
 }
+
+// ApproveRefund godoc
+// @Summary 审核通过退款
+// @Description 运营管理接口，余额支付的订单退回余额，微信支付的订单原路退回
+// @Tags 运营管理
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "管理接口令牌"
+// @Param X-Admin-Operator header string false "操作人"
+// @Param request body model.ApproveRefundRequest true "退款单信息"
+// @Success 200 {object} v1.Response
+// @Router /admin/refund/approve [post]
+func (h *RefundOrderHandler) ApproveRefund(c *gin.Context) {
+	var req model.ApproveRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.refundOrderService.ApproveRefund(c, req); err != nil {
+		if errors.Is(err, v1.ErrOrderStatusIllegal) {
+			v1.HandleError(c, v1.ErrOrderStatusIllegalCode, v1.MsgOrderStatusIllegal, nil)
+			return
+		}
+		switch err.Error() {
+		case "退款单不存在", "退款单已处理", "订单不存在", "支付流水不存在", "订单支付方式不支持退款":
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "审核退款失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, nil)
+}
+
+// RejectRefund godoc
+// @Summary 审核拒绝退款
+// @Description 运营管理接口，拒绝退款申请，订单恢复到申请退款前的状态
+// @Tags 运营管理
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "管理接口令牌"
+// @Param X-Admin-Operator header string false "操作人"
+// @Param request body model.RejectRefundRequest true "拒绝信息"
+// @Success 200 {object} v1.Response
+// @Router /admin/refund/reject [post]
+func (h *RefundOrderHandler) RejectRefund(c *gin.Context) {
+	var req model.RejectRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.refundOrderService.RejectRefund(c, req); err != nil {
+		if errors.Is(err, v1.ErrOrderStatusIllegal) {
+			v1.HandleError(c, v1.ErrOrderStatusIllegalCode, v1.MsgOrderStatusIllegal, nil)
+			return
+		}
+		switch err.Error() {
+		case "退款单不存在", "退款单已处理", "退款已提交渠道处理":
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "拒绝退款失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, nil)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	v1 "app/api/v1"
+	"app/internal/model"
+	"app/internal/service"
+)
+
+type StoreHandler struct {
+	*Handler
+	storeService service.StoreService
+}
+
+func NewStoreHandler(
+	handler *Handler,
+	storeService service.StoreService,
+) *StoreHandler {
+	return &StoreHandler{
+		Handler:      handler,
+		storeService: storeService,
+	}
+}
+
+// GetStorePage godoc
+// @Summary 店铺主页
+// @Description 获取店铺信息、评分和店铺商品列表
+// @Tags 店铺
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param store_id path uint64 true "店铺ID"
+// @Param page query int false "页码，默认1"
+// @Param page_size query int false "每页条数，默认10"
+// @Success 200 {object} model.StorePageResponse
+// @Router /store/{store_id} [get]
+func (h *StoreHandler) GetStorePage(c *gin.Context) {
+	storeID, err := strconv.ParseUint(c.Param("store_id"), 10, 64)
+	if err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+	var req model.StorePageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	response, err := h.storeService.GetStorePage(c, storeID, req)
+	if err != nil {
+		if err.Error() == "店铺不存在" {
+			v1.HandleError(c, v1.ErrNotFoundCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "获取店铺信息失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, response)
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"app/internal/common"
+	pb "app/internal/grpc"
+	"app/internal/model"
+	"app/internal/service"
+)
+
+// errPushUnavailable 推送服务未连接
+var errPushUnavailable = errors.New("推送服务未连接")
+
+// TaskHandler 定时任务处理
+type TaskHandler struct {
+	*Handler
+	userOrderService      service.UserOrderService
+	reconciliationService service.ReconciliationService
+	withdrawOrderService  service.WithdrawOrderService
+	pointService          service.PointService
+	userCouponService     service.UserCouponService
+	ostrichService        service.OstrichService
+	userEarningService    service.UserEarningService
+	stream                *pb.PushMessageService_StreamMessagesClient
+}
+
+func NewTaskHandler(
+	handler *Handler,
+	userOrderService service.UserOrderService,
+	reconciliationService service.ReconciliationService,
+	withdrawOrderService service.WithdrawOrderService,
+	pointService service.PointService,
+	userCouponService service.UserCouponService,
+	ostrichService service.OstrichService,
+	userEarningService service.UserEarningService,
+	stream *pb.PushMessageService_StreamMessagesClient,
+) *TaskHandler {
+	return &TaskHandler{
+		Handler:               handler,
+		userOrderService:      userOrderService,
+		reconciliationService: reconciliationService,
+		withdrawOrderService:  withdrawOrderService,
+		pointService:          pointService,
+		userCouponService:     userCouponService,
+		ostrichService:        ostrichService,
+		userEarningService:    userEarningService,
+		stream:                stream,
+	}
+}
+
+// ExpireUnpaidOrders 关闭超时未支付的订单并通知用户
+func (h *TaskHandler) ExpireUnpaidOrders(ctx context.Context) error {
+	orderItems, err := h.userOrderService.ExpireUnpaidOrders(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range orderItems {
+		h.push(item.UserId, model.OrderNotice{
+			Module:      common.MODULE_TYPE_ORDER,
+			OrderItemID: item.ID,
+			OrderNo:     item.OrderNo,
+			Status:      common.ORDER_STATUS_EXPIRED,
+			Message:     "您的订单超时未支付，已自动关闭",
+		})
+	}
+	if len(orderItems) > 0 {
+		h.logger.Info("关闭超时订单", zap.Int("count", len(orderItems)))
+	}
+	return nil
+}
+
+// ReconcileAssets 核对用户余额、积分与流水和业务单据
+func (h *TaskHandler) ReconcileAssets(ctx context.Context) error {
+	run, err := h.reconciliationService.ReconcileAssets(ctx)
+	if err != nil {
+		return err
+	}
+	h.logger.Info("资产对账完成",
+		zap.Uint64("run_id", run.ID),
+		zap.Int("user_count", run.UserCount),
+		zap.Int("discrepancy_count", run.DiscrepancyCount),
+	)
+	return nil
+}
+
+// SettleWithdrawals 结算已打款或已拒绝提现单的冻结余额
+func (h *TaskHandler) SettleWithdrawals(ctx context.Context) error {
+	return h.withdrawOrderService.SettleWithdrawals(ctx)
+}
+
+// ExpirePoints 扣减已过期积分批次的剩余积分
+func (h *TaskHandler) ExpirePoints(ctx context.Context) error {
+	return h.pointService.ExpirePoints(ctx)
+}
+
+// RemindExpiringPoints 推送积分即将过期提醒，只标记推送成功的积分批次
+func (h *TaskHandler) RemindExpiringPoints(ctx context.Context) error {
+	notices, err := h.pointService.RemindExpiringPoints(ctx)
+	if err != nil {
+		return err
+	}
+	lotIDs := make([]uint64, 0)
+	for _, notice := range notices {
+		if err := h.push(notice.UserID, notice); err != nil {
+			if errors.Is(err, errPushUnavailable) {
+				h.logger.Warn("推送服务未连接，积分过期提醒延后", zap.Int("count", len(notices)))
+				break
+			}
+			continue
+		}
+		lotIDs = append(lotIDs, notice.LotIDs...)
+	}
+	if err := h.pointService.MarkPointsReminded(ctx, lotIDs); err != nil {
+		return err
+	}
+	if len(lotIDs) > 0 {
+		h.logger.Info("积分过期提醒", zap.Int("count", len(lotIDs)))
+	}
+	return nil
+}
+
+// ExpireCoupons 将已过截止时间的未使用优惠券标记为已过期
+func (h *TaskHandler) ExpireCoupons(ctx context.Context) error {
+	return h.userCouponService.ExpireCoupons(ctx)
+}
+
+// RemindExpiringCoupons 推送优惠券即将过期提醒，只标记推送成功的优惠券
+func (h *TaskHandler) RemindExpiringCoupons(ctx context.Context) error {
+	notices, err := h.userCouponService.RemindExpiringCoupons(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint64, 0)
+	for _, notice := range notices {
+		if err := h.push(notice.UserID, notice); err != nil {
+			if errors.Is(err, errPushUnavailable) {
+				h.logger.Warn("推送服务未连接，优惠券过期提醒延后", zap.Int("count", len(notices)))
+				break
+			}
+			continue
+		}
+		ids = append(ids, notice.IDs...)
+	}
+	if err := h.userCouponService.MarkCouponsReminded(ctx, ids); err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		h.logger.Info("优惠券过期提醒", zap.Int("count", len(ids)))
+	}
+	return nil
+}
+
+// AdvanceOstrichStages 按日龄推进认养鸵鸟的生长阶段
+func (h *TaskHandler) AdvanceOstrichStages(ctx context.Context) error {
+	return h.ostrichService.AdvanceStages(ctx)
+}
+
+// GenerateDailyEarnings 按农场产蛋记录生成认养用户的每日收益
+func (h *TaskHandler) GenerateDailyEarnings(ctx context.Context) error {
+	return h.userEarningService.GenerateDailyEarnings(ctx)
+}
+
+// push 通过推送服务向用户发送消息，推送服务未连接时返回 errPushUnavailable
+func (h *TaskHandler) push(userID string, data any) error {
+	if h.stream == nil || *h.stream == nil {
+		return errPushUnavailable
+	}
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		h.logger.Error("推送消息序列化失败", zap.Error(err))
+		return err
+	}
+	if err := (*h.stream).Send(&pb.PushMessageRequest{
+		UserId: "system",
+		To:     userID,
+		Data:   dataBytes,
+	}); err != nil {
+		h.logger.Error("推送消息失败", zap.String("user_id", userID), zap.Error(err))
+		return err
+	}
+	return nil
+}
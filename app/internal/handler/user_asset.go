@@ -45,13 +45,13 @@ func (h *UserAssetHandler) GetUserAsset(c *gin.Context) {
 
 // RechargeBalance godoc
 // @Summary 充值用户余额
-// @Description 给用户账户充值指定金额
+// @Description 创建充值单并返回调起支付的参数，支付成功后余额到账
 // @Tags 用户资产
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param request body model.RechargeRequest true "充值请求"
-// @Success 200 {object} v1.Response
+// @Success 200 {object} model.RechargeResponse
 // @Router /asset/recharge [post]
 func (h *UserAssetHandler) RechargeBalance(c *gin.Context) {
 	// 解析请求体
@@ -61,14 +61,61 @@ func (h *UserAssetHandler) RechargeBalance(c *gin.Context) {
 		return
 	}
 
-	// 调用服务层进行充值
-	err := h.userAssetService.RechargeBalance(c, req)
+	// 创建充值单并发起支付
+	resp, err := h.userAssetService.RechargeBalance(c, req)
 	if err != nil {
 		v1.HandleError(c, v1.ErrRegisterCode, "充值失败", nil)
 		return
 	}
 
-	v1.HandleSuccess(c, nil)
+	v1.HandleSuccess(c, resp)
+}
+
+// GetRechargeBonusTiers godoc
+// @Summary 获取充值赠送档位
+// @Description 获取充值满额赠送的档位，如充100送10
+// @Tags 用户资产
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} model.RechargeBonusTier
+// @Router /asset/recharge/tiers [get]
+func (h *UserAssetHandler) GetRechargeBonusTiers(c *gin.Context) {
+	tiers, err := h.userAssetService.GetRechargeBonusTiers(c)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取充值档位失败", nil)
+		return
+	}
+
+	v1.HandleSuccess(c, tiers)
+}
+
+// GetRechargeList godoc
+// @Summary 获取充值记录
+// @Description 分页获取用户的充值记录
+// @Tags 用户资产
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param status query int false "充值状态(0:待支付;1:已到账;2:已关闭)"
+// @Param page query int false "页码，默认1"
+// @Param page_size query int false "每页条数，默认10"
+// @Success 200 {object} model.RechargeListResponse
+// @Router /asset/recharge/list [get]
+func (h *UserAssetHandler) GetRechargeList(c *gin.Context) {
+	var req model.RechargeQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	response, err := h.userAssetService.GetRechargeList(c, req)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取充值记录失败", nil)
+		return
+	}
+
+	v1.HandleSuccess(c, response)
 }
 
 // WithdrawBalance godoc
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 
 	v1 "app/api/v1"
@@ -44,6 +46,10 @@ func (h *UserCartHandler) AddToCart(c *gin.Context) {
 	// Add to cart
 	err := h.userCartService.AddToCart(c, req)
 	if err != nil {
+		if errors.Is(err, v1.ErrStockNotEnough) {
+			v1.HandleError(c, v1.ErrStockNotEnoughCode, v1.MsgStockNotEnough, nil)
+			return
+		}
 		v1.HandleError(c, v1.ErrRegisterCode, "添加购物车失败", nil)
 		return
 	}
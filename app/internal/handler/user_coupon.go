@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 
 	v1 "app/api/v1"
@@ -57,10 +59,17 @@ func (h *UserCouponHandler) GetUserCoupons(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
+// @Param status query int false "状态(0:未使用;1:已使用;2:已过期)，为空查询全部"
 // @Success 200 {array} model.ProductCouponDTO
 // @Router /coupon/list [get]
 func (h *UserCouponHandler) GetAllUserCoupons(c *gin.Context) {
-	coupons, err := h.userCouponService.GetAllUserCoupons(c)
+	var req model.UserCouponQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	coupons, err := h.userCouponService.GetAllUserCoupons(c, req)
 	if err != nil {
 		v1.HandleError(c, v1.ErrRegisterCode, "获取优惠券失败", nil)
 		return
@@ -90,9 +99,10 @@ func (h *UserCouponHandler) ClaimCoupon(c *gin.Context) {
 	// 领取优惠券
 	err := h.userCouponService.ClaimCoupon(c, req)
 	if err != nil {
-		// 检查是否是已领取的错误
-		if err.Error() == "coupon already claimed" {
-			v1.HandleError(c, v1.ErrRegisterCode, "您已经领取过该优惠券", nil)
+		// 不存在、已过期、已领完或达到领取上限的优惠券
+		switch err.Error() {
+		case "优惠券不存在", "优惠券已过期", "不在优惠券领取时间内", "已达到该优惠券的领取上限", "优惠券已领完":
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
 			return
 		}
 		v1.HandleError(c, v1.ErrRegisterCode, "领取优惠券失败", nil)
@@ -133,3 +143,40 @@ func (h *UserCouponHandler) GetUserCouponDetail(c *gin.Context) {
 
 	v1.HandleSuccess(c, coupon)
 }
+
+// RecommendCoupons godoc
+// @Summary 推荐优惠券组合
+// @Description 结算时根据购物车商品计算总优惠最大的优惠券组合，返回每个商品使用的优惠券和其他优惠券不可用的原因
+// @Tags 优惠券
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.CouponRecommendRequest true "推荐优惠券请求"
+// @Success 200 {object} model.CouponRecommendResponse
+// @Failure 400 {object} v1.Response "参数错误"
+// @Failure 401 {object} v1.Response "未授权"
+// @Failure 500 {object} v1.Response "服务器内部错误"
+// @Router /coupon/recommend [post]
+func (h *UserCouponHandler) RecommendCoupons(c *gin.Context) {
+	var req model.CouponRecommendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	response, err := h.userCouponService.RecommendCoupons(c, req)
+	if err != nil {
+		if errors.Is(err, v1.ErrRegionNotDeliverable) {
+			v1.HandleError(c, v1.ErrRegionNotDeliverableCode, v1.MsgRegionNotDeliverable, nil)
+			return
+		}
+		if err.Error() == "购物车商品不存在" || err.Error() == "商品不存在" {
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "推荐优惠券失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, response)
+}
@@ -23,36 +23,6 @@ func NewUserEarningHandler(
 	}
 }
 
-// AddEarning godoc
-// @Summary 添加用户收益
-// @Description 添加用户的收益记录
-// @Tags 收益
-// @Accept json
-// @Produce json
-// @Param Authorization header string true "Bearer token"
-// @Param request body model.AddEarningRequest true "收益信息"
-// @Success 200 {object} v1.Response
-// @Failure 400 {object} v1.Response "参数错误"
-// @Failure 401 {object} v1.Response "未授权"
-// @Failure 500 {object} v1.Response "服务器内部错误"
-// @Router /earning/add [post]
-func (h *UserEarningHandler) AddEarning(c *gin.Context) {
-	var req model.AddEarningRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
-		return
-	}
-
-	// 调用服务层添加收益
-	err := h.userEarningService.AddEarning(c, req)
-	if err != nil {
-		v1.HandleError(c, v1.ErrRegisterCode, err.Error(), err)
-		return
-	}
-
-	v1.HandleSuccess(c, nil)
-}
-
 // GetEarningList godoc
 // @Summary 获取用户收益列表
 // @Description 获取用户的收益记录列表，可按日期筛选
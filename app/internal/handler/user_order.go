@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -52,6 +53,26 @@ func (h *UserOrderHandler) CreateOrders(c *gin.Context) {
 	// Create orders
 	err := h.userOrderService.CreateOrders(c, req)
 	if err != nil {
+		if errors.Is(err, v1.ErrOrderPriceChanged) {
+			v1.HandleError(c, v1.ErrOrderPriceChangedCode, v1.MsgOrderPriceChanged, nil)
+			return
+		}
+		if errors.Is(err, v1.ErrCouponUnavailable) {
+			v1.HandleError(c, v1.ErrCouponUnavailableCode, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, v1.ErrStockNotEnough) {
+			v1.HandleError(c, v1.ErrStockNotEnoughCode, v1.MsgStockNotEnough, nil)
+			return
+		}
+		if errors.Is(err, v1.ErrRegionNotDeliverable) {
+			v1.HandleError(c, v1.ErrRegionNotDeliverableCode, v1.MsgRegionNotDeliverable, nil)
+			return
+		}
+		if err.Error() == "余额不足" || err.Error() == "店铺不存在" || err.Error() == "店铺已关闭" {
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
 		v1.HandleError(c, v1.ErrRegisterCode, "创建订单失败", nil)
 		return
 	}
@@ -59,6 +80,40 @@ func (h *UserOrderHandler) CreateOrders(c *gin.Context) {
 	v1.HandleSuccess(c, nil)
 }
 
+// QuoteFreight godoc
+// @Summary 运费报价
+// @Description 按收货地址和购物车商品计算运费，下单时将每个商品的运费作为 courier_fee_min 提交
+// @Tags 订单
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.FreightQuoteRequest true "运费报价请求"
+// @Success 200 {object} model.FreightQuote
+// @Router /order/freight [post]
+func (h *UserOrderHandler) QuoteFreight(c *gin.Context) {
+	var req model.FreightQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	quote, err := h.userOrderService.QuoteFreight(c, req)
+	if err != nil {
+		if errors.Is(err, v1.ErrRegionNotDeliverable) {
+			v1.HandleError(c, v1.ErrRegionNotDeliverableCode, v1.MsgRegionNotDeliverable, nil)
+			return
+		}
+		if err.Error() == "收货地址不存在" {
+			v1.HandleError(c, v1.ErrNotFoundCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "计算运费失败", nil)
+		return
+	}
+
+	v1.HandleSuccess(c, quote)
+}
+
 // GetOrderList godoc
 // @Summary 获取订单列表
 // @Description 获取用户订单列表，可根据状态筛选
@@ -149,8 +204,11 @@ func (h *UserOrderHandler) UpdateOrderStatus(c *gin.Context) {
 		if err.Error() == "订单不存在或无权限" {
 			v1.HandleError(c, v1.ErrNotFoundCode, err.Error(), nil)
 			return
-		} else if err.Error() == "非法的状态转换" {
-			v1.HandleError(c, v1.ErrParamCode, err.Error(), nil)
+		} else if errors.Is(err, v1.ErrOrderStatusIllegal) {
+			v1.HandleError(c, v1.ErrOrderStatusIllegalCode, v1.MsgOrderStatusIllegal, nil)
+			return
+		} else if errors.Is(err, v1.ErrOrderStatusChanged) {
+			v1.HandleError(c, v1.ErrOrderStatusChangedCode, v1.MsgOrderStatusChanged, nil)
 			return
 		}
 		v1.HandleError(c, v1.ErrRegisterCode, "更新订单状态失败", err)
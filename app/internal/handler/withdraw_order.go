@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -36,7 +37,7 @@ func NewWithdrawOrderHandler(
 // @Success 200 {object} v1.Response
 // @Failure 400 {object} v1.Response "参数错误"
 // @Failure 401 {object} v1.Response "未授权"
-// @Failure 403 {object} v1.Response "余额不足"
+// @Failure 403 {object} v1.Response "余额不足或超出提现限额"
 // @Failure 500 {object} v1.Response "服务器内部错误"
 // @Router /withdraw/create [post]
 func (h *WithdrawOrderHandler) CreateWithdraw(c *gin.Context) {
@@ -49,7 +50,11 @@ func (h *WithdrawOrderHandler) CreateWithdraw(c *gin.Context) {
 	// 调用服务层创建提现单
 	err := h.withdrawOrderService.CreateWithdraw(c, req)
 	if err != nil {
-		if err.Error() == "余额不足" {
+		if errors.Is(err, v1.ErrWithdrawLimit) {
+			v1.HandleError(c, v1.ErrWithdrawLimitCode, err.Error(), nil)
+			return
+		}
+		if err.Error() == "余额不足" || err.Error() == "收款账户不存在" {
 			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
 			return
 		}
@@ -138,3 +143,90 @@ func (h *WithdrawOrderHandler) GetWithdrawDetail(c *gin.Context) {
 
 	v1.HandleSuccess(c, response)
 }
+
+// BindPayoutAccount godoc
+// @Summary 绑定收款账户
+// @Description 绑定银行卡或微信零钱作为提现收款账户，户名和账号加密存储
+// @Tags 提现
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.BindPayoutAccountRequest true "绑定收款账户请求"
+// @Success 200 {object} v1.Response
+// @Failure 400 {object} v1.Response "参数错误"
+// @Failure 401 {object} v1.Response "未授权"
+// @Failure 500 {object} v1.Response "服务器内部错误"
+// @Router /withdraw/account/bind [post]
+func (h *WithdrawOrderHandler) BindPayoutAccount(c *gin.Context) {
+	var req model.BindPayoutAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.withdrawOrderService.BindPayoutAccount(c, req); err != nil {
+		switch err.Error() {
+		case "请填写银行名称和卡号", "未绑定微信，无法提现到微信零钱", "收款方式不合法", "收款账户数量已达上限", "该收款账户已绑定":
+			v1.HandleError(c, v1.ErrOperateCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "绑定收款账户失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, nil)
+}
+
+// GetPayoutAccountList godoc
+// @Summary 获取收款账户列表
+// @Description 获取已绑定的提现收款账户，户名和账号脱敏展示
+// @Tags 提现
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} model.PayoutAccountItem
+// @Failure 401 {object} v1.Response "未授权"
+// @Failure 500 {object} v1.Response "服务器内部错误"
+// @Router /withdraw/account/list [get]
+func (h *WithdrawOrderHandler) GetPayoutAccountList(c *gin.Context) {
+	list, err := h.withdrawOrderService.GetPayoutAccountList(c)
+	if err != nil {
+		v1.HandleError(c, v1.ErrRegisterCode, "获取收款账户列表失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, list)
+}
+
+// DeletePayoutAccount godoc
+// @Summary 删除收款账户
+// @Description 删除已绑定的提现收款账户
+// @Tags 提现
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body model.DeletePayoutAccountRequest true "删除收款账户请求"
+// @Success 200 {object} v1.Response
+// @Failure 400 {object} v1.Response "参数错误"
+// @Failure 401 {object} v1.Response "未授权"
+// @Failure 404 {object} v1.Response "收款账户不存在"
+// @Failure 500 {object} v1.Response "服务器内部错误"
+// @Router /withdraw/account/delete [post]
+func (h *WithdrawOrderHandler) DeletePayoutAccount(c *gin.Context) {
+	var req model.DeletePayoutAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		v1.HandleError(c, v1.ErrParamCode, "参数错误", err)
+		return
+	}
+
+	if err := h.withdrawOrderService.DeletePayoutAccount(c, req.ID); err != nil {
+		if err.Error() == "收款账户不存在" {
+			v1.HandleError(c, v1.ErrNotFoundCode, err.Error(), nil)
+			return
+		}
+		v1.HandleError(c, v1.ErrRegisterCode, "删除收款账户失败", err)
+		return
+	}
+
+	v1.HandleSuccess(c, nil)
+}
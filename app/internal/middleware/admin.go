@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	v1 "app/api/v1"
+	"app/pkg/log"
+)
+
+// AdminMiddleware 运营管理接口鉴权，校验请求头 X-Admin-Token 与配置的 security.admin.token
+// 未配置令牌时关闭管理接口；操作人取自请求头 X-Admin-Operator，用于记录变更日志
+func AdminMiddleware(logger *log.Logger, conf *viper.Viper) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := conf.GetString("security.admin.token")
+		header := ctx.GetHeader("X-Admin-Token")
+		if token == "" || subtle.ConstantTimeCompare([]byte(header), []byte(token)) != 1 {
+			logger.WithContext(ctx).Warn("管理接口鉴权失败", zap.String("url", ctx.Request.URL.Path), zap.String("ip", ctx.ClientIP()))
+			v1.HandleError(ctx, v1.ErrAuthorizationCheckCode, v1.MsgAuthorizationCheck, nil)
+			ctx.Abort()
+			return
+		}
+		operator := ctx.GetHeader("X-Admin-Operator")
+		if operator == "" {
+			operator = "admin"
+		}
+		ctx.Set("admin_operator", operator)
+		recoveryLoggerFunc(ctx, logger)
+		ctx.Next()
+	}
+}
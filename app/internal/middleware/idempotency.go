@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	v1 "app/api/v1"
+	"app/internal/cache"
+	"app/internal/common"
+	"app/pkg/jwt"
+	"app/pkg/log"
+)
+
+const (
+	// IdempotencyKeyHeader 客户端携带的幂等键
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 标记响应来自重放
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// 幂等记录保留时间
+	idempotencyTTL = 24 * time.Hour
+	// 处理中记录的保留时间，请求异常中断未能清理时，到期后允许使用同一个键重试
+	idempotencyPendingTTL = time.Minute
+)
+
+// idempotencyRecord 幂等键对应的请求记录
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"` // 请求体摘要
+	Done        bool   `json:"done"`        // 是否已处理完成
+	Status      int    `json:"status"`      // 响应状态码
+	Body        string `json:"body"`        // 响应内容
+}
+
+// IdempotencyMiddleware 按用户、路由和 Idempotency-Key 去重，需放在 SignMiddleware 之后
+// 首次请求成功后保存响应，重复请求直接重放；同一个键携带不同请求体时拒绝
+func IdempotencyMiddleware(logger *log.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		idempotencyKey := ctx.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			ctx.Next()
+			return
+		}
+		userID := ""
+		if v, ok := ctx.Get("claims"); ok {
+			if claims, ok := v.(*jwt.MyCustomClaims); ok {
+				userID = claims.UserId
+			}
+		}
+
+		var bodyBytes []byte
+		if ctx.Request.Body != nil {
+			bodyBytes, _ = io.ReadAll(ctx.Request.Body)
+			ctx.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		}
+		sum := sha256.Sum256(bodyBytes)
+		fingerprint := hex.EncodeToString(sum[:])
+
+		store := cache.NewCache(ctx, common.PREFFIX_IDEMPOTENCY)
+		store.Expired = idempotencyPendingTTL
+		key := userID + ":" + ctx.FullPath() + ":" + idempotencyKey
+		pending, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		ok, err := store.SetNX(key, pending)
+		if err != nil {
+			// Redis 不可用时不阻断业务
+			logger.WithContext(ctx).Error("写入幂等记录失败", zap.Error(err))
+			ctx.Next()
+			return
+		}
+		if !ok {
+			replayIdempotentResponse(ctx, logger, store, key, fingerprint)
+			return
+		}
+
+		// 处理过程中 panic 时删除处理中记录，再交给 Recovery 处理
+		defer func() {
+			if p := recover(); p != nil {
+				if err := store.Del(key); err != nil {
+					logger.WithContext(ctx).Error("删除幂等记录失败", zap.Error(err))
+				}
+				panic(p)
+			}
+		}()
+
+		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: ctx.Writer}
+		ctx.Writer = blw
+		ctx.Next()
+
+		// 只保存成功的响应，失败的请求未产生资金变动，允许使用同一个键重试
+		var resp v1.Response
+		if err := json.Unmarshal(blw.body.Bytes(), &resp); err != nil || resp.Code != v1.SuccessCode {
+			if err := store.Del(key); err != nil {
+				logger.WithContext(ctx).Error("删除幂等记录失败", zap.Error(err))
+			}
+			return
+		}
+		done, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      blw.Status(),
+			Body:        blw.body.String(),
+		})
+		store.Expired = idempotencyTTL
+		if err := store.Set(key, done); err != nil {
+			logger.WithContext(ctx).Error("保存幂等记录失败", zap.Error(err))
+		}
+	}
+}
+
+// replayIdempotentResponse 处理重复请求
+func replayIdempotentResponse(ctx *gin.Context, logger *log.Logger, store *cache.Cache, key string, fingerprint string) {
+	value, err := store.GetString(key)
+	if err != nil {
+		// 记录恰好过期或被删除，按处理中返回，客户端重试即可
+		if !errors.Is(err, redis.Nil) {
+			logger.WithContext(ctx).Error("读取幂等记录失败", zap.Error(err))
+		}
+		v1.HandleError(ctx, v1.ErrIdempotencyProcessingCode, v1.MsgIdempotencyProcessing, nil)
+		ctx.Abort()
+		return
+	}
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		logger.WithContext(ctx).Error("解析幂等记录失败", zap.Error(err))
+		v1.HandleError(ctx, v1.ErrIdempotencyProcessingCode, v1.MsgIdempotencyProcessing, nil)
+		ctx.Abort()
+		return
+	}
+	if record.Fingerprint != fingerprint {
+		v1.HandleError(ctx, v1.ErrIdempotencyConflictCode, v1.MsgIdempotencyConflict, nil)
+		ctx.Abort()
+		return
+	}
+	if !record.Done {
+		v1.HandleError(ctx, v1.ErrIdempotencyProcessingCode, v1.MsgIdempotencyProcessing, nil)
+		ctx.Abort()
+		return
+	}
+	ctx.Header(IdempotentReplayedHeader, "true")
+	ctx.Data(record.Status, "application/json; charset=utf-8", []byte(record.Body))
+	ctx.Abort()
+}
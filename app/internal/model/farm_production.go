@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// FarmProduction 农场每日产蛋记录，由农场录入，用于生成认养用户的每日收益
+// 指定鸵鸟时产蛋计入该鸵鸟；未指定时按栏舍记录，由栏舍内在养的认养鸵鸟平均分配
+type FarmProduction struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	FarmID         uint64    `gorm:"column:farm_id;type:bigint unsigned;not null;default:0;comment:农场ID" json:"farm_id"`                     // 农场ID
+	Pen            string    `gorm:"column:pen;type:varchar(64);not null;default:'';comment:栏舍，按栏舍记录时必填" json:"pen"`                         // 栏舍
+	OstrichID      uint64    `gorm:"column:ostrich_id;type:bigint unsigned;not null;default:0;comment:鸵鸟ID，为0表示按栏舍记录" json:"ostrich_id"`     // 鸵鸟ID
+	ProductionDate string    `gorm:"column:production_date;type:varchar(10);not null;index;comment:产蛋日期(YYYY-MM-DD)" json:"production_date"` // 产蛋日期
+	EggCount       int64     `gorm:"column:egg_count;type:bigint;not null;default:0;comment:产蛋数量" json:"egg_count"`                          // 产蛋数量
+	Remark         string    `gorm:"column:remark;type:varchar(255);not null;default:'';comment:备注" json:"remark"`                           // 备注
+	Allocated      uint8     `gorm:"column:allocated;type:tinyint;not null;default:0;comment:是否已分配到鸵鸟（0:否；1:是）" json:"allocated"`            // 是否已分配
+	CreatedAt      time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                       // 创建时间
+	UpdatedAt      time.Time `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                       // 更新时间
+}
+
+func (m *FarmProduction) TableName() string {
+	return "farm_production"
+}
+
+// FarmProductionAllocation 产蛋记录分配到鸵鸟的数量，分配后不再随栏舍变动重新计算
+type FarmProductionAllocation struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ProductionID   uint64    `gorm:"column:production_id;type:bigint unsigned;not null;uniqueIndex:uk_production_ostrich,priority:1;comment:产蛋记录ID" json:"production_id"` // 产蛋记录ID
+	OstrichID      uint64    `gorm:"column:ostrich_id;type:bigint unsigned;not null;uniqueIndex:uk_production_ostrich,priority:2;comment:鸵鸟ID" json:"ostrich_id"`         // 鸵鸟ID
+	ProductionDate string    `gorm:"column:production_date;type:varchar(10);not null;index:idx_date_status;comment:产蛋日期(YYYY-MM-DD)" json:"production_date"`              // 产蛋日期
+	Amount         int64     `gorm:"column:amount;type:bigint;not null;default:0;comment:分配数量" json:"amount"`                                                             // 分配数量
+	Status         uint8     `gorm:"column:status;type:tinyint;not null;default:1;index:idx_date_status;comment:状态(1:待入账;2:已入账;3:已跳过)" json:"status"`                     // 状态
+	EarningID      uint64    `gorm:"column:earning_id;type:bigint unsigned;not null;default:0;comment:入账的收益ID" json:"earning_id"`                                         // 入账的收益ID
+	CreatedAt      time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                                                    // 创建时间
+	UpdatedAt      time.Time `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                                                    // 更新时间
+}
+
+func (m *FarmProductionAllocation) TableName() string {
+	return "farm_production_allocation"
+}
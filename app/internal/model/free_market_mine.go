@@ -19,8 +19,8 @@ func (m *FreeMarketMine) TableName() string {
 	return "free_market_mine"
 }
 
-// Define a struct to receive parameters
+// UpdateEggPriceRequest 修改出售挂单价格请求，价格单位为元
 type UpdateEggPriceRequest struct {
 	ID    int     `form:"id" binding:"required"`
-	Price float64 `form:"price" binding:"required"`
+	Price float64 `form:"price" binding:"required,gt=0"`
 }
@@ -1,16 +1,17 @@
 package model
 
 type FreeMarketMineResponse struct {
-	SelledList []SelledEggDTO `json:"selled_list"`
-	NoSellList []NoSellEggDTO `json:"no_sell_list"`
-	Summary    SummaryDTO     `json:"summary"`
-	Others     []NoSellEggDTO `json:"others"`
+	SelledList []SelledEggDTO   `json:"selled_list"`
+	NoSellList []NoSellEggDTO   `json:"no_sell_list"`
+	Summary    SummaryDTO       `json:"summary"`
+	PriceBand  *MarketPriceBand `json:"price_band"`
 }
 
 // SummaryDTO for summary
 type SummaryDTO struct {
-	TotalSelled int `json:"total_selled"`
-	TotalNoSell int `json:"total_no_sell"`
+	TotalSelled    int     `json:"total_selled"`
+	TotalNoSell    int     `json:"total_no_sell"`
+	ReferencePrice float64 `json:"reference_price"`
 }
 
 // SelledEggDTO for sold eggs
@@ -23,8 +24,16 @@ type SelledEggDTO struct {
 
 // NoSellEggDTO for unsold eggs
 type NoSellEggDTO struct {
-	Id       int     `json:"id"`
-	EggPrice float64 `json:"egg_price"`
-	EggNum   int     `json:"egg_num"`
-	Date     string  `json:"date"`
+	Id        int           `json:"id"`
+	EggPrice  float64       `json:"egg_price"`
+	EggNum    int           `json:"egg_num"`
+	Date      string        `json:"date"`
+	PriceLogs []PriceLogDTO `json:"price_logs"`
+}
+
+// PriceLogDTO for price changes of unsold eggs
+type PriceLogDTO struct {
+	OldPrice float64 `json:"old_price"`
+	NewPrice float64 `json:"new_price"`
+	Time     string  `json:"time"`
 }
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+
+	"app/pkg/money"
+)
+
+// FreightTemplate 运费模板
+type FreightTemplate struct {
+	ID         uint64        `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	Name       string        `gorm:"column:name;type:varchar(100);not null;comment:模板名称" json:"name"`                                 // 模板名称
+	ChargeType uint8         `gorm:"column:charge_type;type:tinyint;not null;default:1;comment:计费方式(1:按件数;2:按重量)" json:"charge_type"` // 计费方式
+	IsDefault  uint8         `gorm:"column:is_default;type:tinyint;not null;default:0;comment:是否默认模板（0:否；1:是）" json:"is_default"`     // 是否默认模板
+	CreatedAt  time.Time     `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                // 创建时间
+	UpdatedAt  time.Time     `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                // 更新时间
+	Rules      []FreightRule `gorm:"foreignKey:TemplateID;references:ID" json:"rules"`                                                // 地区规则
+}
+
+func (m *FreightTemplate) TableName() string {
+	return "freight_template"
+}
+
+// FreightRule 运费模板的地区规则，省市区为空表示不限，匹配时取最具体的一条
+type FreightRule struct {
+	ID             uint64      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	TemplateID     uint64      `gorm:"column:template_id;type:bigint unsigned;not null;index;comment:运费模板ID" json:"template_id"`             // 运费模板ID
+	Province       string      `gorm:"column:province;type:varchar(255);not null;default:'';comment:省" json:"province"`                      // 省
+	City           string      `gorm:"column:city;type:varchar(255);not null;default:'';comment:城市" json:"city"`                             // 城市
+	District       string      `gorm:"column:district;type:varchar(255);not null;default:'';comment:区/县" json:"district"`                    // 区/县
+	FirstUnit      int         `gorm:"column:first_unit;type:int;not null;default:1;comment:首件数或首重（克）" json:"first_unit"`                    // 首件数或首重（克）
+	FirstFee       money.Money `gorm:"column:first_fee;type:bigint;not null;default:0;comment:首件或首重运费（分）" json:"first_fee"`                  // 首件或首重运费
+	ExtraUnit      int         `gorm:"column:extra_unit;type:int;not null;default:1;comment:续件数或续重（克）" json:"extra_unit"`                    // 续件数或续重（克）
+	ExtraFee       money.Money `gorm:"column:extra_fee;type:bigint;not null;default:0;comment:续件或续重运费（分）" json:"extra_fee"`                  // 续件或续重运费
+	FreeThreshold  money.Money `gorm:"column:free_threshold;type:bigint;not null;default:0;comment:包邮门槛（分），0表示不包邮" json:"free_threshold"`    // 包邮门槛
+	NotDeliverable uint8       `gorm:"column:not_deliverable;type:tinyint;not null;default:0;comment:是否不配送（0:否；1:是）" json:"not_deliverable"` // 是否不配送
+	CreatedAt      time.Time   `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                     // 创建时间
+	UpdatedAt      time.Time   `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                     // 更新时间
+}
+
+func (m *FreightRule) TableName() string {
+	return "freight_rule"
+}
+
+// FreightRegion 收货地区
+type FreightRegion struct {
+	Province string `json:"province"` // 省
+	City     string `json:"city"`     // 城市
+	District string `json:"district"` // 区/县
+}
+
+// FreightItem 参与运费计算的商品
+type FreightItem struct {
+	ProductID uint64 `json:"product_id" binding:"required"` // 商品ID
+	Quantity  int    `json:"quantity" binding:"required"`   // 商品数量
+}
+
+// FreightQuoteRequest 运费报价请求
+type FreightQuoteRequest struct {
+	AddressID uint64        `json:"address_id" binding:"required"` // 收货地址ID
+	Items     []FreightItem `json:"items" binding:"required,dive"` // 购物车商品
+}
+
+// FreightQuote 运费报价
+type FreightQuote struct {
+	Items      []FreightItemQuote `json:"items"`       // 每个商品分摊的运费
+	CourierFee money.Money        `json:"courier_fee"` // 运费合计
+}
+
+// FreightItemQuote 商品分摊的运费，下单时作为 courier_fee_min 提交
+type FreightItemQuote struct {
+	ProductID  uint64      `json:"product_id"`  // 商品ID
+	CourierFee money.Money `json:"courier_fee"` // 运费
+}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"time"
+
+	"app/pkg/money"
+)
+
+// MarketOrder 自由市场挂单，出售挂单冻结卖方的鸟蛋，求购挂单冻结买方的余额
+// 挂单按价格优先、时间优先与对手方挂单撮合，可分多次成交，成交价为先挂单一方的价格
+type MarketOrder struct {
+	ID             uint64      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID         string      `gorm:"column:user_id;type:varchar(30);not null;index:idx_user_status;comment:用户ID" json:"user_id"`                                      // 用户ID
+	PublishType    uint8       `gorm:"column:publish_type;type:tinyint;not null;index:idx_book,priority:1;comment:挂单类型(1:出售 2:求购)" json:"publish_type"`                 // 挂单类型
+	Price          money.Money `gorm:"column:price;type:bigint;not null;index:idx_book,priority:3;comment:单价（分）" json:"price"`                                          // 单价
+	Quantity       int64       `gorm:"column:quantity;type:bigint;not null;comment:挂单数量" json:"quantity"`                                                               // 挂单数量
+	FilledQuantity int64       `gorm:"column:filled_quantity;type:bigint;not null;default:0;comment:已成交数量" json:"filled_quantity"`                                      // 已成交数量
+	Status         uint8       `gorm:"column:status;type:tinyint;not null;index:idx_user_status;index:idx_book,priority:2;comment:状态(1:挂单中 2:已成交 3:已撤单)" json:"status"` // 状态
+	CreatedAt      time.Time   `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                                                // 创建时间
+	UpdatedAt      time.Time   `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                                                // 更新时间
+}
+
+func (m *MarketOrder) TableName() string {
+	return "market_order"
+}
+
+// Remaining 未成交数量
+func (m *MarketOrder) Remaining() int64 {
+	return m.Quantity - m.FilledQuantity
+}
+
+// MarketTrade 自由市场成交记录，一次撮合生成一条，手续费由卖方承担
+type MarketTrade struct {
+	ID          uint64      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	SellOrderID uint64      `gorm:"column:sell_order_id;not null;index;comment:出售挂单ID" json:"sell_order_id"`          // 出售挂单ID
+	BuyOrderID  uint64      `gorm:"column:buy_order_id;not null;index;comment:求购挂单ID" json:"buy_order_id"`            // 求购挂单ID
+	SellerID    string      `gorm:"column:seller_id;type:varchar(30);not null;index;comment:卖方用户ID" json:"seller_id"` // 卖方用户ID
+	BuyerID     string      `gorm:"column:buyer_id;type:varchar(30);not null;index;comment:买方用户ID" json:"buyer_id"`   // 买方用户ID
+	Price       money.Money `gorm:"column:price;type:bigint;not null;comment:成交单价（分）" json:"price"`                   // 成交单价
+	Quantity    int64       `gorm:"column:quantity;type:bigint;not null;comment:成交数量" json:"quantity"`                // 成交数量
+	Amount      money.Money `gorm:"column:amount;type:bigint;not null;comment:成交金额（分）" json:"amount"`                 // 成交金额
+	Fee         money.Money `gorm:"column:fee;type:bigint;not null;default:0;comment:手续费（分），从卖方所得中扣除" json:"fee"`     // 手续费
+	CreatedAt   time.Time   `gorm:"column:created_at;comment:成交时间" json:"created_at"`                                 // 成交时间
+}
+
+func (m *MarketTrade) TableName() string {
+	return "market_trade"
+}
+
+// MarketPriceLog 挂单改价记录
+type MarketPriceLog struct {
+	ID        uint64      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	OrderID   uint64      `gorm:"column:order_id;not null;index;comment:挂单ID" json:"order_id"`                                 // 挂单ID
+	UserID    string      `gorm:"column:user_id;type:varchar(30);not null;index:idx_user_created;comment:用户ID" json:"user_id"` // 用户ID
+	OldPrice  money.Money `gorm:"column:old_price;type:bigint;not null;comment:修改前单价（分）" json:"old_price"`                     // 修改前单价
+	NewPrice  money.Money `gorm:"column:new_price;type:bigint;not null;comment:修改后单价（分）" json:"new_price"`                     // 修改后单价
+	CreatedAt time.Time   `gorm:"column:created_at;index:idx_user_created;comment:修改时间" json:"created_at"`                     // 修改时间
+}
+
+func (m *MarketPriceLog) TableName() string {
+	return "market_price_log"
+}
+
+// MarketCandle 自由市场K线，按周期汇总成交的开盘、最高、最低、收盘价和成交量，每笔成交时更新所在周期
+type MarketCandle struct {
+	ID         uint64      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	Period     string      `gorm:"column:period;type:varchar(8);not null;uniqueIndex:uk_period_start,priority:1;comment:周期(1h 1d 1w)" json:"period"` // 周期
+	StartAt    time.Time   `gorm:"column:start_at;not null;uniqueIndex:uk_period_start,priority:2;comment:周期开始时间" json:"start_at"`                   // 周期开始时间
+	Open       money.Money `gorm:"column:open;type:bigint;not null;comment:开盘价（分）" json:"open"`                                                      // 开盘价
+	High       money.Money `gorm:"column:high;type:bigint;not null;comment:最高价（分）" json:"high"`                                                      // 最高价
+	Low        money.Money `gorm:"column:low;type:bigint;not null;comment:最低价（分）" json:"low"`                                                        // 最低价
+	Close      money.Money `gorm:"column:close;type:bigint;not null;comment:收盘价（分）" json:"close"`                                                    // 收盘价
+	Volume     int64       `gorm:"column:volume;type:bigint;not null;default:0;comment:成交量" json:"volume"`                                           // 成交量
+	Amount     money.Money `gorm:"column:amount;type:bigint;not null;default:0;comment:成交额（分）" json:"amount"`                                        // 成交额
+	TradeCount int64       `gorm:"column:trade_count;type:bigint;not null;default:0;comment:成交笔数" json:"trade_count"`                                // 成交笔数
+	CreatedAt  time.Time   `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                                 // 创建时间
+	UpdatedAt  time.Time   `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                                 // 更新时间
+}
+
+func (m *MarketCandle) TableName() string {
+	return "market_candle"
+}
+
+// MarketConfig 自由市场配置，配置在 sys_params 的 market_config 中
+// 手续费按成交金额计算，由卖方承担；挂单价格限制在参考价上下 PriceBandRate 的范围内，参考价未配置时取前一交易日的最后成交价
+type MarketConfig struct {
+	FeeRate        float64     `json:"fee_rate"`         // 手续费率
+	MinFee         money.Money `json:"min_fee"`          // 单笔最低手续费
+	ReferencePrice money.Money `json:"reference_price"`  // 参考价
+	PriceBandRate  float64     `json:"price_band_rate"`  // 每日价格浮动范围，如0.1表示参考价上下10%，为0表示不限
+	PriceEditLimit int         `json:"price_edit_limit"` // 每人每日改价次数上限，为0表示不限
+}
+
+// MarketPriceBand 今日可挂单的价格范围
+type MarketPriceBand struct {
+	ReferencePrice money.Money `json:"reference_price"` // 参考价
+	MinPrice       money.Money `json:"min_price"`       // 最低价
+	MaxPrice       money.Money `json:"max_price"`       // 最高价
+}
+
+// PublishMarketOrderRequest 发布挂单请求
+type PublishMarketOrderRequest struct {
+	PublishType uint8       `json:"publish_type" binding:"required,oneof=1 2"` // 挂单类型(1:出售 2:求购)
+	Price       money.Money `json:"price" binding:"required,gt=0"`             // 单价
+	Quantity    int64       `json:"quantity" binding:"required,gt=0"`          // 数量
+}
+
+// CancelMarketOrderRequest 撤单请求
+type CancelMarketOrderRequest struct {
+	ID uint64 `json:"id" binding:"required"` // 挂单ID
+}
+
+// MarketOrderBookRequest 查询挂单簿请求
+type MarketOrderBookRequest struct {
+	PublishType uint8 `form:"publish_type" binding:"required,oneof=1 2"` // 挂单类型(1:出售 2:求购)
+	Page        int   `form:"page" json:"page"`                          // 页码
+	PageSize    int   `form:"page_size" json:"page_size"`                // 每页条数
+}
+
+// MarketOrderItem 挂单信息
+type MarketOrderItem struct {
+	ID             uint64      `json:"id"`              // 挂单ID
+	PublishType    uint8       `json:"publish_type"`    // 挂单类型(1:出售 2:求购)
+	Price          money.Money `json:"price"`           // 单价
+	Quantity       int64       `json:"quantity"`        // 挂单数量
+	FilledQuantity int64       `json:"filled_quantity"` // 已成交数量
+	Remaining      int64       `json:"remaining"`       // 未成交数量
+	Status         uint8       `json:"status"`          // 状态(1:挂单中 2:已成交 3:已撤单)
+	Mine           bool        `json:"mine"`            // 是否为自己的挂单
+	CreatedAt      time.Time   `json:"created_at"`      // 挂单时间
+}
+
+// MarketOrderBookResponse 挂单簿，出售按价格从低到高、求购按价格从高到低排列，同价格先挂单的在前
+type MarketOrderBookResponse struct {
+	Total int64             `json:"total"` // 总数
+	List  []MarketOrderItem `json:"list"`  // 挂单列表
+	Page  int               `json:"page"`  // 页码
+	Size  int               `json:"size"`  // 每页条数
+}
+
+// MarketCandleRequest 查询K线请求
+type MarketCandleRequest struct {
+	Interval string `form:"interval" binding:"required,oneof=1h 1d 1w"` // 周期(1h 1d 1w)
+	End      int64  `form:"end"`                                        // 截止时间戳（秒），为空表示当前时间
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=500"`    // 返回的周期数，默认100
+}
+
+// MarketCandleItem K线数据，价格和成交额单位为元
+type MarketCandleItem struct {
+	StartAt    time.Time   `json:"start_at"`    // 周期开始时间
+	Open       money.Money `json:"open"`        // 开盘价
+	High       money.Money `json:"high"`        // 最高价
+	Low        money.Money `json:"low"`         // 最低价
+	Close      money.Money `json:"close"`       // 收盘价
+	Volume     int64       `json:"volume"`      // 成交量
+	Amount     money.Money `json:"amount"`      // 成交额
+	TradeCount int64       `json:"trade_count"` // 成交笔数
+}
+
+// MarketCandleResponse K线，按时间从早到晚排列，没有成交的周期不返回
+type MarketCandleResponse struct {
+	Interval       string             `json:"interval"`        // 周期
+	ReferencePrice money.Money        `json:"reference_price"` // 今日参考价，为0表示暂无
+	List           []MarketCandleItem `json:"list"`            // K线列表
+}
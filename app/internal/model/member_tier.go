@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"app/pkg/money"
+)
+
+// MemberTierRule 会员等级规则，配置在 sys_params 的 member_tier_rules 中
+type MemberTierRule struct {
+	Role       int         `json:"role"`        // 会员身份
+	Name       string      `json:"name"`        // 等级名称
+	SpendType  string      `json:"spend_type"`  // 统计的消费类型(buy:购买消费;adopt:认养鸵鸟;all:全部)
+	MinAmount  money.Money `json:"min_amount"`  // 达到该等级的最低消费金额
+	WindowDays int         `json:"window_days"` // 统计最近多少天的消费，0 表示不限
+	Manual     bool        `json:"manual"`      // 是否只能由后台指定
+}
+
+// AccountRoleLog 用户身份变更记录
+type AccountRoleLog struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID    string    `gorm:"column:user_id;type:varchar(30);not null;index;comment:用户ID" json:"user_id"`                       // 用户ID
+	FromRole  int       `gorm:"column:from_role;type:tinyint;not null;default:0;comment:变更前身份" json:"from_role"`                  // 变更前身份
+	ToRole    int       `gorm:"column:to_role;type:tinyint;not null;default:0;comment:变更后身份" json:"to_role"`                      // 变更后身份
+	ActorType uint8     `gorm:"column:actor_type;type:tinyint;not null;default:0;comment:操作方(1:用户;2:系统;3:管理员)" json:"actor_type"` // 操作方
+	ActorID   string    `gorm:"column:actor_id;type:varchar(255);default:'';comment:操作人ID" json:"actor_id"`                       // 操作人ID
+	Reason    string    `gorm:"column:reason;type:varchar(255);default:'';comment:变更原因" json:"reason"`                            // 变更原因
+	CreatedAt time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                 // 创建时间
+}
+
+func (m *AccountRoleLog) TableName() string {
+	return "account_role_log"
+}
+
+// AssignRoleRequest 后台指定用户身份请求
+type AssignRoleRequest struct {
+	UserID string `json:"user_id" binding:"required"`          // 用户ID
+	Role   int    `json:"role" binding:"required,min=1,max=6"` // 会员身份
+	Reason string `json:"reason" binding:"required,max=255"`   // 指定原因
+}
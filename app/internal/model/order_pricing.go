@@ -0,0 +1,43 @@
+package model
+
+import "app/pkg/money"
+
+// OrderQuote 服务端计算的订单报价
+type OrderQuote struct {
+	Items          []OrderItemQuote `json:"items"`           // 订单项报价
+	GoodsFee       money.Money      `json:"goods_fee"`       // 商品总价
+	CourierFee     money.Money      `json:"courier_fee"`     // 运费
+	CouponDiscount money.Money      `json:"coupon_discount"` // 优惠券抵扣
+	MemberDiscount money.Money      `json:"member_discount"` // 会员折扣
+	TotalFee       money.Money      `json:"total_fee"`       // 应付总额
+}
+
+// OrderItemQuote 服务端计算的订单项报价
+type OrderItemQuote struct {
+	ProductID      uint64      `json:"product_id"`      // 商品ID
+	ProductName    string      `json:"product_name"`    // 商品名称
+	HeaderImg      string      `json:"header_img"`      // 商品头部图片
+	StoreID        uint64      `json:"store_id"`        // 店铺ID
+	Category1ID    int         `json:"category1_id"`    // 一级分类ID
+	Category2ID    int         `json:"category2_id"`    // 二级分类ID
+	Quantity       int         `json:"quantity"`        // 商品数量
+	UnitPrice      money.Money `json:"unit_price"`      // 商品单价
+	GoodsFee       money.Money `json:"goods_fee"`       // 商品小计
+	CourierFee     money.Money `json:"courier_fee"`     // 运费
+	CouponID       uint64      `json:"coupon_id"`       // 优惠券ID
+	UserCouponID   uint64      `json:"user_coupon_id"`  // 用户优惠券记录ID
+	CouponPrice    money.Money `json:"coupon_price"`    // 优惠券抵扣
+	MemberDiscount money.Money `json:"member_discount"` // 会员折扣
+	TotalFee       money.Money `json:"total_fee"`       // 订单项应付金额
+	Note           string      `json:"note"`            // 备注
+}
+
+// CouponApplication 优惠券校验通过后在订单项上的抵扣结果
+type CouponApplication struct {
+	UserCouponID uint64      // 用户优惠券记录ID
+	CouponName   string      // 优惠券名称
+	Discount     money.Money // 抵扣金额
+	FreeShipping bool        // 是否抵扣运费
+	StackMember  bool        // 是否可与会员折扣叠加
+	Exclusive    bool        // 是否独占
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// OrderStatusLog 订单状态变更记录
+type OrderStatusLog struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	OrderID     uint64    `gorm:"column:order_id;type:bigint unsigned;not null;index;comment:订单ID" json:"order_id"`                 // 订单ID
+	OrderItemID uint64    `gorm:"column:order_item_id;type:bigint unsigned;not null;index;comment:订单项ID" json:"order_item_id"`      // 订单项ID
+	FromStatus  uint8     `gorm:"column:from_status;type:tinyint;not null;default:0;comment:变更前状态" json:"from_status"`              // 变更前状态
+	ToStatus    uint8     `gorm:"column:to_status;type:tinyint;not null;default:0;comment:变更后状态" json:"to_status"`                  // 变更后状态
+	ActorType   uint8     `gorm:"column:actor_type;type:tinyint;not null;default:0;comment:操作方(1:用户;2:系统;3:管理员)" json:"actor_type"` // 操作方
+	ActorID     string    `gorm:"column:actor_id;type:varchar(255);default:'';comment:操作人ID" json:"actor_id"`                       // 操作人ID
+	Remark      string    `gorm:"column:remark;type:varchar(255);default:'';comment:备注" json:"remark"`                              // 备注
+	CreatedAt   time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                 // 创建时间
+}
+
+func (m *OrderStatusLog) TableName() string {
+	return "order_status_log"
+}
+
+// OrderTimelineDTO 订单时间线节点
+type OrderTimelineDTO struct {
+	Status     int    `json:"status"`      // 变更后状态
+	StatusText string `json:"status_text"` // 状态文本
+	ActorType  uint8  `json:"actor_type"`  // 操作方
+	Remark     string `json:"remark"`      // 备注
+	CreatedAt  string `json:"created_at"`  // 变更时间
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"time"
+)
+
+// Ostrich 认养鸵鸟，认养订单支付后按购买数量逐只登记，耳标号在登记时生成
+// 农场取自订单项的店铺，栏舍和出生日期由后台补录；未补录出生日期时按登记时间计算日龄
+type Ostrich struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	TagNo       string     `gorm:"column:tag_no;type:varchar(32);not null;uniqueIndex;comment:耳标号" json:"tag_no"`                                         // 耳标号
+	UserID      string     `gorm:"column:user_id;type:varchar(30);not null;index:idx_user_status;comment:用户ID" json:"user_id"`                            // 用户ID
+	OrderID     uint64     `gorm:"column:order_id;type:bigint unsigned;not null;comment:订单ID" json:"order_id"`                                            // 订单ID
+	OrderItemID uint64     `gorm:"column:order_item_id;type:bigint unsigned;not null;index;comment:订单项ID" json:"order_item_id"`                           // 订单项ID
+	ProductID   uint64     `gorm:"column:product_id;type:bigint unsigned;not null;comment:商品ID" json:"product_id"`                                        // 商品ID
+	ProductName string     `gorm:"column:product_name;type:varchar(255);not null;default:'';comment:商品名称" json:"product_name"`                            // 商品名称
+	Image       string     `gorm:"column:image;type:varchar(255);not null;default:'';comment:图片" json:"image"`                                            // 图片
+	Type        uint8      `gorm:"column:type;type:tinyint;not null;comment:类型(1:商品鸟 2:种鸟)" json:"type"`                                                  // 类型
+	FarmID      uint64     `gorm:"column:farm_id;type:bigint unsigned;not null;default:0;comment:农场ID" json:"farm_id"`                                    // 农场ID
+	FarmName    string     `gorm:"column:farm_name;type:varchar(255);not null;default:'';comment:农场名称" json:"farm_name"`                                  // 农场名称
+	Pen         string     `gorm:"column:pen;type:varchar(64);not null;default:'';comment:栏舍" json:"pen"`                                                 // 栏舍
+	BirthDate   *time.Time `gorm:"column:birth_date;type:date;comment:出生日期" json:"birth_date"`                                                            // 出生日期
+	Status      uint8      `gorm:"column:status;type:tinyint;not null;index:idx_user_status;comment:状态(1:雏鸟 2:育成期 3:成年 4:已出售 5:已死亡 6:已退订)" json:"status"` // 状态
+	CreatedAt   time.Time  `gorm:"column:created_at;comment:登记时间" json:"created_at"`                                                                      // 登记时间
+	UpdatedAt   time.Time  `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                                      // 更新时间
+}
+
+func (m *Ostrich) TableName() string {
+	return "ostrich"
+}
+
+// OstrichStatusLog 认养鸵鸟状态变更记录
+type OstrichStatusLog struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	OstrichID  uint64    `gorm:"column:ostrich_id;type:bigint unsigned;not null;index;comment:鸵鸟ID" json:"ostrich_id"`             // 鸵鸟ID
+	FromStatus uint8     `gorm:"column:from_status;type:tinyint;not null;default:0;comment:变更前状态" json:"from_status"`              // 变更前状态
+	ToStatus   uint8     `gorm:"column:to_status;type:tinyint;not null;default:0;comment:变更后状态" json:"to_status"`                  // 变更后状态
+	ActorType  uint8     `gorm:"column:actor_type;type:tinyint;not null;default:0;comment:操作方(1:用户;2:系统;3:管理员)" json:"actor_type"` // 操作方
+	ActorID    string    `gorm:"column:actor_id;type:varchar(255);default:'';comment:操作人ID" json:"actor_id"`                       // 操作人ID
+	Remark     string    `gorm:"column:remark;type:varchar(255);default:'';comment:备注" json:"remark"`                              // 备注
+	CreatedAt  time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                 // 创建时间
+}
+
+func (m *OstrichStatusLog) TableName() string {
+	return "ostrich_status_log"
+}
+
+// OstrichListRequest 查询我的鸵鸟请求
+type OstrichListRequest struct {
+	Status   uint8 `form:"status" binding:"omitempty,oneof=1 2 3 4 5 6"` // 状态，为空表示全部
+	Page     int   `form:"page" json:"page"`                             // 页码
+	PageSize int   `form:"page_size" json:"page_size"`                   // 每页条数
+}
+
+// OstrichItem 认养鸵鸟信息
+type OstrichItem struct {
+	ID          uint64     `json:"id"`            // 鸵鸟ID
+	TagNo       string     `json:"tag_no"`        // 耳标号
+	OrderItemID uint64     `json:"order_item_id"` // 订单项ID
+	ProductID   uint64     `json:"product_id"`    // 商品ID
+	ProductName string     `json:"product_name"`  // 商品名称
+	Image       string     `json:"image"`         // 图片
+	Type        uint8      `json:"type"`          // 类型(1:商品鸟 2:种鸟)
+	TypeText    string     `json:"type_text"`     // 类型文本
+	FarmName    string     `json:"farm_name"`     // 农场名称
+	Pen         string     `json:"pen"`           // 栏舍
+	BirthDate   *time.Time `json:"birth_date"`    // 出生日期
+	AgeDays     int        `json:"age_days"`      // 日龄
+	Status      uint8      `json:"status"`        // 状态
+	StatusText  string     `json:"status_text"`   // 状态文本
+	CreatedAt   time.Time  `json:"created_at"`    // 登记时间
+}
+
+// OstrichListResponse 我的鸵鸟列表
+type OstrichListResponse struct {
+	Total int64         `json:"total"` // 总数
+	List  []OstrichItem `json:"list"`  // 鸵鸟列表
+	Page  int           `json:"page"`  // 页码
+	Size  int           `json:"size"`  // 每页条数
+}
+
+// OstrichTimelineDTO 认养鸵鸟状态时间线节点
+type OstrichTimelineDTO struct {
+	Status     uint8  `json:"status"`      // 变更后状态
+	StatusText string `json:"status_text"` // 状态文本
+	ActorType  uint8  `json:"actor_type"`  // 操作方
+	Remark     string `json:"remark"`      // 备注
+	CreatedAt  string `json:"created_at"`  // 变更时间
+}
+
+// OstrichDetailResponse 认养鸵鸟详情
+type OstrichDetailResponse struct {
+	OstrichItem
+	OrderNo  string               `json:"order_no"` // 订单号
+	Timeline []OstrichTimelineDTO `json:"timeline"` // 状态时间线
+}
+
+// UpdateOstrichRequest 后台补录鸵鸟栏舍和出生日期请求，字段为空表示不修改
+type UpdateOstrichRequest struct {
+	IDs       []uint64 `json:"ids" binding:"required,min=1,max=500"`               // 鸵鸟ID
+	Pen       *string  `json:"pen" binding:"omitempty,max=64"`                     // 栏舍
+	BirthDate string   `json:"birth_date" binding:"omitempty,datetime=2006-01-02"` // 出生日期(YYYY-MM-DD)
+}
+
+// ChangeOstrichStatusRequest 后台变更鸵鸟状态请求
+type ChangeOstrichStatusRequest struct {
+	ID     uint64 `json:"id" binding:"required"`                     // 鸵鸟ID
+	Status uint8  `json:"status" binding:"required,oneof=2 3 4 5 6"` // 变更后状态
+	Remark string `json:"remark" binding:"required,max=255"`         // 备注
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// PaymentTransaction 支付流水，一笔商户订单号对应一条记录
+type PaymentTransaction struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	OutTradeNo    string     `gorm:"column:out_trade_no;type:varchar(64);not null;uniqueIndex;comment:商户订单号" json:"out_trade_no"`             // 商户订单号
+	BusinessType  uint8      `gorm:"column:business_type;type:tinyint;not null;default:0;comment:业务类型" json:"business_type"`                  // 业务类型
+	BusinessID    uint64     `gorm:"column:business_id;type:bigint unsigned;not null;index;comment:业务ID" json:"business_id"`                  // 业务ID
+	UserID        string     `gorm:"column:user_id;type:varchar(255);not null;index;comment:用户ID" json:"user_id"`                             // 用户ID
+	Provider      string     `gorm:"column:provider;type:varchar(32);not null;comment:支付渠道" json:"provider"`                                  // 支付渠道
+	Amount        int64      `gorm:"column:amount;type:bigint;not null;default:0;comment:支付金额（分）" json:"amount"`                              // 支付金额（分）
+	Status        uint8      `gorm:"column:status;type:tinyint;not null;default:0;comment:支付状态(0:待支付;1:已支付;2:已关闭;3:待退回;4:已退回)" json:"status"` // 支付状态
+	PrepayID      string     `gorm:"column:prepay_id;type:varchar(128);default:'';comment:预支付交易会话标识" json:"prepay_id"`                        // 预支付交易会话标识
+	TransactionID string     `gorm:"column:transaction_id;type:varchar(64);default:'';comment:渠道交易号" json:"transaction_id"`                   // 渠道交易号
+	PaidAt        *time.Time `gorm:"column:paid_at;comment:支付时间" json:"paid_at"`
+	RefundNo      string     `gorm:"column:refund_no;type:varchar(64);not null;default:'';index;comment:订单失效时原路退回的退款单号" json:"refund_no"` // 退款单号
+	CreatedAt     time.Time  `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                    // 创建时间
+	UpdatedAt     time.Time  `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                    // 更新时间
+}
+
+func (m *PaymentTransaction) TableName() string {
+	return "payment_transaction"
+}
+
+// PrepayRequest 发起支付请求
+type PrepayRequest struct {
+	OrderID uint64 `json:"order_id" binding:"required"` // 订单ID
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"app/pkg/money"
+)
+
+// PayoutAccount 用户绑定的提现收款账户，户名和账号使用 AES 加密存储
+type PayoutAccount struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID      string    `gorm:"column:user_id;type:varchar(30);not null;index;comment:用户ID" json:"user_id"`                   // 用户ID
+	Type        uint8     `gorm:"column:type;type:tinyint;not null;comment:收款方式(1:银行卡;2:微信零钱)" json:"type"`                     // 收款方式
+	BankName    string    `gorm:"column:bank_name;type:varchar(100);not null;default:'';comment:银行名称" json:"bank_name"`         // 银行名称
+	AccountName string    `gorm:"column:account_name;type:varchar(255);not null;default:'';comment:户名（加密）" json:"account_name"` // 户名
+	AccountNo   string    `gorm:"column:account_no;type:varchar(255);not null;comment:账号或openid（加密）" json:"account_no"`         // 账号
+	IsDefault   uint8     `gorm:"column:is_default;type:tinyint;not null;default:0;comment:是否默认（0:否；1:是）" json:"is_default"`    // 是否默认
+	CreatedAt   time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                             // 创建时间
+	UpdatedAt   time.Time `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                             // 更新时间
+}
+
+func (m *PayoutAccount) TableName() string {
+	return "payout_account"
+}
+
+// BindPayoutAccountRequest 绑定收款账户请求，微信零钱无需填写账号，使用用户的 openid
+type BindPayoutAccountRequest struct {
+	Type        uint8  `json:"type" binding:"required,oneof=1 2"`      // 收款方式
+	BankName    string `json:"bank_name" binding:"max=100"`            // 银行名称
+	AccountName string `json:"account_name" binding:"required,max=50"` // 户名，微信零钱为实名姓名
+	AccountNo   string `json:"account_no" binding:"max=64"`            // 银行卡号
+	IsDefault   bool   `json:"is_default"`                             // 是否设为默认
+}
+
+// DeletePayoutAccountRequest 删除收款账户请求
+type DeletePayoutAccountRequest struct {
+	ID uint64 `json:"id" binding:"required"` // 收款账户ID
+}
+
+// PayoutAccountItem 收款账户，户名和账号脱敏展示
+type PayoutAccountItem struct {
+	ID          uint64 `json:"id"`           // 收款账户ID
+	Type        uint8  `json:"type"`         // 收款方式
+	BankName    string `json:"bank_name"`    // 银行名称
+	AccountName string `json:"account_name"` // 户名
+	AccountNo   string `json:"account_no"`   // 账号
+	IsDefault   uint8  `json:"is_default"`   // 是否默认
+}
+
+// WithdrawConfig 提现手续费与限额，配置在 sys_params 的 withdraw_config 中，限额为0表示不限
+type WithdrawConfig struct {
+	FeeRate      float64     `json:"fee_rate"`      // 手续费率
+	MinFee       money.Money `json:"min_fee"`       // 最低手续费
+	MinAmount    money.Money `json:"min_amount"`    // 单笔最低提现金额
+	MaxAmount    money.Money `json:"max_amount"`    // 单笔最高提现金额
+	DailyLimit   money.Money `json:"daily_limit"`   // 每日提现限额
+	MonthlyLimit money.Money `json:"monthly_limit"` // 每月提现限额
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+)
+
+// PointRules 积分获取与过期规则，配置在 sys_params 的 point_rules 中，积分数为0表示不奖励
+type PointRules struct {
+	OrderRatios         []PointOrderRatio `json:"order_ratios"`          // 各会员等级的订单积分比例
+	DefaultOrderRatio   float64           `json:"default_order_ratio"`   // 未配置等级时的订单积分比例
+	ReviewPhotoPoints   int               `json:"review_photo_points"`   // 晒图评价奖励积分
+	CheckInPoints       int               `json:"check_in_points"`       // 每日签到奖励积分
+	FirstPurchasePoints int               `json:"first_purchase_points"` // 首次购买奖励积分
+	ExpireDays          int               `json:"expire_days"`           // 积分有效天数，为0表示不过期
+	RemindDays          int               `json:"remind_days"`           // 过期前多少天提醒
+}
+
+// PointOrderRatio 会员等级对应的订单积分比例，即每消费1元获得的积分
+type PointOrderRatio struct {
+	Role  int     `json:"role"`  // 会员等级
+	Ratio float64 `json:"ratio"` // 每元积分
+}
+
+// PointLot 积分批次，每次获得积分生成一个批次，使用和过期时按过期时间先进先出扣减
+type PointLot struct {
+	ID           uint64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID       string     `gorm:"column:user_id;type:varchar(30);not null;index:idx_user_remaining;comment:用户ID" json:"user_id"` // 用户ID
+	BusinessType int8       `gorm:"column:business_type;type:tinyint;not null;default:0;comment:获得积分的业务类型" json:"business_type"`   // 业务类型
+	RelationID   int        `gorm:"column:relation_id;not null;default:0;comment:关联ID" json:"relation_id"`                         // 关联ID
+	Points       int64      `gorm:"column:points;type:bigint;not null;comment:获得积分" json:"points"`                                 // 获得积分
+	Remaining    int64      `gorm:"column:remaining;type:bigint;not null;index:idx_user_remaining;comment:剩余积分" json:"remaining"`  // 剩余积分
+	ExpireAt     *time.Time `gorm:"column:expire_at;index;comment:过期时间，为空表示不过期" json:"expire_at"`                                  // 过期时间
+	Reminded     uint8      `gorm:"column:reminded;type:tinyint;not null;default:0;comment:是否已提醒即将过期（0:否；1:是）" json:"reminded"`    // 是否已提醒
+	CreatedAt    time.Time  `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                              // 创建时间
+	UpdatedAt    time.Time  `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                              // 更新时间
+}
+
+func (m *PointLot) TableName() string {
+	return "point_lot"
+}
+
+// PointCheckIn 每日签到记录
+type PointCheckIn struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID      string    `gorm:"column:user_id;type:varchar(30);not null;uniqueIndex:uk_user_date;comment:用户ID" json:"user_id"`             // 用户ID
+	CheckInDate string    `gorm:"column:check_in_date;type:varchar(10);not null;uniqueIndex:uk_user_date;comment:签到日期" json:"check_in_date"` // 签到日期
+	Points      int       `gorm:"column:points;type:int;not null;default:0;comment:奖励积分" json:"points"`                                      // 奖励积分
+	CreatedAt   time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                          // 创建时间
+}
+
+func (m *PointCheckIn) TableName() string {
+	return "point_check_in"
+}
+
+// CheckInResponse 签到结果
+type CheckInResponse struct {
+	CheckInDate string `json:"check_in_date"` // 签到日期
+	Points      int    `json:"points"`        // 奖励积分
+}
+
+// PointLotItem 积分批次
+type PointLotItem struct {
+	ID           uint64     `json:"id"`            // 批次ID
+	BusinessType int8       `json:"business_type"` // 获得积分的业务类型
+	Points       int64      `json:"points"`        // 获得积分
+	Remaining    int64      `json:"remaining"`     // 剩余积分
+	ExpireAt     *time.Time `json:"expire_at"`     // 过期时间
+	CreatedAt    time.Time  `json:"created_at"`    // 获得时间
+}
+
+// PointLotListResponse 积分批次列表
+type PointLotListResponse struct {
+	Points         int64          `json:"points"`          // 可用积分
+	ExpiringPoints int64          `json:"expiring_points"` // 即将过期的积分
+	List           []PointLotItem `json:"list"`            // 积分批次
+}
+
+// PointExpiringNotice 积分即将过期推送消息
+type PointExpiringNotice struct {
+	Module   string    `json:"module"`    // 消息模块
+	UserID   string    `json:"-"`         // 用户ID
+	LotIDs   []uint64  `json:"-"`         // 提醒的积分批次，推送成功后标记已提醒
+	Points   int64     `json:"points"`    // 即将过期的积分
+	ExpireAt time.Time `json:"expire_at"` // 最早过期时间
+	Message  string    `json:"message"`   // 提示内容
+}
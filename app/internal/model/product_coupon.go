@@ -4,18 +4,64 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"app/pkg/money"
 )
 
+// ProductCoupon 优惠券模板，用户领取后生成 UserCoupon
 type ProductCoupon struct {
 	gorm.Model
-	ProductID         uint64     `gorm:"column:product_id;type:bigint unsigned;not null;comment:商品ID" json:"product_id"`                 // 商品ID
-	CouponName        string     `gorm:"column:coupon_name;type:varchar(100);not null;comment:优惠券名称" json:"coupon_name"`                 // 优惠券名称
-	CouponPrice       float64    `gorm:"column:coupon_price;type:varchar(50);not null;comment:优惠券价格" json:"coupon_price"`                // 优惠券价格
-	AvailableMinPrice float64    `gorm:"column:available_min_price;type:varchar(50);not null;comment:可用最低价格" json:"available_min_price"` // 可用最低价格
-	Deadline          *time.Time `gorm:"column:deadline;type:datetime;comment:截止时间" json:"deadline"`                                     // 截止时间
-	Status            uint8      `gorm:"column:status;type:tinyint unsigned;not null;default:0;comment:状态(0:未领取;1:已领取)" json:"status"`   // 状态
+	ProductID         uint64      `gorm:"column:product_id;type:bigint unsigned;not null;comment:商品ID" json:"product_id"`                                  // 商品ID
+	CouponName        string      `gorm:"column:coupon_name;type:varchar(100);not null;comment:优惠券名称" json:"coupon_name"`                                  // 优惠券名称
+	Kind              uint8       `gorm:"column:kind;type:tinyint unsigned;not null;default:1;comment:类型(1:立减;2:折扣;3:满减;4:免运费)" json:"kind"`               // 类型
+	CouponPrice       money.Money `gorm:"column:coupon_price;type:bigint;not null;comment:优惠券价格（分）" json:"coupon_price"`                                   // 优惠券价格，立减和满减的抵扣金额
+	DiscountRate      float64     `gorm:"column:discount_rate;type:decimal(4,2);not null;default:0;comment:折扣率，如0.85为85折" json:"discount_rate"`            // 折扣率
+	MaxDiscount       money.Money `gorm:"column:max_discount;type:bigint;not null;default:0;comment:最高抵扣金额（分），为0表示不限" json:"max_discount"`                 // 最高抵扣金额
+	AvailableMinPrice money.Money `gorm:"column:available_min_price;type:bigint;not null;comment:可用最低价格（分）" json:"available_min_price"`                    // 可用最低价格
+	ScopeType         uint8       `gorm:"column:scope_type;type:tinyint unsigned;not null;default:1;comment:适用范围(1:全部商品;2:指定分类;3:指定商品)" json:"scope_type"` // 适用范围
+	ScopeIDs          string      `gorm:"column:scope_ids;type:varchar(1024);not null;default:'';comment:适用的分类或商品ID,多个用逗号分隔" json:"scope_ids"`             // 适用的分类或商品ID
+	StartAt           *time.Time  `gorm:"column:start_at;type:datetime;comment:生效时间，为空表示立即生效" json:"start_at"`                                             // 生效时间
+	Deadline          *time.Time  `gorm:"column:deadline;type:datetime;comment:截止时间" json:"deadline"`                                                      // 截止时间
+	ValidDays         int         `gorm:"column:valid_days;type:int;not null;default:0;comment:领取后有效天数，为0表示以截止时间为准" json:"valid_days"`                     // 领取后有效天数
+	StackMember       uint8       `gorm:"column:stack_member;type:tinyint unsigned;not null;default:1;comment:是否可与会员折扣叠加(0:否;1:是)" json:"stack_member"`    // 是否可与会员折扣叠加
+	Exclusive         uint8       `gorm:"column:exclusive;type:tinyint unsigned;not null;default:0;comment:是否独占，不可与其他优惠券同单使用(0:否;1:是)" json:"exclusive"`   // 是否独占
+	TotalQuantity     int         `gorm:"column:total_quantity;type:int;not null;default:0;comment:发放总量，为0表示不限" json:"total_quantity"`
+	ClaimedQuantity   int         `gorm:"column:claimed_quantity;type:int;not null;default:0;comment:已领取数量" json:"claimed_quantity"`
+	PerUserLimit      int         `gorm:"column:per_user_limit;type:int;not null;default:1;comment:每人限领数量，为0表示不限" json:"per_user_limit"`
+	ClaimStartAt      *time.Time  `gorm:"column:claim_start_at;type:datetime;comment:领取开始时间" json:"claim_start_at"`
+	ClaimEndAt        *time.Time  `gorm:"column:claim_end_at;type:datetime;comment:领取结束时间" json:"claim_end_at"`
+	Status            uint8       `gorm:"column:status;type:tinyint unsigned;not null;default:0;comment:状态(0:未领取;1:已领取)" json:"status"` // 状态
 }
 
 func (m *ProductCoupon) TableName() string {
 	return "product_coupon"
 }
+
+// UserCouponClaim 用户领取优惠券的次数，用于按条件更新控制每人限领
+type UserCouponClaim struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID     string    `gorm:"column:user_id;type:varchar(30);not null;uniqueIndex:uk_user_coupon;comment:用户ID" json:"user_id"` // 用户ID
+	CouponID   uint64    `gorm:"column:coupon_id;not null;uniqueIndex:uk_user_coupon;comment:优惠券模板ID" json:"coupon_id"`           // 优惠券模板ID
+	ClaimCount int       `gorm:"column:claim_count;type:int;not null;default:0;comment:已领取次数" json:"claim_count"`                 // 已领取次数
+	CreatedAt  time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                // 创建时间
+	UpdatedAt  time.Time `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                // 更新时间
+}
+
+func (m *UserCouponClaim) TableName() string {
+	return "user_coupon_claim"
+}
+
+// CouponClaimLog 优惠券领取记录，成功和失败的领取都会记录
+type CouponClaimLog struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CouponID     uint64    `gorm:"column:coupon_id;not null;index;comment:优惠券模板ID" json:"coupon_id"`                   // 优惠券模板ID
+	UserID       string    `gorm:"column:user_id;type:varchar(30);not null;index;comment:用户ID" json:"user_id"`         // 用户ID
+	UserCouponID uint64    `gorm:"column:user_coupon_id;not null;default:0;comment:领取到的用户优惠券ID" json:"user_coupon_id"` // 领取到的用户优惠券ID
+	Result       uint8     `gorm:"column:result;type:tinyint;not null;comment:领取结果(1:成功;2:失败)" json:"result"`          // 领取结果
+	Reason       string    `gorm:"column:reason;type:varchar(255);not null;default:'';comment:失败原因" json:"reason"`     // 失败原因
+	CreatedAt    time.Time `gorm:"column:created_at;comment:领取时间" json:"created_at"`                                   // 领取时间
+}
+
+func (m *CouponClaimLog) TableName() string {
+	return "coupon_claim_log"
+}
@@ -1,5 +1,7 @@
 package model
 
+import "app/pkg/money"
+
 // ProductListResponse represents the top level response structure
 type ProductListResponse struct {
 	Category1ID   int                   `json:"category1_id"`
@@ -24,6 +26,7 @@ type ProductListItemDTO struct {
 	ProductUnit          string               `json:"product_unit"`
 	ProductSpec          string               `json:"product_spec"`
 	ProductSales         int                  `json:"product_sales"`
+	ProductStock         int                  `json:"product_stock"` // 可售库存，-1表示不限
 	ProductSpecification string               `json:"product_specification"`
 	HeaderImg            string               `json:"header_img"`
 	ProductImages        []string             `json:"product_images"`
@@ -45,13 +48,20 @@ type ProductListItemDTO struct {
 
 // ProductCouponDTO represents a coupon for a product
 type ProductCouponDTO struct {
-	CouponID          uint    `json:"coupon_id"`
-	CouponName        string  `json:"coupon_name"`
-	CouponPrice       float64 `json:"coupon_price"`
-	AvailableMinPrice float64 `json:"available_min_price"`
-	Deadline          string  `json:"deadline"`
-	IsReceived        int     `json:"is_received"`
-	ProductID         uint    `json:"product_id"`
+	UserCouponID      uint64      `json:"user_coupon_id"`
+	CouponID          uint        `json:"coupon_id"`
+	CouponName        string      `json:"coupon_name"`
+	CouponPrice       money.Money `json:"coupon_price"`
+	Kind              uint8       `json:"kind"`
+	DiscountRate      float64     `json:"discount_rate"`
+	MaxDiscount       money.Money `json:"max_discount"`
+	AvailableMinPrice money.Money `json:"available_min_price"`
+	ScopeType         uint8       `json:"scope_type"`
+	TotalQuantity     int         `json:"total_quantity"`
+	ClaimedQuantity   int         `json:"claimed_quantity"`
+	Deadline          string      `json:"deadline"`
+	IsReceived        int         `json:"is_received"`
+	ProductID         uint        `json:"product_id"`
 }
 
 // ProductEvaluateDTO represents an evaluation for a product
@@ -67,3 +77,9 @@ type ProductEvaluateDTO struct {
 	EvaluateNums    uint     `json:"evaluate_nums"`
 	PraiseNums      uint     `json:"praise_nums"`
 }
+
+// UpdateProductStockRequest 运营设置商品库存请求
+type UpdateProductStockRequest struct {
+	ProductID uint64 `json:"product_id" binding:"required"`   // 商品ID
+	Stock     *int   `json:"stock" binding:"required,min=-1"` // 可售库存，-1表示不限
+}
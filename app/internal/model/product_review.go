@@ -2,6 +2,8 @@ package model
 
 import (
 	"time"
+
+	"app/pkg/money"
 )
 
 // ProductReview 商品评价模型
@@ -55,46 +57,46 @@ type ReviewListResponse struct {
 
 // ReviewListDTO 评价列表数据传输对象
 type ReviewListDTO struct {
-	ID            uint64   `json:"id"`             // 评价ID
-	OrderID       uint64   `json:"order_id"`       // 订单ID
-	OrderNo       string   `json:"order_no"`       // 订单编号
-	OrderItemID   uint64   `json:"order_item_id"`  // 订单项ID
-	ProductID     uint64   `json:"product_id"`     // 商品ID
-	StoreName     string   `json:"store_name"`     // 店铺名称
-	StoreIcon     string   `json:"store_icon"`     // 店铺图标
-	StatusText    string   `json:"status_text"`    // 订单状态文本
-	ProductName   string   `json:"product_name"`   // 商品名称
-	ProductImage  string   `json:"product_image"`  // 商品图片
-	Price         float64  `json:"price"`          // 商品价格
-	Quantity      int      `json:"quantity"`       // 购买数量
-	ReviewStatus  uint8    `json:"review_status"`  // 评价状态
-	OrderTime     string   `json:"order_time"`     // 订单时间
-	ReviewContent string   `json:"review_content"` // 评价内容
-	ReviewImages  []string `json:"review_images"`  // 评价图片
-	ViewNums      uint     `json:"view_nums"`      // 查看人数
-	EvaluateNums  uint     `json:"evaluate_nums"`  // 评价人数
-	PraiseNums    uint     `json:"praise_nums"`    // 点赞人数
-	IsAnonymous   bool     `json:"is_anonymous"`   // 是否匿名
-	UserName      string   `json:"user_name"`      // 用户名
-	UserAvatar    string   `json:"user_avatar"`    // 用户头像
+	ID            uint64      `json:"id"`             // 评价ID
+	OrderID       uint64      `json:"order_id"`       // 订单ID
+	OrderNo       string      `json:"order_no"`       // 订单编号
+	OrderItemID   uint64      `json:"order_item_id"`  // 订单项ID
+	ProductID     uint64      `json:"product_id"`     // 商品ID
+	StoreName     string      `json:"store_name"`     // 店铺名称
+	StoreIcon     string      `json:"store_icon"`     // 店铺图标
+	StatusText    string      `json:"status_text"`    // 订单状态文本
+	ProductName   string      `json:"product_name"`   // 商品名称
+	ProductImage  string      `json:"product_image"`  // 商品图片
+	Price         money.Money `json:"price"`          // 商品价格
+	Quantity      int         `json:"quantity"`       // 购买数量
+	ReviewStatus  uint8       `json:"review_status"`  // 评价状态
+	OrderTime     string      `json:"order_time"`     // 订单时间
+	ReviewContent string      `json:"review_content"` // 评价内容
+	ReviewImages  []string    `json:"review_images"`  // 评价图片
+	ViewNums      uint        `json:"view_nums"`      // 查看人数
+	EvaluateNums  uint        `json:"evaluate_nums"`  // 评价人数
+	PraiseNums    uint        `json:"praise_nums"`    // 点赞人数
+	IsAnonymous   bool        `json:"is_anonymous"`   // 是否匿名
+	UserName      string      `json:"user_name"`      // 用户名
+	UserAvatar    string      `json:"user_avatar"`    // 用户头像
 }
 
 type ReviewDoneListDTO struct {
-	ID            uint64   `json:"id"`            // 评价ID
-	Status        uint8    `json:"status"`        // 评价状态
-	CreatedAt     string   `json:"createdAt"`     // 创建时间
-	ProductID     uint64   `json:"productId"`     // 商品IDs
-	ProductName   string   `json:"productName"`   // 商品名称
-	ProductImage  string   `json:"productImage"`  // 商品图片
-	Price         float64  `json:"price"`         // 商品价格
-	Quantity      int      `json:"quantity"`      // 购买数量
-	ReviewContent string   `json:"reviewContent"` // 评价内容
-	ReviewImages  []string `json:"reviewImages"`  // 评价图片
-	ReviewStatus  uint8    `json:"reviewStatus"`  // 评价状态
-	StatusText    string   `json:"statusText"`    // 评价状态文本
-	StoreName     string   `json:"storeName"`     // 店铺名称
-	StoreIcon     string   `json:"storeIcon"`     // 店铺图标
-	IsAnonymous   bool     `json:"isAnonymous"`   // 是否匿名
+	ID            uint64      `json:"id"`            // 评价ID
+	Status        uint8       `json:"status"`        // 评价状态
+	CreatedAt     string      `json:"createdAt"`     // 创建时间
+	ProductID     uint64      `json:"productId"`     // 商品IDs
+	ProductName   string      `json:"productName"`   // 商品名称
+	ProductImage  string      `json:"productImage"`  // 商品图片
+	Price         money.Money `json:"price"`         // 商品价格
+	Quantity      int         `json:"quantity"`      // 购买数量
+	ReviewContent string      `json:"reviewContent"` // 评价内容
+	ReviewImages  []string    `json:"reviewImages"`  // 评价图片
+	ReviewStatus  uint8       `json:"reviewStatus"`  // 评价状态
+	StatusText    string      `json:"statusText"`    // 评价状态文本
+	StoreName     string      `json:"storeName"`     // 店铺名称
+	StoreIcon     string      `json:"storeIcon"`     // 店铺图标
+	IsAnonymous   bool        `json:"isAnonymous"`   // 是否匿名
 }
 
 // ReviewTabType 评价标签类型
@@ -113,19 +115,19 @@ type UserReviewListRequest struct {
 
 // PendingReviewItem 待评价项目
 type PendingReviewItem struct {
-	OrderID         uint64     `json:"order_id"`          // 订单ID
-	OrderNo         string     `json:"order_no"`          // 订单编号
-	ProductID       uint64     `json:"product_id"`        // 商品ID
-	ProductName     string     `json:"product_name"`      // 商品名称
-	ProductImage    string     `json:"product_image"`     // 商品图片
-	ProductSpec     string     `json:"product_spec"`      // 商品规格
-	Price           float64    `json:"price"`             // 商品价格
-	Quantity        int        `json:"quantity"`          // 购买数量
-	OrderCreatedAt  *time.Time `json:"order_created_at"`  // 订单创建时间
-	OrderStatus     int        `json:"order_status"`      // 订单状态
-	OrderStatusText string     `json:"order_status_text"` // 订单状态文本
-	StoreName       string     `json:"store_name"`        // 店铺名称
-	StoreIcon       string     `json:"store_icon"`        // 店铺图标
+	OrderID         uint64      `json:"order_id"`          // 订单ID
+	OrderNo         string      `json:"order_no"`          // 订单编号
+	ProductID       uint64      `json:"product_id"`        // 商品ID
+	ProductName     string      `json:"product_name"`      // 商品名称
+	ProductImage    string      `json:"product_image"`     // 商品图片
+	ProductSpec     string      `json:"product_spec"`      // 商品规格
+	Price           money.Money `json:"price"`             // 商品价格
+	Quantity        int         `json:"quantity"`          // 购买数量
+	OrderCreatedAt  *time.Time  `json:"order_created_at"`  // 订单创建时间
+	OrderStatus     int         `json:"order_status"`      // 订单状态
+	OrderStatusText string      `json:"order_status_text"` // 订单状态文本
+	StoreName       string      `json:"store_name"`        // 店铺名称
+	StoreIcon       string      `json:"store_icon"`        // 店铺图标
 }
 
 // PendingReviewListResponse 待评价列表响应
@@ -153,7 +155,7 @@ type ReviewDetailResponse struct {
 	ProductName     string                `json:"product_name"`     // 商品名称
 	ProductImage    string                `json:"product_image"`    // 商品图片
 	ProductSpec     string                `json:"product_spec"`     // 商品规格
-	Price           float64               `json:"price"`            // 商品价格
+	Price           money.Money           `json:"price"`            // 商品价格
 	Quantity        int                   `json:"quantity"`         // 购买数量
 	StoreName       string                `json:"store_name"`       // 店铺名称
 	StoreIcon       string                `json:"store_icon"`       // 店铺图标
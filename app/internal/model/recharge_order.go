@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"app/pkg/money"
+	"app/pkg/payment"
+)
+
+// RechargeOrder 充值单，支付回调验签通过后才给用户入账
+type RechargeOrder struct {
+	ID          uint64      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	RechargeNo  string      `gorm:"column:recharge_no;type:varchar(64);not null;uniqueIndex;comment:充值单号" json:"recharge_no"`    // 充值单号
+	UserID      string      `gorm:"column:user_id;type:varchar(30);not null;index;comment:用户ID" json:"user_id"`                  // 用户ID
+	Amount      money.Money `gorm:"column:amount;type:bigint;not null;default:0;comment:充值金额（分）" json:"amount"`                  // 充值金额
+	BonusAmount money.Money `gorm:"column:bonus_amount;type:bigint;not null;default:0;comment:赠送金额（分）" json:"bonus_amount"`      // 赠送金额
+	Status      uint8       `gorm:"column:status;type:tinyint;not null;default:0;comment:充值状态(0:待支付;1:已到账;2:已关闭)" json:"status"` // 充值状态
+	PaidAt      *time.Time  `gorm:"column:paid_at;comment:到账时间" json:"paid_at"`                                                  // 到账时间
+	CreatedAt   time.Time   `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                            // 创建时间
+	UpdatedAt   time.Time   `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                            // 更新时间
+}
+
+func (m *RechargeOrder) TableName() string {
+	return "recharge_order"
+}
+
+// RechargeBonusTier 充值赠送档位，配置在 sys_params 的 recharge_bonus_tiers 中，如充100送10
+type RechargeBonusTier struct {
+	Amount money.Money `json:"amount"` // 充值满额
+	Bonus  money.Money `json:"bonus"`  // 赠送金额
+}
+
+// RechargeResponse 充值下单结果
+type RechargeResponse struct {
+	RechargeNo  string                  `json:"recharge_no"`  // 充值单号
+	Amount      money.Money             `json:"amount"`       // 充值金额
+	BonusAmount money.Money             `json:"bonus_amount"` // 赠送金额
+	Payment     *payment.PrepayResponse `json:"payment"`      // 调起支付的参数
+}
+
+// RechargeQueryRequest 充值记录查询请求
+type RechargeQueryRequest struct {
+	Status   *uint8 `form:"status"`                     // 充值状态
+	Page     int    `form:"page" json:"page"`           // 页码
+	PageSize int    `form:"page_size" json:"page_size"` // 每页条数
+}
+
+// RechargeListItem 充值记录
+type RechargeListItem struct {
+	ID          uint64      `json:"id"`           // 充值单ID
+	RechargeNo  string      `json:"recharge_no"`  // 充值单号
+	Amount      money.Money `json:"amount"`       // 充值金额
+	BonusAmount money.Money `json:"bonus_amount"` // 赠送金额
+	Status      uint8       `json:"status"`       // 充值状态
+	StatusText  string      `json:"status_text"`  // 充值状态文本
+	PaidAt      *time.Time  `json:"paid_at"`      // 到账时间
+	CreatedAt   time.Time   `json:"created_at"`   // 创建时间
+}
+
+// RechargeListResponse 充值记录列表
+type RechargeListResponse struct {
+	Total int64              `json:"total"` // 总数
+	List  []RechargeListItem `json:"list"`  // 充值记录
+	Page  int                `json:"page"`  // 页码
+	Size  int                `json:"size"`  // 每页条数
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+)
+
+// ReconciliationRun 资产对账任务，管理后台按任务查看对账结果
+type ReconciliationRun struct {
+	ID               uint64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	Status           uint8      `gorm:"column:status;type:tinyint;not null;default:1;comment:状态(1:进行中;2:已完成;3:失败)" json:"status"`        // 状态
+	AutoCorrect      uint8      `gorm:"column:auto_correct;type:tinyint;not null;default:0;comment:是否自动调整（0:否；1:是）" json:"auto_correct"` // 是否自动调整
+	UserCount        int        `gorm:"column:user_count;type:int;not null;default:0;comment:对账用户数" json:"user_count"`                   // 对账用户数
+	DiscrepancyCount int        `gorm:"column:discrepancy_count;type:int;not null;default:0;comment:差异数" json:"discrepancy_count"`       // 差异数
+	CorrectedCount   int        `gorm:"column:corrected_count;type:int;not null;default:0;comment:已调整差异数" json:"corrected_count"`        // 已调整差异数
+	Error            string     `gorm:"column:error;type:varchar(500);not null;default:'';comment:失败原因" json:"error"`                    // 失败原因
+	StartedAt        time.Time  `gorm:"column:started_at;comment:开始时间" json:"started_at"`                                                // 开始时间
+	FinishedAt       *time.Time `gorm:"column:finished_at;comment:结束时间" json:"finished_at"`                                              // 结束时间
+}
+
+func (m *ReconciliationRun) TableName() string {
+	return "asset_reconciliation_run"
+}
+
+// ReconciliationDiscrepancy 对账差异，余额单位为分
+type ReconciliationDiscrepancy struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	RunID       uint64    `gorm:"column:run_id;type:bigint unsigned;not null;index;comment:对账任务ID" json:"run_id"`                                                    // 对账任务ID
+	UserID      string    `gorm:"column:user_id;type:varchar(30);not null;index;comment:用户ID" json:"user_id"`                                                        // 用户ID
+	Type        uint8     `gorm:"column:type;type:tinyint;not null;comment:差异类型(1:资产与流水不符;2:订单扣款与流水不符;3:提现扣款与流水不符;4:缓存与资产不符;5:冻结余额与冻结记录不符;6:积分与积分批次不符)" json:"type"` // 差异类型
+	AssetType   int8      `gorm:"column:asset_type;type:tinyint;not null;comment:资产类型(1:积分 2:余额 4:冻结余额)" json:"asset_type"`                                          // 资产类型
+	ActualNum   int64     `gorm:"column:actual_num;type:bigint;not null;default:0;comment:实际数量" json:"actual_num"`                                                   // 实际数量
+	ExpectedNum int64     `gorm:"column:expected_num;type:bigint;not null;default:0;comment:应有数量" json:"expected_num"`                                               // 应有数量
+	Diff        int64     `gorm:"column:diff;type:bigint;not null;default:0;comment:差额（实际-应有）" json:"diff"`                                                          // 差额
+	Corrected   uint8     `gorm:"column:corrected;type:tinyint;not null;default:0;comment:是否已调整（0:否；1:是）" json:"corrected"`                                          // 是否已调整
+	CreatedAt   time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                                                  // 创建时间
+}
+
+func (m *ReconciliationDiscrepancy) TableName() string {
+	return "asset_reconciliation_discrepancy"
+}
+
+// ReconciliationRunListRequest 查询对账任务请求
+type ReconciliationRunListRequest struct {
+	Page     int `form:"page" json:"page"`           // 页码
+	PageSize int `form:"page_size" json:"page_size"` // 每页条数
+}
+
+// ReconciliationRunListResponse 对账任务列表
+type ReconciliationRunListResponse struct {
+	Total int64               `json:"total"` // 总数
+	List  []ReconciliationRun `json:"list"`  // 对账任务
+	Page  int                 `json:"page"`  // 页码
+	Size  int                 `json:"size"`  // 每页条数
+}
+
+// ReconciliationDiscrepancyRequest 查询对账差异请求
+type ReconciliationDiscrepancyRequest struct {
+	RunID uint64 `form:"run_id" binding:"required"` // 对账任务ID
+}
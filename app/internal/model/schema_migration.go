@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// SchemaMigration 已执行的数据迁移，用于保证一次性的数据转换只执行一次
+type SchemaMigration struct {
+	Version   string    `gorm:"primaryKey;column:version;type:varchar(128);comment:迁移版本" json:"version"` // 迁移版本
+	CreatedAt time.Time `gorm:"column:created_at;comment:执行时间" json:"created_at"`                        // 执行时间
+}
+
+func (m *SchemaMigration) TableName() string {
+	return "schema_migration"
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+)
+
+// Store 店铺
+type Store struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	Name        string    `gorm:"column:name;type:varchar(255);not null;comment:店铺名称" json:"name"`                          // 店铺名称
+	Logo        string    `gorm:"column:logo;type:varchar(255);not null;default:'';comment:店铺LOGO" json:"logo"`             // 店铺LOGO
+	Description string    `gorm:"column:description;type:varchar(500);not null;default:'';comment:店铺简介" json:"description"` // 店铺简介
+	Status      uint8     `gorm:"column:status;type:tinyint;not null;default:1;comment:店铺状态(1:营业中;2:已关闭)" json:"status"`    // 店铺状态
+	CreatedAt   time.Time `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                         // 创建时间
+	UpdatedAt   time.Time `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                         // 更新时间
+}
+
+func (m *Store) TableName() string {
+	return "store"
+}
+
+// StorePageRequest 店铺主页请求
+type StorePageRequest struct {
+	Page     int `form:"page" json:"page"`           // 页码
+	PageSize int `form:"page_size" json:"page_size"` // 每页条数
+}
+
+// StoreRatingDTO 店铺评分，取店铺所有商品已通过评价的平均分
+type StoreRatingDTO struct {
+	Rating          float64 `json:"rating"`           // 商品评分
+	FreshnessRating float64 `json:"freshness_rating"` // 新鲜程度评分
+	PackagingRating float64 `json:"packaging_rating"` // 包装评分
+	DeliveryRating  float64 `json:"delivery_rating"`  // 配送评分
+	ServiceRating   float64 `json:"service_rating"`   // 服务态度评分
+	ReviewCount     int64   `json:"review_count"`     // 评价数量
+}
+
+// StorePageResponse 店铺主页响应
+type StorePageResponse struct {
+	StoreID     uint64               `json:"store_id"`    // 店铺ID
+	StoreName   string               `json:"store_name"`  // 店铺名称
+	StoreLogo   string               `json:"store_logo"`  // 店铺LOGO
+	Description string               `json:"description"` // 店铺简介
+	Rating      StoreRatingDTO       `json:"rating"`      // 店铺评分
+	Total       int64                `json:"total"`       // 商品总数
+	Products    []ProductListItemDTO `json:"products"`    // 商品列表
+	Page        int                  `json:"page"`        // 页码
+	PageSize    int                  `json:"page_size"`   // 每页条数
+}
@@ -2,17 +2,22 @@ package model
 
 import (
 	"time"
+
+	"app/pkg/money"
 )
 
 // UserAsset represents the user_asset table
 type UserAsset struct {
-	ID          int       `gorm:"primarykey" json:"id"`
-	UserID      string    `gorm:"column:user_id;type:varchar(30);not null;uniqueIndex;comment:用户ID" json:"user_id"` // 用户ID
-	Points      int       `gorm:"column:points;type:int;not null;default:0;comment:用户积分" json:"points"`             // 用户积分
-	Balance     float64   `gorm:"column:balance;type:int;not null;default:0;comment:用户余额" json:"balance"`           // 用户余额
-	Consumption float64   `gorm:"column:consumption;type:int;not null;default:0;comment:用户消费" json:"consumption"`   // 用户消费
-	CreatedAt   time.Time `gorm:"column:created_at;not null;comment:创建时间" json:"created_at"`                        // 创建时间
-	UpdatedAt   time.Time `gorm:"column:updated_at;not null;comment:更新时间" json:"updated_at"`                        // 更新时间
+	ID            int         `gorm:"primarykey" json:"id"`
+	UserID        string      `gorm:"column:user_id;type:varchar(30);not null;uniqueIndex;comment:用户ID" json:"user_id"`                 // 用户ID
+	Points        int         `gorm:"column:points;type:int;not null;default:0;comment:用户积分" json:"points"`                             // 用户积分
+	Balance       money.Money `gorm:"column:balance;type:bigint;not null;default:0;comment:用户余额（分）" json:"balance"`                     // 用户余额
+	FrozenBalance money.Money `gorm:"column:frozen_balance;type:bigint;not null;default:0;comment:冻结余额（分），提现处理中" json:"frozen_balance"` // 冻结余额
+	Consumption   money.Money `gorm:"column:consumption;type:bigint;not null;default:0;comment:用户消费（分）" json:"consumption"`             // 用户消费
+	Eggs          int64       `gorm:"column:eggs;type:bigint;not null;default:0;comment:鸟蛋数量" json:"eggs"`                              // 鸟蛋数量
+	FrozenEggs    int64       `gorm:"column:frozen_eggs;type:bigint;not null;default:0;comment:冻结鸟蛋数量，出售挂单中" json:"frozen_eggs"`        // 冻结鸟蛋数量
+	CreatedAt     time.Time   `gorm:"column:created_at;not null;comment:创建时间" json:"created_at"`                                        // 创建时间
+	UpdatedAt     time.Time   `gorm:"column:updated_at;not null;comment:更新时间" json:"updated_at"`                                        // 更新时间
 }
 
 // TableName specifies the table name for the UserAsset model
@@ -22,20 +27,45 @@ func (m *UserAsset) TableName() string {
 
 // UserAssetResponse represents the response for user asset queries
 type UserAssetResponse struct {
-	UserID      string  `json:"user_id"`      // 用户ID
-	Points      int     `json:"points"`       // 用户积分
-	Balance     float64 `json:"balance"`      // 用户余额
-	CouponCount int     `json:"coupon_count"` // 用户优惠券数量
-	Nickname    string  `json:"nickname"`     // 用户昵称
-	Avatar      string  `json:"avatar"`       // 用户头像
+	UserID           string      `json:"user_id"`           // 用户ID
+	Points           int         `json:"points"`            // 用户积分
+	Balance          money.Money `json:"balance"`           // 用户余额，即可用余额
+	AvailableBalance money.Money `json:"available_balance"` // 可用余额
+	FrozenBalance    money.Money `json:"frozen_balance"`    // 冻结余额
+	Consumption      money.Money `json:"consumption"`       // 累计消费
+	Eggs             int64       `json:"eggs"`              // 可用鸟蛋数量
+	FrozenEggs       int64       `json:"frozen_eggs"`       // 出售挂单中冻结的鸟蛋数量
+	CouponCount      int         `json:"coupon_count"`      // 用户优惠券数量
+	Nickname         string      `json:"nickname"`          // 用户昵称
+	Avatar           string      `json:"avatar"`            // 用户头像
 }
 
 // RechargeRequest 表示充值余额的请求
 type RechargeRequest struct {
-	Amount float64 `json:"amount" binding:"required,gt=0"` // 充值金额，必须大于0
+	Amount money.Money `json:"amount" binding:"required,gt=0"` // 充值金额，必须大于0
 }
 
 // WithdrawRequest 表示从用户余额提取的请求
 type WithdrawRequest struct {
-	Amount float64 `json:"amount" binding:"required,gt=0"` // 提取金额，必须大于0
+	Amount money.Money `json:"amount" binding:"required,gt=0"` // 提取金额，必须大于0
+}
+
+// UserAssetHold 资金冻结记录，一笔业务单据对应一条，冻结的金额从可用余额转入冻结余额
+// 业务完成时扣除（capture），业务取消时解冻退回可用余额（release），分批完成的业务可多次部分扣除
+type UserAssetHold struct {
+	ID             uint64      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID         string      `gorm:"column:user_id;type:varchar(30);not null;index;comment:用户ID" json:"user_id"`                                    // 用户ID
+	BusinessType   int8        `gorm:"column:business_type;type:tinyint;not null;uniqueIndex:uk_business_relation;comment:业务类型" json:"business_type"` // 业务类型
+	RelationID     int         `gorm:"column:relation_id;not null;uniqueIndex:uk_business_relation;comment:关联ID" json:"relation_id"`                  // 关联ID
+	ActionType     int8        `gorm:"column:action_type;type:tinyint;not null;comment:动作类型" json:"action_type"`                                      // 动作类型
+	RelationTitle  string      `gorm:"column:relation_title;type:varchar(255);not null;default:'';comment:关联标题" json:"relation_title"`                // 关联标题
+	Amount         money.Money `gorm:"column:amount;type:bigint;not null;comment:冻结金额（分）" json:"amount"`                                              // 冻结金额
+	CapturedAmount money.Money `gorm:"column:captured_amount;type:bigint;not null;default:0;comment:已扣除金额（分）" json:"captured_amount"`                 // 已扣除金额
+	Status         uint8       `gorm:"column:status;type:tinyint;not null;default:1;comment:状态(1:冻结中;2:已解冻;3:已扣除)" json:"status"`                     // 状态
+	CreatedAt      time.Time   `gorm:"column:created_at;comment:创建时间" json:"created_at"`                                                              // 创建时间
+	UpdatedAt      time.Time   `gorm:"column:updated_at;comment:更新时间" json:"updated_at"`                                                              // 更新时间
+}
+
+func (m *UserAssetHold) TableName() string {
+	return "user_asset_hold"
 }
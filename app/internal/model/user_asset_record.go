@@ -1,7 +1,11 @@
 package model
 
 import (
+	"strconv"
 	"time"
+
+	"app/internal/common"
+	"app/pkg/money"
 )
 
 type UserAssetRecord struct {
@@ -10,18 +14,41 @@ type UserAssetRecord struct {
 	BusinessType  int8      `gorm:"column:business_type;type:tinyint(4);default:0;NOT NULL;comment:业务类型" json:"business_type"`
 	ActionType    int8      `gorm:"column:action_type;type:tinyint(4);default:0;NOT NULL;comment:消费类型(1:使用 2:奖励 3:购买 5:售出所得 6:收益 7:使用)" json:"action_type"` // 消费类型(1:使用 2:奖励 3:购买 5:售出所得 6:收益 7:使用)
 	AssetType     int8      `gorm:"column:asset_type;type:tinyint(4);default:0;NOT NULL;comment:资产类型(1:金币 2:命数 3:扑克 4:转盘次数 5:广告加倍)" json:"asset_type"`      // 资产类型(1:金币 2:命数 3:扑克 4:转盘次数 5:广告加倍)
-	ActionNum     float32   `gorm:"column:action_num;type:double;default:0;NOT NULL;comment:使用数量" json:"action_num"`                                        // 使用数量
-	LeftNum       float32   `gorm:"column:left_num;type:double;default:0;NOT NULL;comment:剩余数量" json:"left_num"`                                            // 剩余数量
+	ActionNum     int64     `gorm:"column:action_num;type:bigint;default:0;NOT NULL;comment:使用数量（余额单位为分）" json:"action_num"`                                // 使用数量（余额单位为分）
+	LeftNum       int64     `gorm:"column:left_num;type:bigint;default:0;NOT NULL;comment:剩余数量（余额单位为分）" json:"left_num"`                                    // 剩余数量（余额单位为分）
 	CreatedAt     time.Time `gorm:"column:created_at;type:datetime;NOT NULL;comment:创建时间" json:"created_at"`                                                // 创建时间
 	UpdatedAt     time.Time `gorm:"column:updated_at;type:datetime;NOT NULL;comment:更新时间" json:"updated_at"`                                                // 更新时间
 	RelationId    int       `gorm:"column:relation_id;comment:关联ID" json:"relation_id"`                                                                     // 关联ID                                                      // 关联图片
 	RelationTitle string    `gorm:"column:relation_title;comment:关联标题" json:"relation_title"`                                                               // 关联标题
+	JournalNo     string    `gorm:"column:journal_no;type:varchar(64);not null;default:'';index;comment:记账凭证号" json:"journal_no"`                           // 记账凭证号，同一凭证的分录金额合计为0
+	AccountType   int8      `gorm:"column:account_type;type:tinyint(4);not null;default:1;comment:账户类型(1:用户账户 2:系统账户)" json:"account_type"`                 // 账户类型(1:用户账户 2:系统账户)
 }
 
 func (m *UserAssetRecord) TableName() string {
 	return "user_asset_record"
 }
 
+// LedgerEntry 一笔资产变动，Amount 为正表示增加、为负表示减少，余额单位为分
+type LedgerEntry struct {
+	UserID        string     // 用户ID
+	BusinessType  int8       // 业务类型
+	ActionType    int8       // 动作类型
+	AssetType     int8       // 资产类型
+	Amount        int64      // 变动数量
+	RelationID    int        // 关联ID
+	RelationTitle string     // 关联标题
+	ExpireAt      *time.Time // 积分过期时间，只对增加的积分有效，为空表示不过期
+	PointLotID    uint64     // 扣减积分时指定的积分批次，为0时按先进先出扣减
+}
+
+// DisplayNum 资产数量的展示值，余额由分转为元
+func (m *UserAssetRecord) DisplayNum(num int64) string {
+	if m.AssetType == common.ASSET_TYPE_BALANCE || m.AssetType == common.ASSET_TYPE_FROZEN {
+		return money.FromFen(num).String()
+	}
+	return strconv.FormatInt(num, 10)
+}
+
 // BalanceRecordQueryRequest 表示查询余额记录的请求
 type BalanceRecordQueryRequest struct {
 	Page     int `form:"page" json:"page"`           // 页码，从1开始
@@ -38,14 +65,14 @@ type BalanceRecordResponse struct {
 
 // UserAssetRecordDTO 用户资产记录DTO
 type UserAssetRecordDTO struct {
-	ID           uint64  `json:"id"`            // 记录ID
-	UserId       string  `json:"user_id"`       // 用户ID
-	Title        string  `json:"title"`         // 标题
-	BusinessType int8    `json:"business_type"` // 业务类型
-	ActionType   int8    `json:"action_type"`   // 操作类型：1-增加，2-减少
-	AssetType    int8    `json:"asset_type"`    // 资产类型：1-积分，2-余额
-	ActionNum    float32 `json:"action_num"`    // 操作数量
-	LeftNum      float32 `json:"left_num"`      // 剩余数量
-	Remark       string  `json:"remark"`        // 备注
-	CreatedAt    string  `json:"created_at"`    // 创建时间
+	ID           uint64 `json:"id"`            // 记录ID
+	UserId       string `json:"user_id"`       // 用户ID
+	Title        string `json:"title"`         // 标题
+	BusinessType int8   `json:"business_type"` // 业务类型
+	ActionType   int8   `json:"action_type"`   // 操作类型：1-增加，2-减少
+	AssetType    int8   `json:"asset_type"`    // 资产类型：1-积分，2-余额
+	ActionNum    string `json:"action_num"`    // 操作数量，余额以元展示
+	LeftNum      string `json:"left_num"`      // 剩余数量，余额以元展示
+	Remark       string `json:"remark"`        // 备注
+	CreatedAt    string `json:"created_at"`    // 创建时间
 }
@@ -7,6 +7,7 @@ type CartResponse []CartStoreDTO
 type CartStoreDTO struct {
 	StoreID   uint64           `json:"store_id"`
 	StoreName string           `json:"store_name"`
+	StoreLogo string           `json:"store_logo"`
 	StoreURL  string           `json:"store_url"`
 	List      []CartProductDTO `json:"list"`
 }
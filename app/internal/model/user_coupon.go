@@ -1,21 +1,29 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"app/pkg/money"
+)
 
 // UserCoupon represents the user_coupon table
 type UserCoupon struct {
-	ID                uint64    `gorm:"primarykey" json:"id"`
-	UserID            string    `gorm:"column:user_id;type:varchar(255);not null;comment:用户ID" json:"user_id"`                     // 用户ID
-	ProductID         uint64    `gorm:"column:product_id;type:bigint unsigned;not null;comment:商品ID" json:"product_id"`            // 商品ID
-	CouponID          uint64    `gorm:"column:coupon_id;type:bigint unsigned;not null;comment:优惠券ID" json:"coupon_id"`             // 优惠券ID (注意:列名在DB中有拼写错误)
-	Type              uint8     `gorm:"column:type;type:tinyint;not null;default:0;comment:类型（1:优惠券；2:兑换券）" json:"type"`           // 类型
-	Status            uint8     `gorm:"column:status;type:tinyint;not null;default:0;comment:状态（0:未处理:1:已使用;2:已过期）" json:"status"` // 状态
-	CreatedAt         time.Time `gorm:"column:created_at" json:"created_at"`
-	UpdatedAt         time.Time `gorm:"column:updated_at" json:"updated_at"`
-	CouponName        string    `gorm:"column:coupon_name;type:varchar(100);not null;comment:优惠券名称" json:"coupon_name"`                 // 优惠券名称
-	CouponPrice       float64   `gorm:"column:coupon_price;type:varchar(50);not null;comment:优惠券价格" json:"coupon_price"`                // 优惠券价格
-	AvailableMinPrice float64   `gorm:"column:available_min_price;type:varchar(50);not null;comment:可用最低价格" json:"available_min_price"` // 可用最低价格
-	Deadline          time.Time `gorm:"column:deadline;comment:截止时间" json:"deadline"`                                                   // 截止时间
+	ID                uint64      `gorm:"primarykey" json:"id"`
+	UserID            string      `gorm:"column:user_id;type:varchar(255);not null;comment:用户ID" json:"user_id"`                     // 用户ID
+	ProductID         uint64      `gorm:"column:product_id;type:bigint unsigned;not null;comment:商品ID" json:"product_id"`            // 商品ID
+	CouponID          uint64      `gorm:"column:coupon_id;type:bigint unsigned;not null;comment:优惠券ID" json:"coupon_id"`             // 优惠券ID (注意:列名在DB中有拼写错误)
+	Type              uint8       `gorm:"column:type;type:tinyint;not null;default:0;comment:类型（1:优惠券；2:兑换券）" json:"type"`           // 类型
+	Status            uint8       `gorm:"column:status;type:tinyint;not null;default:0;comment:状态（0:未处理:1:已使用;2:已过期）" json:"status"` // 状态
+	CreatedAt         time.Time   `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt         time.Time   `gorm:"column:updated_at" json:"updated_at"`
+	CouponName        string      `gorm:"column:coupon_name;type:varchar(100);not null;comment:优惠券名称" json:"coupon_name"`               // 优惠券名称
+	CouponPrice       money.Money `gorm:"column:coupon_price;type:bigint;not null;comment:优惠券价格（分）" json:"coupon_price"`                // 优惠券价格
+	AvailableMinPrice money.Money `gorm:"column:available_min_price;type:bigint;not null;comment:可用最低价格（分）" json:"available_min_price"` // 可用最低价格
+	Deadline          time.Time   `gorm:"column:deadline;comment:截止时间" json:"deadline"`                                                 // 截止时间
+	StartAt           *time.Time  `gorm:"column:start_at;comment:生效时间" json:"start_at"`                                                 // 生效时间
+	OrderItemID       uint64      `gorm:"column:order_item_id;not null;default:0;index;comment:使用的订单项ID" json:"order_item_id"`          // 使用的订单项ID
+	ExpireReminded    uint8       `gorm:"column:expire_reminded;type:tinyint;not null;default:0;comment:是否已提醒即将过期（0:否；1:是）" json:"expire_reminded"`
+	OrderNo           string      `gorm:"column:order_no;type:varchar(64);not null;default:'';comment:使用的订单号" json:"order_no"` // 使用的订单号
 }
 
 // TableName specifies the table name for UserCoupon
@@ -34,6 +42,57 @@ type UserCouponResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UserCouponQueryRequest 用户优惠券列表查询请求
+type UserCouponQueryRequest struct {
+	Status *uint8 `form:"status" binding:"omitempty,oneof=0 1 2"` // 状态（0:未使用;1:已使用;2:已过期），为空查询全部
+}
+
+// CouponExpiringNotice 优惠券即将过期推送消息
+type CouponExpiringNotice struct {
+	Module   string    `json:"module"`    // 消息模块
+	UserID   string    `json:"-"`         // 用户ID
+	IDs      []uint64  `json:"-"`         // 提醒的用户优惠券，推送成功后标记已提醒
+	Count    int       `json:"count"`     // 即将过期的优惠券数量
+	ExpireAt time.Time `json:"expire_at"` // 最早过期时间
+	Message  string    `json:"message"`   // 提示内容
+}
+
+// CouponRecommendRequest 结算页推荐优惠券请求，收货地区用于计算运费
+type CouponRecommendRequest struct {
+	CartIDs  []uint64 `json:"cart_ids" binding:"required,min=1"` // 购物车ID
+	Province string   `json:"province"`                          // 省
+	City     string   `json:"city"`                              // 城市
+	District string   `json:"district"`                          // 区/县
+}
+
+// CouponRecommendResponse 推荐的优惠券组合
+type CouponRecommendResponse struct {
+	TotalDiscount money.Money        `json:"total_discount"` // 使用推荐组合比不用优惠券多优惠的金额
+	Allocations   []CouponAllocation `json:"allocations"`    // 每个商品的优惠券分配，与请求的购物车一一对应
+	Unusable      []UnusableCoupon   `json:"unusable"`       // 未选用的优惠券及原因
+}
+
+// CouponAllocation 商品使用的优惠券及使用后的价格，未使用优惠券时优惠券ID为0
+type CouponAllocation struct {
+	CartID         uint64      `json:"cart_id"`         // 购物车ID
+	ProductID      uint64      `json:"product_id"`      // 商品ID
+	ProductName    string      `json:"product_name"`    // 商品名称
+	CouponID       uint64      `json:"coupon_id"`       // 优惠券ID，下单时传入
+	UserCouponID   uint64      `json:"user_coupon_id"`  // 用户优惠券记录ID
+	CouponName     string      `json:"coupon_name"`     // 优惠券名称
+	CouponPrice    money.Money `json:"coupon_price"`    // 优惠券抵扣
+	MemberDiscount money.Money `json:"member_discount"` // 会员折扣
+	TotalFee       money.Money `json:"total_fee"`       // 应付金额
+}
+
+// UnusableCoupon 未选用的优惠券
+type UnusableCoupon struct {
+	UserCouponID uint64 `json:"user_coupon_id"` // 用户优惠券记录ID
+	CouponID     uint64 `json:"coupon_id"`      // 优惠券ID
+	CouponName   string `json:"coupon_name"`    // 优惠券名称
+	Reason       string `json:"reason"`         // 未选用原因
+}
+
 // ClaimCouponRequest 领取优惠券的请求
 type ClaimCouponRequest struct {
 	CouponID uint64 `json:"coupon_id" binding:"required"` // 优惠券ID
@@ -41,13 +100,18 @@ type ClaimCouponRequest struct {
 
 // CouponDetailResponse 优惠券详情响应
 type CouponDetailResponse struct {
-	ID                uint64    `json:"id"`
-	CouponName        string    `json:"coupon_name"`         // 优惠券名称
-	CouponPrice       float64   `json:"coupon_price"`        // 优惠券金额
-	AvailableMinPrice float64   `json:"available_min_price"` // 可用最低金额
-	Type              uint8     `json:"type"`                // 类型（1:优惠券；2:兑换券）
-	Status            uint8     `json:"status"`              // 状态
-	ProductID         uint64    `json:"product_id"`          // 商品ID
-	Deadline          time.Time `json:"deadline"`            // 截止时间
-	CreatedAt         time.Time `json:"created_at"`          // 创建时间
+	ID                uint64      `json:"id"`
+	CouponName        string      `json:"coupon_name"`         // 优惠券名称
+	CouponPrice       money.Money `json:"coupon_price"`        // 优惠券金额
+	AvailableMinPrice money.Money `json:"available_min_price"` // 可用最低金额
+	Type              uint8       `json:"type"`                // 类型（1:优惠券；2:兑换券）
+	Kind              uint8       `json:"kind"`                // 优惠类型（1:立减;2:折扣;3:满减;4:免运费）
+	DiscountRate      float64     `json:"discount_rate"`       // 折扣率
+	MaxDiscount       money.Money `json:"max_discount"`        // 最高抵扣金额
+	Status            uint8       `json:"status"`              // 状态
+	ProductID         uint64      `json:"product_id"`          // 商品ID
+	StartAt           *time.Time  `json:"start_at"`            // 生效时间
+	Deadline          time.Time   `json:"deadline"`            // 截止时间
+	OrderNo           string      `json:"order_no"`            // 使用的订单号
+	CreatedAt         time.Time   `json:"created_at"`          // 创建时间
 }
@@ -16,22 +16,17 @@ type UserEarning struct {
 	ID          uint `gorm:"primarykey"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
-	UserID      string      `gorm:"column:user_id;type:varchar(255);not null;comment:用户ID" json:"user_id"`
+	UserID      string      `gorm:"column:user_id;type:varchar(255);not null;uniqueIndex:uk_user_ostrich_date,priority:1;comment:用户ID" json:"user_id"`
+	OstrichID   *uint64     `gorm:"column:ostrich_id;type:bigint unsigned;uniqueIndex:uk_user_ostrich_date,priority:2;comment:产出收益的鸵鸟ID，每只鸵鸟每天只生成一次" json:"ostrich_id"`
 	EarningType EarningType `gorm:"column:earning_type;type:tinyint;not null;comment:收益类型:1-鸟蛋,2-商品鸟,3-种鸟" json:"earning_type"`
 	TypeName    string      `gorm:"column:type_name;type:varchar(255);comment:类型名称" json:"type_name"`
 	Image       string      `gorm:"column:image;type:varchar(255);comment:图片" json:"image"`
 	Amount      int64       `gorm:"column:amount;type:bigint;not null;comment:收益数量" json:"amount"`
-	EarningDate string      `gorm:"column:earning_date;type:varchar(10);comment:收益日期" json:"earning_date"`
+	EarningDate string      `gorm:"column:earning_date;type:varchar(10);uniqueIndex:uk_user_ostrich_date,priority:3;comment:收益日期" json:"earning_date"`
 	Year        int         `gorm:"column:year;type:int;comment:所属年份" json:"year"`
 	Month       int         `gorm:"column:month;type:int;comment:所属月份" json:"month"`
 }
 
-// AddEarningRequest 添加收益请求
-type AddEarningRequest struct {
-	EarningType EarningType `json:"earning_type" binding:"required"` // 收益类型
-	Amount      int64       `json:"amount" binding:"required"`       // 收益数量
-}
-
 // QueryEarningRequest 查询收益请求
 type QueryEarningRequest struct {
 	Date     string `form:"date"`                                // 日期，格式：YYYY-MM-DD
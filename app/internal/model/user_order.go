@@ -2,6 +2,8 @@ package model
 
 import (
 	"time"
+
+	"app/pkg/money"
 )
 
 // UserOrder represents an order
@@ -18,7 +20,7 @@ type UserOrder struct {
 	District      string          `gorm:"column:district;type:varchar(255);not null;comment:区/县" json:"district"`                      // 区/县
 	Detail        string          `gorm:"column:detail;type:varchar(255);not null;comment:详细地址" json:"detail"`                         // 详细地址
 	IsDefault     uint8           `gorm:"column:is_default;type:tinyint;not null;default:0;comment:是否默认地址（0:否；1:是）" json:"is_default"` // 是否默认地址
-	TotalFee      float64         `gorm:"column:total_fee;type:int(13);not null;comment:总金额" json:"total_fee"`                         // 总金额
+	TotalFee      money.Money     `gorm:"column:total_fee;type:bigint;not null;comment:总金额（分）" json:"total_fee"`                       // 总金额
 	CreatedAt     *time.Time      `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt     *time.Time      `gorm:"column:updated_at" json:"updated_at"`
 	OrderItems    []UserOrderItem `gorm:"foreignKey:OrderID;references:ID" json:"order_items"`
@@ -30,19 +32,19 @@ func (m *UserOrder) TableName() string {
 }
 
 type OrderItemRequest struct {
-	CartID         uint    `json:"cart_id"`
-	ProductID      uint64  `json:"product_id" binding:"required"`
-	Quantity       int     `json:"quantity" binding:"required"`
-	ProductName    string  `json:"product_name" binding:"required"`
-	Image          string  `json:"image" binding:"required"`
-	CurrentPrice   float64 `json:"current_price" binding:"required"`
-	CourierFeeMin  float64 `json:"courier_fee_min"`
-	MemberDiscount float64 `json:"member_discount"`
-	StoreID        uint64  `json:"store_id"`
-	StoreName      string  `json:"store_name"`
-	Note           string  `json:"note"`
-	CouponID       uint64  `json:"coupon_id"`
-	CouponPrice    float64 `json:"coupon_price"`
+	CartID         uint        `json:"cart_id"`
+	ProductID      uint64      `json:"product_id" binding:"required"`
+	Quantity       int         `json:"quantity" binding:"required"`
+	ProductName    string      `json:"product_name" binding:"required"`
+	Image          string      `json:"image" binding:"required"`
+	CurrentPrice   money.Money `json:"current_price" binding:"required"`
+	CourierFeeMin  money.Money `json:"courier_fee_min"`
+	MemberDiscount money.Money `json:"member_discount"`
+	StoreID        uint64      `json:"store_id"`
+	StoreName      string      `json:"store_name"`
+	Note           string      `json:"note"`
+	CouponID       uint64      `json:"coupon_id"`
+	CouponPrice    money.Money `json:"coupon_price"`
 }
 
 type Address struct {
@@ -61,7 +63,6 @@ type CreateOrderRequest struct {
 	Items         []OrderItemRequest `json:"items" binding:"required,dive"`
 	Address       Address            `json:"address" binding:"required"`
 	PaymentMethod int                `json:"payment_method" binding:"required"`
-	Status        int                `json:"status"`
 }
 
 // OrderQueryRequest 订单查询请求
@@ -85,7 +86,7 @@ type OrderListDTO struct {
 	ID            uint64          `json:"id"`
 	OrderNo       string          `json:"order_no"`
 	UserID        string          `json:"user_id"`
-	TotalFee      float64         `json:"total_fee"`
+	TotalFee      money.Money     `json:"total_fee"`
 	Status        int             `json:"status"`
 	StatusText    string          `json:"status_text"`
 	PaymentMethod int             `json:"payment_method"`
@@ -99,12 +100,12 @@ type OrderListDTO struct {
 
 // OrderProductDTO 订单商品数据传输对象
 type OrderProductDTO struct {
-	ItemID      uint64  `json:"item_id"`
-	ProductID   uint64  `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
-	Image       string  `json:"image"`
+	ItemID      uint64      `json:"item_id"`
+	ProductID   uint64      `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	Quantity    int         `json:"quantity"`
+	Price       money.Money `json:"price"`
+	Image       string      `json:"image"`
 }
 
 // OrderProductsRequest 订单商品查询请求
@@ -126,22 +127,22 @@ type UpdateOrderStatusRequest struct {
 // OrderDetailResponse 订单详情响应
 type OrderDetailResponse struct {
 	// 订单基本信息
-	OrderID       uint64  `json:"order_id"`        // 订单ID
-	OrderNo       string  `json:"order_no"`        // 订单号
-	UserID        string  `json:"user_id"`         // 用户ID
-	OrderAmount   float64 `json:"order_amount"`    // 订单金额
-	Status        int     `json:"status"`          // 订单状态
-	StatusText    string  `json:"status_text"`     // 订单状态文本
-	PayMethod     int     `json:"pay_method"`      // 支付方式
-	PayMethodText string  `json:"pay_method_text"` // 支付方式文本
-	PayTime       string  `json:"pay_time"`        // 支付时间
-	ShippedAt     string  `json:"shipped_at"`      // 发货时间
-	CompletedAt   string  `json:"completed_at"`    // 完成时间
-	CreatedAt     string  `json:"created_at"`      // 创建时间
-	UpdatedAt     string  `json:"updated_at"`      // 更新时间
-	StoreID       uint64  `json:"store_id"`        // 店铺ID
-	StoreName     string  `json:"store_name"`      // 店铺名称
-	StoreLogo     string  `json:"store_logo"`      // 店铺Logo
+	OrderID       uint64      `json:"order_id"`        // 订单ID
+	OrderNo       string      `json:"order_no"`        // 订单号
+	UserID        string      `json:"user_id"`         // 用户ID
+	OrderAmount   money.Money `json:"order_amount"`    // 订单金额
+	Status        int         `json:"status"`          // 订单状态
+	StatusText    string      `json:"status_text"`     // 订单状态文本
+	PayMethod     int         `json:"pay_method"`      // 支付方式
+	PayMethodText string      `json:"pay_method_text"` // 支付方式文本
+	PayTime       string      `json:"pay_time"`        // 支付时间
+	ShippedAt     string      `json:"shipped_at"`      // 发货时间
+	CompletedAt   string      `json:"completed_at"`    // 完成时间
+	CreatedAt     string      `json:"created_at"`      // 创建时间
+	UpdatedAt     string      `json:"updated_at"`      // 更新时间
+	StoreID       uint64      `json:"store_id"`        // 店铺ID
+	StoreName     string      `json:"store_name"`      // 店铺名称
+	StoreLogo     string      `json:"store_logo"`      // 店铺Logo
 
 	// 地址信息
 	Address *AddressInfo `json:"address"` // 收货地址信息
@@ -150,11 +151,14 @@ type OrderDetailResponse struct {
 	Products []ProductListItemDTO `json:"products"` // 商品列表
 
 	// 订单汇总信息
-	TotalPrice     float64 `json:"total_price"`     // 商品总价
-	ShippingFee    float64 `json:"shipping_fee"`    // 运费
-	CouponDiscount float64 `json:"coupon_discount"` // 优惠券折扣
-	MemberDiscount float64 `json:"member_discount"` // 会员折扣
-	ActualAmount   float64 `json:"actual_amount"`   // 实际支付金额
+	TotalPrice     money.Money `json:"total_price"`     // 商品总价
+	ShippingFee    money.Money `json:"shipping_fee"`    // 运费
+	CouponDiscount money.Money `json:"coupon_discount"` // 优惠券折扣
+	MemberDiscount money.Money `json:"member_discount"` // 会员折扣
+	ActualAmount   money.Money `json:"actual_amount"`   // 实际支付金额
+
+	// 订单状态时间线
+	Timeline []OrderTimelineDTO `json:"timeline"` // 状态变更记录
 }
 
 // AddressInfo 地址信息
@@ -166,3 +170,12 @@ type AddressInfo struct {
 	District      string `json:"district"`       // 区县
 	DetailAddress string `json:"detail_address"` // 详细地址
 }
+
+// OrderNotice 订单状态推送消息
+type OrderNotice struct {
+	Module      string `json:"module"`        // 消息模块
+	OrderItemID uint64 `json:"order_item_id"` // 订单项ID
+	OrderNo     string `json:"order_no"`      // 订单号
+	Status      int    `json:"status"`        // 订单状态
+	Message     string `json:"message"`       // 提示内容
+}
@@ -2,107 +2,713 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
 
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	v1 "app/api/v1"
+	"app/internal/cache"
+	"app/internal/common"
 	"app/internal/model"
+	"app/pkg/money"
 )
 
+// FreeMarketMineRepository 自由市场鸟蛋交易
+// 出售挂单冻结卖方的鸟蛋，求购挂单冻结买方的余额，挂单按价格优先、时间优先撮合，成交后通过记账入口结算鸟蛋和余额
+// 挂单只能由本人修改，价格限制在每日价格范围内，改价次数按用户每日限制并留有记录
+// 每笔成交计入1小时、1天、1周的K线，前一日的日K线收盘价作为今日参考价
 type FreeMarketMineRepository interface {
 	GetUserEggsSummary(ctx context.Context, userID string) (*model.FreeMarketMineResponse, error)
-	UpdateEggPrice(ctx context.Context, price float64, id int) error
+	UpdateEggPrice(ctx context.Context, userID string, id uint64, price money.Money) (*model.MarketOrderItem, error)
+	GetMarketConfig(ctx context.Context) (*model.MarketConfig, error)
+	PublishOrder(ctx context.Context, userID string, req model.PublishMarketOrderRequest) (*model.MarketOrderItem, error)
+	CancelOrder(ctx context.Context, userID string, id uint64) error
+	GetOrderBook(ctx context.Context, userID string, req model.MarketOrderBookRequest) (*model.MarketOrderBookResponse, error)
+	GetCandles(ctx context.Context, req model.MarketCandleRequest) (*model.MarketCandleResponse, error)
 }
 
 func NewFreeMarketMineRepository(
 	repository *Repository,
+	ledgerRepository LedgerRepository,
+	settingsRepository SettingsRepository,
 ) FreeMarketMineRepository {
 	return &freeMarketMineRepository{
-		Repository: repository,
+		Repository:         repository,
+		ledgerRepository:   ledgerRepository,
+		settingsRepository: settingsRepository,
 	}
 }
 
 type freeMarketMineRepository struct {
 	*Repository
+	ledgerRepository   LedgerRepository
+	settingsRepository SettingsRepository
 }
 
-func (r *freeMarketMineRepository) GetUserEggsSummary(ctx context.Context, userID string) (*model.FreeMarketMineResponse, error) {
-	// Get all records for this user
-	var records []*model.FreeMarketMine
-	if err := r.DB(ctx).Where("user_id = ?", userID).Find(&records).Error; err != nil {
-		return nil, err
-	}
+const (
+	// marketLockKey 撮合锁，挂单簿的所有修改串行执行
+	marketLockKey = "match"
+	// marketLockTTL 撮合锁的过期时间
+	marketLockTTL = 10 * time.Second
+	// marketLockWait 等待撮合锁的最长时间
+	marketLockWait = 3 * time.Second
+)
 
+// GetUserEggsSummary 获取用户已成交和出售中的鸟蛋，已成交包括迁移前的历史出售记录
+func (r *freeMarketMineRepository) GetUserEggsSummary(ctx context.Context, userID string) (*model.FreeMarketMineResponse, error) {
 	response := &model.FreeMarketMineResponse{
 		SelledList: []model.SelledEggDTO{},
 		NoSellList: []model.NoSellEggDTO{},
-		Others:     []model.NoSellEggDTO{},
 		Summary:    model.SummaryDTO{},
 	}
 
-	// Process records into appropriate lists
+	// 历史出售记录，未出售的记录已迁移为出售挂单
+	var records []*model.FreeMarketMine
+	if err := r.DB(ctx).Where("user_id = ? AND status = 1", userID).Find(&records).Error; err != nil {
+		return nil, err
+	}
 	for _, record := range records {
 		// Skip records with null values
-		if record.EggPrice == 0 || record.EggNum == nil || record.Status == nil || record.Date == nil {
+		if record.EggPrice == 0 || record.EggNum == nil || record.Date == nil {
 			continue
 		}
+		response.SelledList = append(response.SelledList, model.SelledEggDTO{
+			EggPrice: record.EggPrice,
+			EggNum:   int(*record.EggNum),
+			Date:     record.Date.Format("2006-01-02"),
+			Total:    record.EggPrice * float64(*record.EggNum),
+		})
+	}
+
+	var trades []model.MarketTrade
+	if err := r.DB(ctx).Where("seller_id = ?", userID).Order("id DESC").Find(&trades).Error; err != nil {
+		r.logger.Error("查询成交记录失败", zap.Error(err))
+		return nil, err
+	}
+	for _, trade := range trades {
+		response.SelledList = append(response.SelledList, model.SelledEggDTO{
+			EggPrice: trade.Price.Yuan(),
+			EggNum:   int(trade.Quantity),
+			Date:     trade.CreatedAt.Format("2006-01-02"),
+			Total:    trade.Amount.Yuan(),
+		})
+	}
+
+	var orders []model.MarketOrder
+	if err := r.DB(ctx).Where("user_id = ? AND publish_type = ? AND status = ?", userID,
+		common.PUBLISH_TYPE_SELL, common.PUBLISH_PRODUCT_STATUS_NORMAL).Order("id DESC").Find(&orders).Error; err != nil {
+		r.logger.Error("查询挂单失败", zap.Error(err))
+		return nil, err
+	}
+	for _, order := range orders {
+		response.NoSellList = append(response.NoSellList, model.NoSellEggDTO{
+			Id:        int(order.ID),
+			EggPrice:  order.Price.Yuan(),
+			EggNum:    int(order.Remaining()),
+			Date:      order.CreatedAt.Format("2006-01-02"),
+			PriceLogs: []model.PriceLogDTO{},
+		})
+	}
 
-		dateStr := record.Date.Format("2006-01-02")
+	if err := r.fillPriceLogs(ctx, response.NoSellList); err != nil {
+		return nil, err
+	}
 
-		// Check if sold or unsold
-		if *record.Status == 1 {
-			// Sold eggs
-			total := record.EggPrice * float64(*record.EggNum)
+	marketConfig, err := r.GetMarketConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reference, err := r.referencePrice(ctx, marketConfig)
+	if err != nil {
+		return nil, err
+	}
+	if response.PriceBand, err = r.priceBand(ctx, marketConfig); err != nil {
+		return nil, err
+	}
 
-			selledItem := model.SelledEggDTO{
-				EggPrice: record.EggPrice,
-				EggNum:   int(*record.EggNum),
-				Date:     dateStr,
-				Total:    total,
+	response.Summary = model.SummaryDTO{
+		TotalSelled:    len(response.SelledList),
+		TotalNoSell:    len(response.NoSellList),
+		ReferencePrice: reference.Yuan(),
+	}
+	return response, nil
+}
+
+// UpdateEggPrice 修改自己出售挂单的价格并记录改价记录，改价后按新价格重新撮合
+// 求购挂单冻结的余额与价格相关，需撤单后重新挂单
+func (r *freeMarketMineRepository) UpdateEggPrice(ctx context.Context, userID string, id uint64, price money.Money) (*model.MarketOrderItem, error) {
+	marketConfig, err := r.GetMarketConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	band, err := r.priceBand(ctx, marketConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPriceBand(band, price); err != nil {
+		return nil, err
+	}
+	var order model.MarketOrder
+	err = r.withMarketLock(ctx, func() error {
+		return r.Transaction(ctx, func(ctx context.Context) error {
+			tx := r.DB(ctx)
+			if err := r.lockOrder(tx, userID, id, &order); err != nil {
+				return err
 			}
-			response.SelledList = append(response.SelledList, selledItem)
-		} else {
-			// Unsold eggs
-			noSellItem := model.NoSellEggDTO{
-				Id:       int(record.ID),
-				EggPrice: record.EggPrice,
-				EggNum:   int(*record.EggNum),
-				Date:     dateStr,
+			if order.PublishType != common.PUBLISH_TYPE_SELL {
+				return errors.New("求购挂单不支持改价，请撤单后重新挂单")
+			}
+			if order.Price == price {
+				return nil
+			}
+			now := time.Now()
+			if marketConfig.PriceEditLimit > 0 {
+				var edits int64
+				if err := tx.Model(&model.MarketPriceLog{}).
+					Where("user_id = ? AND created_at >= ?", userID, startOfDay(now)).Count(&edits).Error; err != nil {
+					r.logger.Error("查询改价次数失败", zap.Error(err))
+					return err
+				}
+				if edits >= int64(marketConfig.PriceEditLimit) {
+					return fmt.Errorf("%w，每日最多改价%d次", v1.ErrMarketEditLimit, marketConfig.PriceEditLimit)
+				}
+			}
+			if err := tx.Model(&model.MarketOrder{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+				"price":      price,
+				"updated_at": now,
+			}).Error; err != nil {
+				r.logger.Error("更新挂单价格失败", zap.Error(err))
+				return err
 			}
-			response.NoSellList = append(response.NoSellList, noSellItem)
+			if err := tx.Create(&model.MarketPriceLog{
+				OrderID:   order.ID,
+				UserID:    userID,
+				OldPrice:  order.Price,
+				NewPrice:  price,
+				CreatedAt: now,
+			}).Error; err != nil {
+				r.logger.Error("创建改价记录失败", zap.Error(err))
+				return err
+			}
+			order.Price = price
+			return r.match(ctx, tx, &order, marketConfig)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	item := toMarketOrderItem(order, userID)
+	return &item, nil
+}
+
+// GetMarketConfig 获取自由市场配置，未配置时按1%收取手续费，价格每日上下浮动10%，每人每日改价10次
+func (r *freeMarketMineRepository) GetMarketConfig(ctx context.Context) (*model.MarketConfig, error) {
+	marketConfig := &model.MarketConfig{FeeRate: 0.01, PriceBandRate: 0.1, PriceEditLimit: 10}
+	value, err := r.settingsRepository.GetSettings(ctx, common.SETTINGS_MARKET_CONFIG)
+	if err != nil || value == "" {
+		return marketConfig, nil
+	}
+	if err := json.Unmarshal([]byte(value), marketConfig); err != nil {
+		r.logger.Error("解析自由市场配置失败", zap.Error(err))
+		return nil, err
+	}
+	return marketConfig, nil
+}
+
+// PublishOrder 发布挂单，冻结出售的鸟蛋或求购所需的余额后立即与已有挂单撮合，未成交部分留在挂单簿
+func (r *freeMarketMineRepository) PublishOrder(ctx context.Context, userID string, req model.PublishMarketOrderRequest) (*model.MarketOrderItem, error) {
+	if req.Price.Fen() > math.MaxInt64/req.Quantity {
+		return nil, errors.New("挂单金额过大")
+	}
+	marketConfig, err := r.GetMarketConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	band, err := r.priceBand(ctx, marketConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPriceBand(band, req.Price); err != nil {
+		return nil, err
+	}
+	order := model.MarketOrder{
+		UserID:      userID,
+		PublishType: req.PublishType,
+		Price:       req.Price,
+		Quantity:    req.Quantity,
+		Status:      common.PUBLISH_PRODUCT_STATUS_NORMAL,
+	}
+	err = r.withMarketLock(ctx, func() error {
+		return r.Transaction(ctx, func(ctx context.Context) error {
+			tx := r.DB(ctx)
+			if err := tx.Create(&order).Error; err != nil {
+				r.logger.Error("创建挂单失败", zap.Error(err))
+				return err
+			}
+			if err := r.freeze(ctx, tx, &order); err != nil {
+				return err
+			}
+			return r.match(ctx, tx, &order, marketConfig)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	item := toMarketOrderItem(order, userID)
+	return &item, nil
+}
+
+// CancelOrder 撤销自己的挂单，未成交部分冻结的鸟蛋或余额退回，已成交部分不受影响
+func (r *freeMarketMineRepository) CancelOrder(ctx context.Context, userID string, id uint64) error {
+	return r.withMarketLock(ctx, func() error {
+		return r.Transaction(ctx, func(ctx context.Context) error {
+			tx := r.DB(ctx)
+			var order model.MarketOrder
+			if err := r.lockOrder(tx, userID, id, &order); err != nil {
+				return err
+			}
+			if err := tx.Model(&model.MarketOrder{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+				"status":     common.PUBLISH_PRODUCT_STATUS_CANCEL,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				r.logger.Error("撤销挂单失败", zap.Error(err))
+				return err
+			}
+
+			if order.PublishType == common.PUBLISH_TYPE_BUY {
+				return r.ledgerRepository.Release(ctx, tx, common.BUSINESS_TYPE_MARKET, int(order.ID))
+			}
+			entry := model.LedgerEntry{
+				UserID:        order.UserID,
+				BusinessType:  common.BUSINESS_TYPE_MARKET,
+				ActionType:    common.ACTION_TYPE_REFUND,
+				RelationID:    int(order.ID),
+				RelationTitle: "出售鸟蛋撤单退回",
+			}
+			legs := []model.LedgerEntry{entry, entry}
+			legs[0].AssetType, legs[0].Amount = common.ASSET_TYPE_FROZEN_EGG, -order.Remaining()
+			legs[1].AssetType, legs[1].Amount = common.ASSET_TYPE_EGG, order.Remaining()
+			for _, leg := range legs {
+				if err := r.ledgerRepository.Post(ctx, tx, leg); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// GetOrderBook 分页获取挂单簿，出售按价格从低到高、求购按价格从高到低排列，同价格先挂单的在前
+func (r *freeMarketMineRepository) GetOrderBook(ctx context.Context, userID string, req model.MarketOrderBookRequest) (*model.MarketOrderBookResponse, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	query := r.DB(ctx).Model(&model.MarketOrder{}).
+		Where("publish_type = ? AND status = ?", req.PublishType, common.PUBLISH_PRODUCT_STATUS_NORMAL)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("查询挂单总数失败", zap.Error(err))
+		return nil, err
+	}
+
+	priceOrder := "price ASC"
+	if req.PublishType == common.PUBLISH_TYPE_BUY {
+		priceOrder = "price DESC"
+	}
+	var orders []model.MarketOrder
+	if err := query.Order(priceOrder).Order("id ASC").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&orders).Error; err != nil {
+		r.logger.Error("查询挂单列表失败", zap.Error(err))
+		return nil, err
+	}
+
+	list := make([]model.MarketOrderItem, 0, len(orders))
+	for _, order := range orders {
+		list = append(list, toMarketOrderItem(order, userID))
+	}
+	return &model.MarketOrderBookResponse{
+		Total: total,
+		List:  list,
+		Page:  page,
+		Size:  pageSize,
+	}, nil
+}
+
+// GetCandles 获取截止时间之前最近的K线，按时间从早到晚排列
+func (r *freeMarketMineRepository) GetCandles(ctx context.Context, req model.MarketCandleRequest) (*model.MarketCandleResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	end := time.Now()
+	if req.End > 0 {
+		end = time.Unix(req.End, 0)
+	}
+
+	var candles []model.MarketCandle
+	if err := r.DB(ctx).Where("period = ? AND start_at <= ?", req.Interval, end).
+		Order("start_at DESC").Limit(limit).Find(&candles).Error; err != nil {
+		r.logger.Error("查询K线失败", zap.Error(err))
+		return nil, err
+	}
+	list := make([]model.MarketCandleItem, len(candles))
+	for i, candle := range candles {
+		list[len(candles)-1-i] = model.MarketCandleItem{
+			StartAt:    candle.StartAt,
+			Open:       candle.Open,
+			High:       candle.High,
+			Low:        candle.Low,
+			Close:      candle.Close,
+			Volume:     candle.Volume,
+			Amount:     candle.Amount,
+			TradeCount: candle.TradeCount,
 		}
 	}
 
-	summary := model.SummaryDTO{
-		TotalSelled: len(response.SelledList),
-		TotalNoSell: len(response.NoSellList),
+	marketConfig, err := r.GetMarketConfig(ctx)
+	if err != nil {
+		return nil, err
 	}
-	response.Summary = summary
+	reference, err := r.referencePrice(ctx, marketConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &model.MarketCandleResponse{
+		Interval:       req.Interval,
+		ReferencePrice: reference,
+		List:           list,
+	}, nil
+}
 
-	var others []*model.FreeMarketMine
-	if err := r.DB(ctx).Where("user_id != ?", userID).Find(&others).Error; err != nil {
+// referencePrice 今日参考价，未配置时取今日之前最后一根日K线的收盘价，都没有时返回0
+func (r *freeMarketMineRepository) referencePrice(ctx context.Context, marketConfig *model.MarketConfig) (money.Money, error) {
+	if marketConfig.ReferencePrice > 0 {
+		return marketConfig.ReferencePrice, nil
+	}
+	var candle model.MarketCandle
+	if err := r.DB(ctx).Where("period = ? AND start_at < ?", common.MARKET_CANDLE_DAY, startOfDay(time.Now())).
+		Order("start_at DESC").First(&candle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return money.Zero, nil
+		}
+		r.logger.Error("查询日K线失败", zap.Error(err))
+		return money.Zero, err
+	}
+	return candle.Close, nil
+}
+
+// priceBand 今日可挂单的价格范围，没有参考价或不限浮动时返回 nil
+func (r *freeMarketMineRepository) priceBand(ctx context.Context, marketConfig *model.MarketConfig) (*model.MarketPriceBand, error) {
+	if marketConfig.PriceBandRate <= 0 {
+		return nil, nil
+	}
+	reference, err := r.referencePrice(ctx, marketConfig)
+	if err != nil || reference <= 0 {
 		return nil, err
 	}
+	delta := reference.MulRate(marketConfig.PriceBandRate)
+	return &model.MarketPriceBand{
+		ReferencePrice: reference,
+		MinPrice:       money.Max(reference-delta, money.FromFen(1)),
+		MaxPrice:       reference + delta,
+	}, nil
+}
 
-	for _, record := range others {
-		dateStr := record.Date.Format("2006-01-02")
-		response.Others = append(response.Others, model.NoSellEggDTO{
-			Id:       int(record.ID),
-			EggPrice: record.EggPrice,
-			EggNum:   int(*record.EggNum),
-			Date:     dateStr,
+// checkPriceBand 校验挂单价格是否在今日价格范围内
+func checkPriceBand(band *model.MarketPriceBand, price money.Money) error {
+	if band == nil || (price >= band.MinPrice && price <= band.MaxPrice) {
+		return nil
+	}
+	return fmt.Errorf("%w，今日可挂单价格为%s-%s元", v1.ErrMarketPriceLimit, band.MinPrice, band.MaxPrice)
+}
+
+// fillPriceLogs 填充出售挂单的改价记录
+func (r *freeMarketMineRepository) fillPriceLogs(ctx context.Context, list []model.NoSellEggDTO) error {
+	if len(list) == 0 {
+		return nil
+	}
+	orderIDs := make([]uint64, 0, len(list))
+	for _, item := range list {
+		orderIDs = append(orderIDs, uint64(item.Id))
+	}
+	var logs []model.MarketPriceLog
+	if err := r.DB(ctx).Where("order_id IN ?", orderIDs).Order("id DESC").Find(&logs).Error; err != nil {
+		r.logger.Error("查询改价记录失败", zap.Error(err))
+		return err
+	}
+	index := make(map[uint64]int, len(list))
+	for i, item := range list {
+		index[uint64(item.Id)] = i
+	}
+	for _, priceLog := range logs {
+		i := index[priceLog.OrderID]
+		list[i].PriceLogs = append(list[i].PriceLogs, model.PriceLogDTO{
+			OldPrice: priceLog.OldPrice.Yuan(),
+			NewPrice: priceLog.NewPrice.Yuan(),
+			Time:     priceLog.CreatedAt.Format("2006-01-02 15:04:05"),
 		})
 	}
+	return nil
+}
 
-	return response, nil
+// withMarketLock 持有撮合锁执行 fn，避免并发撮合时多个事务交叉锁定双方的用户资产而死锁
+func (r *freeMarketMineRepository) withMarketLock(ctx context.Context, fn func() error) error {
+	lock := cache.NewCache(ctx, common.PREFFIX_MARKET_LOCK)
+	deadline := time.Now().Add(marketLockWait)
+	for {
+		token, ok, err := lock.Lock(marketLockKey, marketLockTTL)
+		if err != nil {
+			r.logger.Error("获取撮合锁失败", zap.Error(err))
+			return err
+		}
+		if ok {
+			defer func() {
+				if err := lock.Unlock(marketLockKey, token); err != nil {
+					r.logger.Error("释放撮合锁失败", zap.Error(err))
+				}
+			}()
+			return fn()
+		}
+		if time.Now().After(deadline) {
+			return errors.New("市场繁忙，请稍后重试")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// lockOrder 锁定用户挂单中的挂单
+func (r *freeMarketMineRepository) lockOrder(tx *gorm.DB, userID string, id uint64, order *model.MarketOrder) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND user_id = ?", id, userID).First(order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("挂单不存在")
+		}
+		r.logger.Error("查询挂单失败", zap.Error(err))
+		return err
+	}
+	if order.Status != common.PUBLISH_PRODUCT_STATUS_NORMAL {
+		return errors.New("挂单已成交或已撤销")
+	}
+	return nil
+}
+
+// freeze 冻结挂单占用的资产：出售挂单冻结鸟蛋，求购挂单按挂单价冻结全部金额
+func (r *freeMarketMineRepository) freeze(ctx context.Context, tx *gorm.DB, order *model.MarketOrder) error {
+	if order.PublishType == common.PUBLISH_TYPE_BUY {
+		return r.ledgerRepository.Hold(ctx, tx, model.LedgerEntry{
+			UserID:        order.UserID,
+			BusinessType:  common.BUSINESS_TYPE_MARKET,
+			ActionType:    common.ACTION_TYPE_BUY,
+			Amount:        order.Price.Fen() * order.Quantity,
+			RelationID:    int(order.ID),
+			RelationTitle: "求购鸟蛋",
+		})
+	}
+	entry := model.LedgerEntry{
+		UserID:        order.UserID,
+		BusinessType:  common.BUSINESS_TYPE_MARKET,
+		ActionType:    common.ACTION_TYPE_SELL,
+		RelationID:    int(order.ID),
+		RelationTitle: "出售鸟蛋挂单",
+	}
+	legs := []model.LedgerEntry{entry, entry}
+	legs[0].AssetType, legs[0].Amount = common.ASSET_TYPE_EGG, -order.Quantity
+	legs[1].AssetType, legs[1].Amount = common.ASSET_TYPE_FROZEN_EGG, order.Quantity
+	for _, leg := range legs {
+		if err := r.ledgerRepository.Post(ctx, tx, leg); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (r *freeMarketMineRepository) UpdateEggPrice(ctx context.Context, price float64, id int) error {
-	// Update price for all unsold eggs for this user
-	result := r.DB(ctx).Model(&model.FreeMarketMine{}).
-		Where("status = 0 AND id = ?", id).
-		Update("egg_price", price)
+// match 将 taker 与挂单簿中价格可成交的对手方挂单逐笔撮合，直到全部成交或没有可成交的挂单
+// 不与自己的挂单成交
+func (r *freeMarketMineRepository) match(ctx context.Context, tx *gorm.DB, taker *model.MarketOrder, marketConfig *model.MarketConfig) error {
+	for taker.Remaining() > 0 {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND user_id <> ?", common.PUBLISH_PRODUCT_STATUS_NORMAL, taker.UserID)
+		if taker.PublishType == common.PUBLISH_TYPE_SELL {
+			query = query.Where("publish_type = ? AND price >= ?", common.PUBLISH_TYPE_BUY, taker.Price).Order("price DESC")
+		} else {
+			query = query.Where("publish_type = ? AND price <= ?", common.PUBLISH_TYPE_SELL, taker.Price).Order("price ASC")
+		}
+		var maker model.MarketOrder
+		if err := query.Order("id ASC").First(&maker).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			r.logger.Error("查询对手方挂单失败", zap.Error(err))
+			return err
+		}
 
-	if result.Error != nil {
-		return result.Error
+		quantity := taker.Remaining()
+		if quantity > maker.Remaining() {
+			quantity = maker.Remaining()
+		}
+		sell, buy := taker, &maker
+		if taker.PublishType == common.PUBLISH_TYPE_BUY {
+			sell, buy = &maker, taker
+		}
+		if err := r.settle(ctx, tx, sell, buy, maker.Price, quantity, marketConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settle 按成交价结算一笔撮合：从买方冻结余额扣除成交金额，卖方冻结的鸟蛋转给买方，卖方收到成交金额并扣除手续费
+// 求购挂单全部成交后，以低于挂单价成交而多冻结的余额退回
+func (r *freeMarketMineRepository) settle(ctx context.Context, tx *gorm.DB, sell, buy *model.MarketOrder, price money.Money, quantity int64, marketConfig *model.MarketConfig) error {
+	amount := money.FromFen(price.Fen() * quantity)
+	fee := money.Min(money.Max(amount.MulRate(marketConfig.FeeRate), marketConfig.MinFee), amount)
+	now := time.Now()
+	trade := model.MarketTrade{
+		SellOrderID: sell.ID,
+		BuyOrderID:  buy.ID,
+		SellerID:    sell.UserID,
+		BuyerID:     buy.UserID,
+		Price:       price,
+		Quantity:    quantity,
+		Amount:      amount,
+		Fee:         fee,
+		CreatedAt:   now,
+	}
+	if err := tx.Create(&trade).Error; err != nil {
+		r.logger.Error("创建成交记录失败", zap.Error(err))
+		return err
+	}
+	if err := r.updateCandles(tx, trade); err != nil {
+		return err
 	}
 
+	if err := r.ledgerRepository.CapturePart(ctx, tx, common.BUSINESS_TYPE_MARKET, int(buy.ID), amount.Fen()); err != nil {
+		return err
+	}
+	entries := []model.LedgerEntry{
+		{UserID: sell.UserID, ActionType: common.ACTION_TYPE_SELL, AssetType: common.ASSET_TYPE_FROZEN_EGG, Amount: -quantity, RelationTitle: "出售鸟蛋"},
+		{UserID: buy.UserID, ActionType: common.ACTION_TYPE_BUY, AssetType: common.ASSET_TYPE_EGG, Amount: quantity, RelationTitle: "购买鸟蛋"},
+		{UserID: sell.UserID, ActionType: common.ACTION_TYPE_SELL, AssetType: common.ASSET_TYPE_BALANCE, Amount: amount.Fen(), RelationTitle: "出售鸟蛋"},
+		{UserID: sell.UserID, ActionType: common.ACTION_TYPE_FEE, AssetType: common.ASSET_TYPE_BALANCE, Amount: -fee.Fen(), RelationTitle: "自由市场交易手续费"},
+	}
+	for _, entry := range entries {
+		entry.BusinessType = common.BUSINESS_TYPE_MARKET
+		entry.RelationID = int(trade.ID)
+		if err := r.ledgerRepository.Post(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+
+	for _, order := range []*model.MarketOrder{sell, buy} {
+		order.FilledQuantity += quantity
+		updates := map[string]interface{}{
+			"filled_quantity": order.FilledQuantity,
+			"updated_at":      now,
+		}
+		if order.Remaining() == 0 {
+			order.Status = common.PUBLISH_PRODUCT_STATUS_BARGIN
+			updates["status"] = order.Status
+		}
+		if err := tx.Model(&model.MarketOrder{}).Where("id = ?", order.ID).Updates(updates).Error; err != nil {
+			r.logger.Error("更新挂单成交数量失败", zap.Error(err))
+			return err
+		}
+	}
+	if buy.Status == common.PUBLISH_PRODUCT_STATUS_BARGIN {
+		return r.ledgerRepository.Release(ctx, tx, common.BUSINESS_TYPE_MARKET, int(buy.ID))
+	}
+	return nil
+}
+
+// updateCandles 将成交计入各周期的K线，撮合在撮合锁内串行执行，开盘价、收盘价按成交顺序更新
+func (r *freeMarketMineRepository) updateCandles(tx *gorm.DB, trade model.MarketTrade) error {
+	for _, period := range []string{common.MARKET_CANDLE_HOUR, common.MARKET_CANDLE_DAY, common.MARKET_CANDLE_WEEK} {
+		start := candleStart(period, trade.CreatedAt)
+		var candle model.MarketCandle
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("period = ? AND start_at = ?", period, start).First(&candle).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			candle = model.MarketCandle{
+				Period:     period,
+				StartAt:    start,
+				Open:       trade.Price,
+				High:       trade.Price,
+				Low:        trade.Price,
+				Close:      trade.Price,
+				Volume:     trade.Quantity,
+				Amount:     trade.Amount,
+				TradeCount: 1,
+				CreatedAt:  trade.CreatedAt,
+				UpdatedAt:  trade.CreatedAt,
+			}
+			if err := tx.Create(&candle).Error; err != nil {
+				r.logger.Error("创建K线失败", zap.Error(err))
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			r.logger.Error("查询K线失败", zap.Error(err))
+			return err
+		}
+		if err := tx.Model(&model.MarketCandle{}).Where("id = ?", candle.ID).Updates(map[string]interface{}{
+			"high":        money.Max(candle.High, trade.Price),
+			"low":         money.Min(candle.Low, trade.Price),
+			"close":       trade.Price,
+			"volume":      gorm.Expr("volume + ?", trade.Quantity),
+			"amount":      gorm.Expr("amount + ?", trade.Amount),
+			"trade_count": gorm.Expr("trade_count + 1"),
+			"updated_at":  trade.CreatedAt,
+		}).Error; err != nil {
+			r.logger.Error("更新K线失败", zap.Error(err))
+			return err
+		}
+	}
 	return nil
 }
+
+// candleStart 时间所在K线周期的开始时间，周线从周一开始
+func candleStart(period string, t time.Time) time.Time {
+	switch period {
+	case common.MARKET_CANDLE_HOUR:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case common.MARKET_CANDLE_WEEK:
+		day := startOfDay(t)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return startOfDay(t)
+	}
+}
+
+func toMarketOrderItem(order model.MarketOrder, userID string) model.MarketOrderItem {
+	return model.MarketOrderItem{
+		ID:             order.ID,
+		PublishType:    order.PublishType,
+		Price:          order.Price,
+		Quantity:       order.Quantity,
+		FilledQuantity: order.FilledQuantity,
+		Remaining:      order.Remaining(),
+		Status:         order.Status,
+		Mine:           order.UserID == userID,
+		CreatedAt:      order.CreatedAt,
+	}
+}
+
+// startOfDay 当天零点
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	v1 "app/api/v1"
+	"app/internal/common"
+	"app/internal/model"
+	"app/pkg/money"
+
+	"go.uber.org/zap"
+)
+
+type FreightRepository interface {
+	QuoteFreight(ctx context.Context, region model.FreightRegion, items []model.FreightItem) (*model.FreightQuote, error)
+}
+
+func NewFreightRepository(
+	repository *Repository,
+) FreightRepository {
+	return &freightRepository{
+		Repository: repository,
+	}
+}
+
+type freightRepository struct {
+	*Repository
+}
+
+// QuoteFreight 按收货地区和运费模板计算运费
+// 同一模板的商品合并计费，运费按商品金额分摊到每个商品；未配置模板且没有默认模板的商品沿用商品的最低快递费
+func (r *freightRepository) QuoteFreight(ctx context.Context, region model.FreightRegion, items []model.FreightItem) (*model.FreightQuote, error) {
+	if len(items) == 0 {
+		return nil, errors.New("订单项不能为空")
+	}
+	productIds := make([]uint64, 0, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, errors.New("商品数量不合法")
+		}
+		productIds = append(productIds, item.ProductID)
+	}
+	var products []model.Product
+	if err := r.DB(ctx).Where("id IN ?", productIds).Find(&products).Error; err != nil {
+		r.logger.Error("查询商品信息失败", zap.Error(err))
+		return nil, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[uint64(product.ID)] = product
+	}
+
+	templates, err := r.getTemplates(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &model.FreightQuote{
+		Items: make([]model.FreightItemQuote, len(items)),
+	}
+	// 按运费模板分组，记录商品在请求中的位置
+	groups := make(map[uint64][]int)
+	groupOrder := make([]uint64, 0)
+	for i, item := range items {
+		product, ok := productMap[item.ProductID]
+		if !ok {
+			return nil, errors.New("商品不存在")
+		}
+		quote.Items[i].ProductID = item.ProductID
+		templateID := product.FreightTemplateID
+		if _, ok := templates[templateID]; !ok {
+			templateID = 0
+		}
+		if _, ok := templates[templateID]; !ok {
+			// 没有可用的运费模板
+			quote.Items[i].CourierFee = money.FromYuan(product.CourierFeeMin)
+			quote.CourierFee += quote.Items[i].CourierFee
+			continue
+		}
+		if _, ok := groups[templateID]; !ok {
+			groupOrder = append(groupOrder, templateID)
+		}
+		groups[templateID] = append(groups[templateID], i)
+	}
+
+	for _, templateID := range groupOrder {
+		template := templates[templateID]
+		rule := matchFreightRule(template.Rules, region)
+		if rule == nil || rule.NotDeliverable == 1 {
+			return nil, v1.ErrRegionNotDeliverable
+		}
+		indexes := groups[templateID]
+		goodsFees := make([]money.Money, len(indexes))
+		var goodsFee money.Money
+		units := 0
+		for k, i := range indexes {
+			product := productMap[items[i].ProductID]
+			goodsFees[k] = money.FromYuan(product.CurrentPrice).Mul(items[i].Quantity)
+			goodsFee += goodsFees[k]
+			if template.ChargeType == common.FREIGHT_CHARGE_WEIGHT {
+				units += product.Weight * items[i].Quantity
+			} else {
+				units += items[i].Quantity
+			}
+		}
+		fee := calcFreight(rule, units, goodsFee)
+		// 按商品金额分摊运费，余数计入最后一个商品
+		allocated := money.Zero
+		for k, i := range indexes {
+			share := fee - allocated
+			if k < len(indexes)-1 {
+				if goodsFee > 0 {
+					share = money.FromFen(fee.Fen() * goodsFees[k].Fen() / goodsFee.Fen())
+				} else {
+					share = money.Zero
+				}
+			}
+			quote.Items[i].CourierFee = share
+			allocated += share
+		}
+		quote.CourierFee += fee
+	}
+	return quote, nil
+}
+
+// getTemplates 获取商品使用的运费模板及其地区规则，默认模板的键为 0
+func (r *freightRepository) getTemplates(ctx context.Context, products []model.Product) (map[uint64]model.FreightTemplate, error) {
+	templateIds := make([]uint64, 0, len(products))
+	for _, product := range products {
+		if product.FreightTemplateID > 0 {
+			templateIds = append(templateIds, product.FreightTemplateID)
+		}
+	}
+	var templates []model.FreightTemplate
+	if err := r.DB(ctx).Preload("Rules").
+		Where("id IN ? OR is_default = ?", append(templateIds, 0), 1).
+		Find(&templates).Error; err != nil {
+		r.logger.Error("查询运费模板失败", zap.Error(err))
+		return nil, err
+	}
+	templateMap := make(map[uint64]model.FreightTemplate, len(templates))
+	for _, template := range templates {
+		templateMap[template.ID] = template
+		if template.IsDefault == 1 {
+			templateMap[0] = template
+		}
+	}
+	return templateMap, nil
+}
+
+// matchFreightRule 匹配收货地区最具体的规则，区县优先于城市，城市优先于省
+func matchFreightRule(rules []model.FreightRule, region model.FreightRegion) *model.FreightRule {
+	var matched *model.FreightRule
+	best := -1
+	for i := range rules {
+		rule := &rules[i]
+		if (rule.Province != "" && rule.Province != region.Province) ||
+			(rule.City != "" && rule.City != region.City) ||
+			(rule.District != "" && rule.District != region.District) {
+			continue
+		}
+		score := 0
+		if rule.Province != "" {
+			score++
+		}
+		if rule.City != "" {
+			score++
+		}
+		if rule.District != "" {
+			score++
+		}
+		if score > best {
+			matched = rule
+			best = score
+		}
+	}
+	return matched
+}
+
+// calcFreight 按首件（首重）加续件（续重）计算运费，达到包邮门槛时免运费
+func calcFreight(rule *model.FreightRule, units int, goodsFee money.Money) money.Money {
+	if rule.FreeThreshold > 0 && goodsFee >= rule.FreeThreshold {
+		return money.Zero
+	}
+	fee := rule.FirstFee
+	if units > rule.FirstUnit && rule.ExtraUnit > 0 {
+		extra := (units - rule.FirstUnit + rule.ExtraUnit - 1) / rule.ExtraUnit
+		fee += rule.ExtraFee.Mul(extra)
+	}
+	return fee
+}
@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"app/internal/common"
+	"app/internal/model"
+	"app/pkg/config"
+	"app/pkg/money"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LedgerRepository 用户积分、余额的唯一记账入口
+// 每笔变动生成一张凭证：用户账户一条分录，对应业务的系统账户一条金额相反的分录，凭证内金额合计为0
+// 需要先占用、后结算的资金（提现、退款审核、交易担保等）通过 Hold 冻结，再 Capture 扣除或 Release 解冻
+// 分批成交的业务（自由市场求购等）通过 CapturePart 按成交金额部分扣除，剩余部分最终 Release 解冻
+type LedgerRepository interface {
+	Post(ctx context.Context, tx *gorm.DB, entry model.LedgerEntry) error
+	Compensate(ctx context.Context, tx *gorm.DB, entry model.LedgerEntry) error
+	Hold(ctx context.Context, tx *gorm.DB, entry model.LedgerEntry) error
+	Release(ctx context.Context, tx *gorm.DB, businessType int8, relationID int) error
+	Capture(ctx context.Context, tx *gorm.DB, businessType int8, relationID int) error
+	CapturePart(ctx context.Context, tx *gorm.DB, businessType int8, relationID int, amount int64) error
+}
+
+func NewLedgerRepository(
+	repository *Repository,
+) LedgerRepository {
+	return &ledgerRepository{
+		Repository: repository,
+	}
+}
+
+type ledgerRepository struct {
+	*Repository
+}
+
+// Post 记账，tx 为空时开启新事务
+// 余额、积分通过带条件的原子更新扣减，不足时返回错误，保证用户资产不为负
+func (r *ledgerRepository) Post(ctx context.Context, tx *gorm.DB, entry model.LedgerEntry) error {
+	if tx == nil {
+		return r.Transaction(ctx, func(ctx context.Context) error {
+			return r.post(ctx, r.DB(ctx), entry)
+		})
+	}
+	return r.post(ctx, tx, entry)
+}
+
+func (r *ledgerRepository) post(ctx context.Context, tx *gorm.DB, entry model.LedgerEntry) error {
+	if entry.Amount == 0 {
+		return nil
+	}
+	column, notEnough := assetColumn(entry.AssetType)
+	if column == "" {
+		return errors.New("资产类型不合法")
+	}
+
+	now := time.Now()
+	result := tx.Model(&model.UserAsset{}).
+		Where("user_id = ? AND "+column+" + ? >= 0", entry.UserID, entry.Amount).
+		Updates(map[string]interface{}{
+			column:       gorm.Expr(column+" + ?", entry.Amount),
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		r.logger.Error("更新用户资产失败", zap.Error(result.Error))
+		return result.Error
+	}
+	// 更新后行已被锁定，读取的剩余数量即本次变动后的值
+	var userAsset model.UserAsset
+	if err := tx.Where("user_id = ?", entry.UserID).First(&userAsset).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户资产不存在")
+		}
+		r.logger.Error("查询用户资产失败", zap.Error(err))
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(notEnough)
+	}
+	leftNum := assetLeftNum(&userAsset, entry.AssetType)
+	if entry.AssetType == common.ASSET_TYPE_POINT {
+		if err := r.applyPointLots(tx, entry, now); err != nil {
+			return err
+		}
+	}
+
+	if err := r.writeJournal(tx, entry, leftNum, now); err != nil {
+		return err
+	}
+
+	// 资产已变化，删除缓存
+	if err := config.Rdb.Del(ctx, common.PREFFIX_USER_ASSET+entry.UserID).Err(); err != nil {
+		r.logger.Debug("删除用户资产缓存失败", zap.Error(err))
+	}
+	return nil
+}
+
+// Compensate 补记调整流水，只写凭证不改动用户资产，用于对账时使流水与资产一致
+func (r *ledgerRepository) Compensate(ctx context.Context, tx *gorm.DB, entry model.LedgerEntry) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	var userAsset model.UserAsset
+	if err := tx.Where("user_id = ?", entry.UserID).First(&userAsset).Error; err != nil {
+		r.logger.Error("查询用户资产失败", zap.Error(err))
+		return err
+	}
+	leftNum := assetLeftNum(&userAsset, entry.AssetType)
+	return r.writeJournal(tx, entry, leftNum, time.Now())
+}
+
+// Hold 冻结资金，entry.Amount 为冻结金额（分），从可用余额转入冻结余额并记录冻结单，可用余额不足时返回错误
+// 同一业务单据只能冻结一次
+func (r *ledgerRepository) Hold(ctx context.Context, tx *gorm.DB, entry model.LedgerEntry) error {
+	if tx == nil {
+		return r.Transaction(ctx, func(ctx context.Context) error {
+			return r.Hold(ctx, r.DB(ctx), entry)
+		})
+	}
+	if entry.Amount <= 0 {
+		return errors.New("冻结金额必须大于0")
+	}
+	hold := model.UserAssetHold{
+		UserID:        entry.UserID,
+		BusinessType:  entry.BusinessType,
+		RelationID:    entry.RelationID,
+		ActionType:    entry.ActionType,
+		RelationTitle: entry.RelationTitle,
+		Amount:        money.FromFen(entry.Amount),
+		Status:        common.HOLD_STATUS_HELD,
+	}
+	if err := tx.Create(&hold).Error; err != nil {
+		r.logger.Error("创建冻结记录失败", zap.Error(err))
+		return err
+	}
+	legs := []model.LedgerEntry{entry, entry}
+	legs[0].AssetType, legs[0].Amount = common.ASSET_TYPE_BALANCE, -entry.Amount
+	legs[1].AssetType = common.ASSET_TYPE_FROZEN
+	for _, leg := range legs {
+		if err := r.post(ctx, tx, leg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Release 解冻资金，未扣除的冻结金额退回可用余额；已解冻或已扣除的冻结单不重复处理
+func (r *ledgerRepository) Release(ctx context.Context, tx *gorm.DB, businessType int8, relationID int) error {
+	return r.settleHold(ctx, tx, businessType, relationID, common.HOLD_STATUS_RELEASED)
+}
+
+// Capture 扣除剩余的冻结资金，业务完成后调用；已解冻或已扣除的冻结单不重复处理
+func (r *ledgerRepository) Capture(ctx context.Context, tx *gorm.DB, businessType int8, relationID int) error {
+	return r.settleHold(ctx, tx, businessType, relationID, common.HOLD_STATUS_CAPTURED)
+}
+
+// CapturePart 按 amount（分）部分扣除冻结资金，超过剩余冻结金额时返回错误，全部扣除后冻结单标记为已扣除
+func (r *ledgerRepository) CapturePart(ctx context.Context, tx *gorm.DB, businessType int8, relationID int, amount int64) error {
+	if tx == nil {
+		return r.Transaction(ctx, func(ctx context.Context) error {
+			return r.CapturePart(ctx, r.DB(ctx), businessType, relationID, amount)
+		})
+	}
+	if amount <= 0 {
+		return errors.New("扣除金额必须大于0")
+	}
+	hold, err := r.lockHold(tx, businessType, relationID)
+	if err != nil {
+		return err
+	}
+	if hold.Status != common.HOLD_STATUS_HELD {
+		return errors.New("冻结记录已结算")
+	}
+	remaining := hold.Amount.Fen() - hold.CapturedAmount.Fen()
+	if amount > remaining {
+		return errors.New("冻结余额不足")
+	}
+	updates := map[string]interface{}{
+		"captured_amount": gorm.Expr("captured_amount + ?", amount),
+		"updated_at":      time.Now(),
+	}
+	if amount == remaining {
+		updates["status"] = common.HOLD_STATUS_CAPTURED
+	}
+	if err := tx.Model(&hold).Updates(updates).Error; err != nil {
+		r.logger.Error("更新冻结记录失败", zap.Error(err))
+		return err
+	}
+	return r.post(ctx, tx, model.LedgerEntry{
+		UserID:        hold.UserID,
+		BusinessType:  hold.BusinessType,
+		ActionType:    hold.ActionType,
+		AssetType:     common.ASSET_TYPE_FROZEN,
+		Amount:        -amount,
+		RelationID:    hold.RelationID,
+		RelationTitle: hold.RelationTitle,
+	})
+}
+
+func (r *ledgerRepository) settleHold(ctx context.Context, tx *gorm.DB, businessType int8, relationID int, status uint8) error {
+	if tx == nil {
+		return r.Transaction(ctx, func(ctx context.Context) error {
+			return r.settleHold(ctx, r.DB(ctx), businessType, relationID, status)
+		})
+	}
+	hold, err := r.lockHold(tx, businessType, relationID)
+	if err != nil {
+		return err
+	}
+	if hold.Status != common.HOLD_STATUS_HELD {
+		return nil
+	}
+	remaining := hold.Amount.Fen() - hold.CapturedAmount.Fen()
+	updates := map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	}
+	if status == common.HOLD_STATUS_CAPTURED {
+		updates["captured_amount"] = hold.Amount
+	}
+	if err := tx.Model(&hold).Updates(updates).Error; err != nil {
+		r.logger.Error("更新冻结记录失败", zap.Error(err))
+		return err
+	}
+
+	entry := model.LedgerEntry{
+		UserID:        hold.UserID,
+		BusinessType:  hold.BusinessType,
+		ActionType:    hold.ActionType,
+		AssetType:     common.ASSET_TYPE_FROZEN,
+		Amount:        -remaining,
+		RelationID:    hold.RelationID,
+		RelationTitle: hold.RelationTitle,
+	}
+	if status == common.HOLD_STATUS_CAPTURED {
+		return r.post(ctx, tx, entry)
+	}
+	entry.ActionType = common.ACTION_TYPE_REFUND
+	entry.RelationTitle = hold.RelationTitle + "退回"
+	legs := []model.LedgerEntry{entry, entry}
+	legs[1].AssetType, legs[1].Amount = common.ASSET_TYPE_BALANCE, remaining
+	for _, leg := range legs {
+		if err := r.post(ctx, tx, leg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockHold 锁定业务单据的冻结记录
+func (r *ledgerRepository) lockHold(tx *gorm.DB, businessType int8, relationID int) (model.UserAssetHold, error) {
+	var hold model.UserAssetHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("business_type = ? AND relation_id = ?", businessType, relationID).First(&hold).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return hold, errors.New("冻结记录不存在")
+		}
+		r.logger.Error("查询冻结记录失败", zap.Error(err))
+		return hold, err
+	}
+	return hold, nil
+}
+
+// applyPointLots 维护积分批次：增加积分时新建批次，扣减积分时从指定批次或按过期时间先进先出扣减
+func (r *ledgerRepository) applyPointLots(tx *gorm.DB, entry model.LedgerEntry, now time.Time) error {
+	if entry.Amount > 0 {
+		lot := model.PointLot{
+			UserID:       entry.UserID,
+			BusinessType: entry.BusinessType,
+			RelationID:   entry.RelationID,
+			Points:       entry.Amount,
+			Remaining:    entry.Amount,
+			ExpireAt:     entry.ExpireAt,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if err := tx.Create(&lot).Error; err != nil {
+			r.logger.Error("创建积分批次失败", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ? AND remaining > 0", entry.UserID)
+	if entry.PointLotID > 0 {
+		query = query.Where("id = ?", entry.PointLotID)
+	}
+	var lots []model.PointLot
+	if err := query.Order("expire_at IS NULL, expire_at ASC, id ASC").Find(&lots).Error; err != nil {
+		r.logger.Error("查询积分批次失败", zap.Error(err))
+		return err
+	}
+	need := -entry.Amount
+	for _, lot := range lots {
+		if need == 0 {
+			break
+		}
+		used := lot.Remaining
+		if used > need {
+			used = need
+		}
+		if err := tx.Model(&model.PointLot{}).Where("id = ?", lot.ID).Updates(map[string]interface{}{
+			"remaining":  gorm.Expr("remaining - ?", used),
+			"updated_at": now,
+		}).Error; err != nil {
+			r.logger.Error("扣减积分批次失败", zap.Error(err))
+			return err
+		}
+		need -= used
+	}
+	// 批次与积分不一致时不阻断业务，由对账任务发现差异
+	if need > 0 {
+		r.logger.Warn("积分批次不足", zap.String("user_id", entry.UserID), zap.Int64("missing", need))
+	}
+	return nil
+}
+
+// assetColumn 资产类型对应的 user_asset 字段及数量不足时的提示
+func assetColumn(assetType int8) (string, string) {
+	switch assetType {
+	case common.ASSET_TYPE_POINT:
+		return "points", "积分不足"
+	case common.ASSET_TYPE_BALANCE:
+		return "balance", "余额不足"
+	case common.ASSET_TYPE_FROZEN:
+		return "frozen_balance", "冻结余额不足"
+	case common.ASSET_TYPE_EGG:
+		return "eggs", "鸟蛋不足"
+	case common.ASSET_TYPE_FROZEN_EGG:
+		return "frozen_eggs", "冻结鸟蛋不足"
+	default:
+		return "", ""
+	}
+}
+
+// assetLeftNum 资产类型对应的剩余数量，余额单位为分
+func assetLeftNum(userAsset *model.UserAsset, assetType int8) int64 {
+	switch assetType {
+	case common.ASSET_TYPE_BALANCE:
+		return userAsset.Balance.Fen()
+	case common.ASSET_TYPE_FROZEN:
+		return userAsset.FrozenBalance.Fen()
+	case common.ASSET_TYPE_EGG:
+		return userAsset.Eggs
+	case common.ASSET_TYPE_FROZEN_EGG:
+		return userAsset.FrozenEggs
+	default:
+		return int64(userAsset.Points)
+	}
+}
+
+// writeJournal 写入一张凭证：用户账户分录和对应业务系统账户的反向分录
+func (r *ledgerRepository) writeJournal(tx *gorm.DB, entry model.LedgerEntry, leftNum int64, now time.Time) error {
+	journalNo := "J" + common.GenerateOrderNo()
+	records := []model.UserAssetRecord{
+		{
+			UserId:        entry.UserID,
+			BusinessType:  entry.BusinessType,
+			ActionType:    entry.ActionType,
+			AssetType:     entry.AssetType,
+			ActionNum:     entry.Amount,
+			LeftNum:       leftNum,
+			RelationId:    entry.RelationID,
+			RelationTitle: entry.RelationTitle,
+			JournalNo:     journalNo,
+			AccountType:   common.LEDGER_ACCOUNT_USER,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+		{
+			// 系统账户不维护余额，按 action_num 汇总即可得到
+			UserId:        common.LEDGER_SYSTEM_ACCOUNT_PREFIX + strconv.Itoa(int(entry.BusinessType)),
+			BusinessType:  entry.BusinessType,
+			ActionType:    entry.ActionType,
+			AssetType:     entry.AssetType,
+			ActionNum:     -entry.Amount,
+			RelationId:    entry.RelationID,
+			RelationTitle: entry.RelationTitle,
+			JournalNo:     journalNo,
+			AccountType:   common.LEDGER_ACCOUNT_SYSTEM,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		r.logger.Error("创建资产变动记录失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
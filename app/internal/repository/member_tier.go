@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"app/internal/cache"
+	"app/internal/common"
+	"app/internal/model"
+	"app/pkg/money"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MemberTierRepository interface {
+	GetTierRules(ctx context.Context) ([]model.MemberTierRule, error)
+	EvaluateRole(ctx context.Context, tx *gorm.DB, userID string, actorType uint8, actorID string, reason string) error
+	AssignRole(ctx context.Context, userID string, role int, actorID string, reason string) error
+}
+
+func NewMemberTierRepository(
+	repository *Repository,
+	settingsRepository SettingsRepository,
+) MemberTierRepository {
+	return &memberTierRepository{
+		Repository:         repository,
+		settingsRepository: settingsRepository,
+	}
+}
+
+type memberTierRepository struct {
+	*Repository
+	settingsRepository SettingsRepository
+}
+
+// defaultMemberTierRules 未配置 member_tier_rules 时使用的默认规则
+// 有消费即为高级会员，购买消费满3万、6万、24万依次晋升农场主等级，合伙人由后台指定
+var defaultMemberTierRules = []model.MemberTierRule{
+	{Role: common.ROLE_VIP, Name: "高级会员", SpendType: common.TIER_SPEND_ALL, MinAmount: money.FromFen(1)},
+	{Role: common.ROLE_FARMER_BEGIN, Name: "初级农场主", SpendType: common.TIER_SPEND_BUY, MinAmount: money.FromYuan(30000)},
+	{Role: common.ROLE_FARMER_VIP, Name: "高级农场主", SpendType: common.TIER_SPEND_BUY, MinAmount: money.FromYuan(60000)},
+	{Role: common.ROLE_FARMER_EXPERT, Name: "资深农场主", SpendType: common.TIER_SPEND_BUY, MinAmount: money.FromYuan(240000)},
+	{Role: common.ROLE_FARMER_PARTNER, Name: "合伙人", Manual: true},
+}
+
+// tierSpendStatuses 计入消费金额的订单状态，退款中的订单在退款完成前仍然计入
+var tierSpendStatuses = []uint8{
+	common.ORDER_STATUS_SHIPPED,
+	common.ORDER_STATUS_RECEIVED,
+	common.ORDER_STATUS_EVALUATE,
+	common.ORDER_STATUS_COMPLETE,
+	common.ORDER_STATUS_REFUNDED,
+}
+
+// tierSpendKey 消费金额的统计口径
+type tierSpendKey struct {
+	spendType  string
+	windowDays int
+}
+
+// GetTierRules 获取会员等级规则，按身份从低到高排序
+func (r *memberTierRepository) GetTierRules(ctx context.Context) ([]model.MemberTierRule, error) {
+	rules := defaultMemberTierRules
+	value, err := r.settingsRepository.GetSettings(ctx, common.SETTINGS_MEMBER_TIER_RULES)
+	if err == nil && value != "" {
+		var configured []model.MemberTierRule
+		if err := json.Unmarshal([]byte(value), &configured); err != nil {
+			r.logger.Error("解析会员等级规则失败", zap.Error(err))
+			return nil, err
+		}
+		rules = configured
+	}
+	sorted := make([]model.MemberTierRule, len(rules))
+	copy(sorted, rules)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Role < sorted[j].Role
+	})
+	return sorted, nil
+}
+
+// EvaluateRole 按等级规则重新计算用户身份，升级和降级都会记录变更日志；tx 为空时开启新事务
+// 等级逐级判断，未达到某一等级时不再判断更高的等级；当前为后台指定等级的用户不参与计算
+func (r *memberTierRepository) EvaluateRole(ctx context.Context, tx *gorm.DB, userID string, actorType uint8, actorID string, reason string) error {
+	if tx == nil {
+		return r.Transaction(ctx, func(ctx context.Context) error {
+			return r.EvaluateRole(ctx, r.DB(ctx), userID, actorType, actorID, reason)
+		})
+	}
+	rules, err := r.GetTierRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	var account model.Account
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).Select("id", "user_id", "role").First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		r.logger.Error("获取用户身份失败", zap.Error(err))
+		return err
+	}
+	currentRole := int(account.Role)
+	for _, rule := range rules {
+		if rule.Manual && rule.Role == currentRole {
+			return nil
+		}
+	}
+
+	// 相同统计口径的消费金额只查询一次
+	spends := make(map[tierSpendKey]money.Money)
+	targetRole := common.ROLE_NORMAL
+	for _, rule := range rules {
+		if rule.Manual {
+			continue
+		}
+		key := tierSpendKey{spendType: rule.SpendType, windowDays: rule.WindowDays}
+		spend, ok := spends[key]
+		if !ok {
+			spend, err = r.getSpendAmount(tx, userID, rule.SpendType, rule.WindowDays)
+			if err != nil {
+				return err
+			}
+			spends[key] = spend
+		}
+		if spend < rule.MinAmount {
+			break
+		}
+		targetRole = rule.Role
+	}
+	if targetRole == currentRole {
+		return nil
+	}
+	return r.changeRole(ctx, tx, userID, currentRole, targetRole, actorType, actorID, reason)
+}
+
+// AssignRole 后台指定用户身份
+func (r *memberTierRepository) AssignRole(ctx context.Context, userID string, role int, actorID string, reason string) error {
+	if role < common.ROLE_NORMAL || role > common.ROLE_FARMER_PARTNER {
+		return errors.New("身份不合法")
+	}
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		var account model.Account
+		if err := r.DB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).Select("id", "user_id", "role").First(&account).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("用户不存在")
+			}
+			return err
+		}
+		if int(account.Role) == role {
+			return nil
+		}
+		return r.changeRole(ctx, r.DB(ctx), userID, int(account.Role), role, common.ORDER_ACTOR_ADMIN, actorID, reason)
+	})
+}
+
+// getSpendAmount 统计用户已支付订单的消费金额
+func (r *memberTierRepository) getSpendAmount(tx *gorm.DB, userID string, spendType string, windowDays int) (money.Money, error) {
+	query := tx.Model(&model.UserOrderItem{}).Where("user_id = ? AND status IN ?", userID, tierSpendStatuses)
+	switch spendType {
+	case common.TIER_SPEND_BUY:
+		query = query.Where("category1_id != ?", common.CATEGORY_ADOPT)
+	case common.TIER_SPEND_ADOPT:
+		query = query.Where("category1_id = ?", common.CATEGORY_ADOPT)
+	}
+	if windowDays > 0 {
+		query = query.Where("pay_time >= ?", time.Now().AddDate(0, 0, -windowDays))
+	}
+	var amount money.Money
+	if err := query.Select("COALESCE(SUM(total_fee), 0)").Scan(&amount).Error; err != nil {
+		r.logger.Error("统计用户消费金额失败", zap.Error(err))
+		return 0, err
+	}
+	return amount, nil
+}
+
+// changeRole 更新用户身份，记录变更日志并清除用户缓存
+func (r *memberTierRepository) changeRole(ctx context.Context, tx *gorm.DB, userID string, fromRole, toRole int, actorType uint8, actorID string, reason string) error {
+	if err := tx.Model(&model.Account{}).Where("user_id = ?", userID).Update("role", toRole).Error; err != nil {
+		r.logger.Error("更新用户身份失败", zap.Error(err))
+		return err
+	}
+	if err := tx.Create(&model.AccountRoleLog{
+		UserID:    userID,
+		FromRole:  fromRole,
+		ToRole:    toRole,
+		ActorType: actorType,
+		ActorID:   actorID,
+		Reason:    reason,
+	}).Error; err != nil {
+		r.logger.Error("记录身份变更失败", zap.Error(err))
+		return err
+	}
+	if err := cache.NewCache(ctx, "account:").Del(userID); err != nil {
+		r.logger.Error("清除用户缓存失败", zap.Error(err))
+	}
+	r.logger.Info("用户身份变更",
+		zap.String("user_id", userID),
+		zap.Int("from_role", fromRole),
+		zap.Int("to_role", toRole),
+		zap.String("reason", reason),
+	)
+	return nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "app/api/v1"
+	"app/internal/common"
+	"app/internal/model"
+	"app/pkg/money"
+
+	"go.uber.org/zap"
+)
+
+type OrderPricingRepository interface {
+	QuoteOrder(ctx context.Context, userID string, items []model.OrderItemRequest, region model.FreightRegion) (*model.OrderQuote, error)
+	VerifyClientQuote(quote *model.OrderQuote, items []model.OrderItemRequest) error
+}
+
+func NewOrderPricingRepository(
+	repository *Repository,
+	freightRepository FreightRepository,
+	userCouponRepository UserCouponRepository,
+) OrderPricingRepository {
+	return &orderPricingRepository{
+		Repository:           repository,
+		freightRepository:    freightRepository,
+		userCouponRepository: userCouponRepository,
+	}
+}
+
+type orderPricingRepository struct {
+	*Repository
+	freightRepository    FreightRepository
+	userCouponRepository UserCouponRepository
+}
+
+// QuoteOrder 根据商品表、运费模板、用户优惠券和会员身份重新计算每个订单项的价格
+func (r *orderPricingRepository) QuoteOrder(ctx context.Context, userID string, items []model.OrderItemRequest, region model.FreightRegion) (*model.OrderQuote, error) {
+	if len(items) == 0 {
+		return nil, errors.New("订单项不能为空")
+	}
+
+	// 获取商品信息
+	productIds := make([]uint64, 0, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, errors.New("商品数量不合法")
+		}
+		productIds = append(productIds, item.ProductID)
+	}
+	var products []model.Product
+	if err := r.DB(ctx).Where("id IN ?", productIds).Find(&products).Error; err != nil {
+		r.logger.Error("查询商品信息失败", zap.Error(err))
+		return nil, err
+	}
+	productMap := make(map[uint64]model.Product, len(products))
+	for _, product := range products {
+		productMap[uint64(product.ID)] = product
+	}
+
+	// 按收货地区计算运费
+	freightItems := make([]model.FreightItem, 0, len(items))
+	for _, item := range items {
+		freightItems = append(freightItems, model.FreightItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	freight, err := r.freightRepository.QuoteFreight(ctx, region, freightItems)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取用户身份，用于计算会员折扣
+	var role int
+	if err := r.DB(ctx).Model(&model.Account{}).Where("user_id = ?", userID).Select("role").Scan(&role).Error; err != nil {
+		r.logger.Error("获取用户身份失败", zap.Error(err))
+		return nil, err
+	}
+
+	quote := &model.OrderQuote{
+		Items: make([]model.OrderItemQuote, 0, len(items)),
+	}
+	usedCoupons := make(map[uint64]bool)
+	exclusiveCoupon := ""
+	for i, item := range items {
+		product, ok := productMap[item.ProductID]
+		if !ok {
+			return nil, errors.New("商品不存在")
+		}
+		unitPrice := money.FromYuan(product.CurrentPrice)
+		goodsFee := unitPrice.Mul(item.Quantity)
+		itemQuote := model.OrderItemQuote{
+			ProductID:   item.ProductID,
+			ProductName: product.ProductName,
+			HeaderImg:   product.HeaderImg,
+			StoreID:     uint64(product.StoreID),
+			Quantity:    item.Quantity,
+			UnitPrice:   unitPrice,
+			GoodsFee:    goodsFee,
+			CourierFee:  freight.Items[i].CourierFee,
+			Note:        item.Note,
+		}
+		if product.Category1ID != nil {
+			itemQuote.Category1ID = *product.Category1ID
+		}
+		if product.Category2ID != nil {
+			itemQuote.Category2ID = *product.Category2ID
+		}
+		// 会员折扣，高级会员及以上身份享受
+		if role >= common.ROLE_VIP {
+			itemQuote.MemberDiscount = money.Min(money.FromYuan(product.MemberDiscount), goodsFee)
+		}
+		// 优惠券抵扣，以用户实际持有的优惠券为准，按优惠券模板的规则计算
+		if item.CouponID > 0 {
+			if usedCoupons[item.CouponID] {
+				return nil, v1.ErrCouponUnavailable
+			}
+			application, err := r.userCouponRepository.ValidateCoupon(ctx, userID, item.CouponID, &itemQuote)
+			if err != nil {
+				return nil, err
+			}
+			usedCoupons[item.CouponID] = true
+			if application.Exclusive {
+				exclusiveCoupon = application.CouponName
+			}
+			itemQuote.CouponID = item.CouponID
+			applyCouponDiscount(&itemQuote, application)
+		}
+		itemQuote.TotalFee = itemQuote.GoodsFee + itemQuote.CourierFee - itemQuote.CouponPrice - itemQuote.MemberDiscount
+
+		quote.Items = append(quote.Items, itemQuote)
+		quote.GoodsFee += itemQuote.GoodsFee
+		quote.CourierFee += itemQuote.CourierFee
+		quote.CouponDiscount += itemQuote.CouponPrice
+		quote.MemberDiscount += itemQuote.MemberDiscount
+		quote.TotalFee += itemQuote.TotalFee
+	}
+	// 独占的优惠券不可与其他优惠券同单使用
+	if exclusiveCoupon != "" && len(usedCoupons) > 1 {
+		return nil, fmt.Errorf("%w，%s不可与其他优惠券同时使用", v1.ErrCouponUnavailable, exclusiveCoupon)
+	}
+	return quote, nil
+}
+
+// VerifyClientQuote 校验客户端提交的价格与服务端报价是否一致
+func (r *orderPricingRepository) VerifyClientQuote(quote *model.OrderQuote, items []model.OrderItemRequest) error {
+	if len(quote.Items) != len(items) {
+		return v1.ErrOrderPriceChanged
+	}
+	for i, item := range items {
+		itemQuote := quote.Items[i]
+		clientTotal := item.CurrentPrice.Mul(item.Quantity) + item.CourierFeeMin - item.CouponPrice - item.MemberDiscount
+		if item.CurrentPrice != itemQuote.UnitPrice || clientTotal != itemQuote.TotalFee {
+			r.logger.Info("客户端报价与服务端报价不一致",
+				zap.Uint64("product_id", item.ProductID),
+				zap.Stringer("client_total", clientTotal),
+				zap.Stringer("server_total", itemQuote.TotalFee),
+			)
+			return v1.ErrOrderPriceChanged
+		}
+	}
+	return nil
+}
+
+// applyCouponDiscount 将优惠券抵扣计入订单项报价
+// 不可与会员折扣叠加的优惠券，使用后该订单项不再享受会员折扣；免运费券只抵扣运费，其他优惠券只抵扣商品金额
+func applyCouponDiscount(itemQuote *model.OrderItemQuote, application *model.CouponApplication) {
+	if !application.StackMember {
+		itemQuote.MemberDiscount = 0
+	}
+	limit := itemQuote.GoodsFee - itemQuote.MemberDiscount
+	if application.FreeShipping {
+		limit = itemQuote.CourierFee
+	}
+	itemQuote.UserCouponID = application.UserCouponID
+	itemQuote.CouponPrice = money.Min(application.Discount, limit)
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	v1 "app/api/v1"
+	"app/internal/common"
+	"app/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type OrderStatusRepository interface {
+	Transit(ctx context.Context, tx *gorm.DB, orderItem *model.UserOrderItem, to uint8, actorType uint8, actorID string, remark string) error
+	CreateLogs(ctx context.Context, tx *gorm.DB, logs []model.OrderStatusLog) error
+	GetTimeline(ctx context.Context, orderItemID uint64) ([]model.OrderStatusLog, error)
+	GetStatusBefore(ctx context.Context, tx *gorm.DB, orderItemID uint64, status uint8) (uint8, error)
+}
+
+func NewOrderStatusRepository(
+	repository *Repository,
+) OrderStatusRepository {
+	return &orderStatusRepository{
+		Repository: repository,
+	}
+}
+
+type orderStatusRepository struct {
+	*Repository
+}
+
+// Transit 按状态机变更订单项状态，并记录变更日志；tx 为空时使用默认连接
+func (r *orderStatusRepository) Transit(ctx context.Context, tx *gorm.DB, orderItem *model.UserOrderItem, to uint8, actorType uint8, actorID string, remark string) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	from := orderItem.Status
+	if !common.CanTransitOrderStatus(from, to, actorType) {
+		r.logger.Info("非法的订单状态变更",
+			zap.Uint64("order_item_id", orderItem.ID),
+			zap.Uint8("from", from),
+			zap.Uint8("to", to),
+			zap.Uint8("actor_type", actorType),
+		)
+		return v1.ErrOrderStatusIllegal
+	}
+
+	now := time.Now()
+	updateFields := map[string]interface{}{
+		"status":     to,
+		"updated_at": now,
+	}
+	switch {
+	case from == common.ORDER_STATUS_PENDING && to == common.ORDER_STATUS_SHIPPED:
+		// 支付时间
+		updateFields["pay_time"] = now
+	case from == common.ORDER_STATUS_SHIPPED && to == common.ORDER_STATUS_RECEIVED:
+		// 发货时间
+		updateFields["shipped_at"] = now
+	case from == common.ORDER_STATUS_EVALUATE && to == common.ORDER_STATUS_COMPLETE:
+		// 完成时间
+		updateFields["completed_at"] = now
+	}
+
+	// 以当前状态为条件更新，防止并发下重复变更
+	result := tx.Model(&model.UserOrderItem{}).
+		Where("id = ? AND status = ?", orderItem.ID, from).
+		Updates(updateFields)
+	if result.Error != nil {
+		r.logger.Error("更新订单状态失败", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return v1.ErrOrderStatusChanged
+	}
+
+	log := model.OrderStatusLog{
+		OrderID:     orderItem.OrderID,
+		OrderItemID: orderItem.ID,
+		FromStatus:  from,
+		ToStatus:    to,
+		ActorType:   actorType,
+		ActorID:     actorID,
+		Remark:      remark,
+		CreatedAt:   now,
+	}
+	if err := tx.Create(&log).Error; err != nil {
+		r.logger.Error("记录订单状态变更失败", zap.Error(err))
+		return err
+	}
+	orderItem.Status = to
+	return nil
+}
+
+// CreateLogs 批量记录订单状态日志，用于下单等初始状态
+func (r *orderStatusRepository) CreateLogs(ctx context.Context, tx *gorm.DB, logs []model.OrderStatusLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	if err := tx.Create(&logs).Error; err != nil {
+		r.logger.Error("记录订单状态变更失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetTimeline 获取订单项的状态变更记录，按时间正序
+func (r *orderStatusRepository) GetTimeline(ctx context.Context, orderItemID uint64) ([]model.OrderStatusLog, error) {
+	var logs []model.OrderStatusLog
+	if err := r.DB(ctx).Where("order_item_id = ?", orderItemID).Order("id ASC").Find(&logs).Error; err != nil {
+		r.logger.Error("查询订单状态记录失败", zap.Error(err))
+		return nil, err
+	}
+	return logs, nil
+}
+
+// GetStatusBefore 获取订单项最近一次进入 status 之前的状态，用于撤销退款时恢复；tx 为空时使用默认连接
+func (r *orderStatusRepository) GetStatusBefore(ctx context.Context, tx *gorm.DB, orderItemID uint64, status uint8) (uint8, error) {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	var log model.OrderStatusLog
+	if err := tx.Where("order_item_id = ? AND to_status = ? AND from_status <> ?", orderItemID, status, status).
+		Order("id DESC").First(&log).Error; err != nil {
+		r.logger.Error("查询订单状态记录失败", zap.Error(err))
+		return 0, err
+	}
+	return log.FromStatus, nil
+}
@@ -0,0 +1,419 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "app/api/v1"
+	"app/internal/common"
+	"app/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ostrichAdvanceBatchSize 每次生长阶段任务处理的鸵鸟数量上限
+const ostrichAdvanceBatchSize = 500
+
+type OstrichRepository interface {
+	Register(ctx context.Context, tx *gorm.DB, orderItems []model.UserOrderItem) error
+	Transit(ctx context.Context, tx *gorm.DB, ostrich *model.Ostrich, to uint8, actorType uint8, actorID string, remark string) error
+	CancelByOrderItem(ctx context.Context, tx *gorm.DB, orderItemID uint64, remark string) error
+	GetUserOstriches(ctx context.Context, userID string, req model.OstrichListRequest) (*model.OstrichListResponse, error)
+	GetUserOstrichDetail(ctx context.Context, userID string, id uint64) (*model.OstrichDetailResponse, error)
+	AdvanceStages(ctx context.Context) (int, error)
+	UpdateInfo(ctx context.Context, ids []uint64, pen *string, birthDate *time.Time) error
+	ChangeStatus(ctx context.Context, id uint64, to uint8, actorID string, remark string) error
+}
+
+func NewOstrichRepository(
+	repository *Repository,
+	settingsRepository SettingsRepository,
+) OstrichRepository {
+	return &ostrichRepository{
+		Repository:         repository,
+		settingsRepository: settingsRepository,
+	}
+}
+
+type ostrichRepository struct {
+	*Repository
+	settingsRepository SettingsRepository
+}
+
+// getBreedingCategories 获取登记为种鸟的二级分类，未配置时全部登记为商品鸟
+func (r *ostrichRepository) getBreedingCategories(ctx context.Context) map[int]bool {
+	categories := make(map[int]bool)
+	value, err := r.settingsRepository.GetSettings(ctx, common.SETTINGS_ADOPT_BREEDING_CATEGORIES)
+	if err != nil || value == "" {
+		return categories
+	}
+	var ids []int
+	if err := json.Unmarshal([]byte(value), &ids); err != nil {
+		r.logger.Error("解析种鸟分类配置失败", zap.Error(err))
+		return categories
+	}
+	for _, id := range ids {
+		categories[id] = true
+	}
+	return categories
+}
+
+// Register 认养订单支付后按购买数量逐只登记鸵鸟，非认养订单项忽略；tx 为空时使用默认连接
+func (r *ostrichRepository) Register(ctx context.Context, tx *gorm.DB, orderItems []model.UserOrderItem) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	var breedingCategories map[int]bool
+	now := time.Now()
+	var ostriches []model.Ostrich
+	for _, item := range orderItems {
+		if item.Category1Id != common.CATEGORY_ADOPT {
+			continue
+		}
+		if breedingCategories == nil {
+			breedingCategories = r.getBreedingCategories(ctx)
+		}
+		ostrichType := uint8(common.OSTRICH_TYPE_COMMODITY)
+		if breedingCategories[item.Category2Id] {
+			ostrichType = common.OSTRICH_TYPE_BREEDING
+		}
+		for i := 0; i < item.Quantity; i++ {
+			ostriches = append(ostriches, model.Ostrich{
+				TagNo:       fmt.Sprintf("OS%d-%03d", item.ID, i+1),
+				UserID:      item.UserId,
+				OrderID:     item.OrderID,
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				Image:       item.HeaderImg,
+				Type:        ostrichType,
+				FarmID:      item.StoreID,
+				FarmName:    item.StoreName,
+				Status:      common.OSTRICH_STATUS_CHICK,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			})
+		}
+	}
+	if len(ostriches) == 0 {
+		return nil
+	}
+	if err := tx.Create(&ostriches).Error; err != nil {
+		r.logger.Error("登记认养鸵鸟失败", zap.Error(err))
+		return err
+	}
+
+	logs := make([]model.OstrichStatusLog, 0, len(ostriches))
+	for _, ostrich := range ostriches {
+		logs = append(logs, model.OstrichStatusLog{
+			OstrichID:  ostrich.ID,
+			FromStatus: common.OSTRICH_STATUS_CHICK,
+			ToStatus:   common.OSTRICH_STATUS_CHICK,
+			ActorType:  common.ORDER_ACTOR_SYSTEM,
+			Remark:     "认养登记",
+			CreatedAt:  now,
+		})
+	}
+	if err := tx.Create(&logs).Error; err != nil {
+		r.logger.Error("记录鸵鸟状态变更失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Transit 按状态机变更鸵鸟状态，并记录变更日志；tx 为空时使用默认连接
+func (r *ostrichRepository) Transit(ctx context.Context, tx *gorm.DB, ostrich *model.Ostrich, to uint8, actorType uint8, actorID string, remark string) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	from := ostrich.Status
+	if !common.CanTransitOstrichStatus(from, to, actorType) {
+		r.logger.Info("非法的鸵鸟状态变更",
+			zap.Uint64("ostrich_id", ostrich.ID),
+			zap.Uint8("from", from),
+			zap.Uint8("to", to),
+			zap.Uint8("actor_type", actorType),
+		)
+		return v1.ErrOrderStatusIllegal
+	}
+
+	now := time.Now()
+	// 以当前状态为条件更新，防止并发下重复变更
+	result := tx.Model(&model.Ostrich{}).
+		Where("id = ? AND status = ?", ostrich.ID, from).
+		Updates(map[string]interface{}{
+			"status":     to,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		r.logger.Error("更新鸵鸟状态失败", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("鸵鸟状态已变更")
+	}
+
+	log := model.OstrichStatusLog{
+		OstrichID:  ostrich.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorType:  actorType,
+		ActorID:    actorID,
+		Remark:     remark,
+		CreatedAt:  now,
+	}
+	if err := tx.Create(&log).Error; err != nil {
+		r.logger.Error("记录鸵鸟状态变更失败", zap.Error(err))
+		return err
+	}
+	ostrich.Status = to
+	return nil
+}
+
+// CancelByOrderItem 订单项退款完成后退订登记的鸵鸟，已出售或已死亡的保持不变
+func (r *ostrichRepository) CancelByOrderItem(ctx context.Context, tx *gorm.DB, orderItemID uint64, remark string) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	var ostriches []model.Ostrich
+	if err := tx.Where("order_item_id = ? AND status IN ?", orderItemID, []uint8{
+		common.OSTRICH_STATUS_CHICK,
+		common.OSTRICH_STATUS_JUVENILE,
+		common.OSTRICH_STATUS_ADULT,
+	}).Find(&ostriches).Error; err != nil {
+		r.logger.Error("查询订单项的鸵鸟失败", zap.Error(err))
+		return err
+	}
+	for i := range ostriches {
+		if err := r.Transit(ctx, tx, &ostriches[i], common.OSTRICH_STATUS_CANCELLED, common.ORDER_ACTOR_SYSTEM, "", remark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUserOstriches 分页获取用户认养的鸵鸟，按登记时间倒序
+func (r *ostrichRepository) GetUserOstriches(ctx context.Context, userID string, req model.OstrichListRequest) (*model.OstrichListResponse, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	query := r.DB(ctx).Model(&model.Ostrich{}).Where("user_id = ?", userID)
+	if req.Status > 0 {
+		query = query.Where("status = ?", req.Status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("查询鸵鸟总数失败", zap.Error(err))
+		return nil, err
+	}
+
+	var ostriches []model.Ostrich
+	if err := query.Order("id DESC").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&ostriches).Error; err != nil {
+		r.logger.Error("查询鸵鸟列表失败", zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	list := make([]model.OstrichItem, 0, len(ostriches))
+	for _, ostrich := range ostriches {
+		list = append(list, toOstrichItem(ostrich, now))
+	}
+	return &model.OstrichListResponse{
+		Total: total,
+		List:  list,
+		Page:  page,
+		Size:  pageSize,
+	}, nil
+}
+
+// GetUserOstrichDetail 获取用户认养鸵鸟的详情和状态时间线
+func (r *ostrichRepository) GetUserOstrichDetail(ctx context.Context, userID string, id uint64) (*model.OstrichDetailResponse, error) {
+	var ostrich model.Ostrich
+	if err := r.DB(ctx).Where("id = ? AND user_id = ?", id, userID).First(&ostrich).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("鸵鸟不存在")
+		}
+		r.logger.Error("查询鸵鸟失败", zap.Error(err))
+		return nil, err
+	}
+
+	var orderNo string
+	if err := r.DB(ctx).Model(&model.UserOrderItem{}).Where("id = ?", ostrich.OrderItemID).
+		Select("order_no").Scan(&orderNo).Error; err != nil {
+		r.logger.Error("查询鸵鸟订单号失败", zap.Error(err))
+		return nil, err
+	}
+
+	var logs []model.OstrichStatusLog
+	if err := r.DB(ctx).Where("ostrich_id = ?", ostrich.ID).Order("id ASC").Find(&logs).Error; err != nil {
+		r.logger.Error("查询鸵鸟状态记录失败", zap.Error(err))
+		return nil, err
+	}
+	timeline := make([]model.OstrichTimelineDTO, 0, len(logs))
+	for _, log := range logs {
+		timeline = append(timeline, model.OstrichTimelineDTO{
+			Status:     log.ToStatus,
+			StatusText: getOstrichStatusText(log.ToStatus),
+			ActorType:  log.ActorType,
+			Remark:     log.Remark,
+			CreatedAt:  log.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return &model.OstrichDetailResponse{
+		OstrichItem: toOstrichItem(ostrich, time.Now()),
+		OrderNo:     orderNo,
+		Timeline:    timeline,
+	}, nil
+}
+
+// AdvanceStages 按日龄将雏鸟转为育成期、育成期转为成年，返回变更的数量
+func (r *ostrichRepository) AdvanceStages(ctx context.Context) (int, error) {
+	today := startOfDay(time.Now())
+	stages := []struct {
+		from, to uint8
+		days     int
+		remark   string
+	}{
+		{common.OSTRICH_STATUS_CHICK, common.OSTRICH_STATUS_JUVENILE, common.OSTRICH_JUVENILE_DAYS, "进入育成期"},
+		{common.OSTRICH_STATUS_JUVENILE, common.OSTRICH_STATUS_ADULT, common.OSTRICH_ADULT_DAYS, "成年"},
+	}
+	advanced := 0
+	for _, stage := range stages {
+		var ostriches []model.Ostrich
+		if err := r.DB(ctx).
+			Where("status = ? AND COALESCE(birth_date, DATE(created_at)) <= ?", stage.from, today.AddDate(0, 0, -stage.days)).
+			Order("id ASC").Limit(ostrichAdvanceBatchSize).Find(&ostriches).Error; err != nil {
+			r.logger.Error("查询待变更生长阶段的鸵鸟失败", zap.Error(err))
+			return advanced, err
+		}
+		for i := range ostriches {
+			if err := r.Transit(ctx, nil, &ostriches[i], stage.to, common.ORDER_ACTOR_SYSTEM, "", stage.remark); err != nil {
+				r.logger.Error("变更鸵鸟生长阶段失败", zap.Uint64("ostrich_id", ostriches[i].ID), zap.Error(err))
+				continue
+			}
+			advanced++
+		}
+	}
+	return advanced, nil
+}
+
+// UpdateInfo 后台补录鸵鸟的栏舍和出生日期，参数为空表示不修改
+func (r *ostrichRepository) UpdateInfo(ctx context.Context, ids []uint64, pen *string, birthDate *time.Time) error {
+	updates := map[string]interface{}{}
+	if pen != nil {
+		updates["pen"] = *pen
+	}
+	if birthDate != nil {
+		if birthDate.After(time.Now()) {
+			return errors.New("出生日期不合法")
+		}
+		updates["birth_date"] = *birthDate
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	updates["updated_at"] = time.Now()
+
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		var count int64
+		if err := r.DB(ctx).Model(&model.Ostrich{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			r.logger.Error("查询鸵鸟失败", zap.Error(err))
+			return err
+		}
+		if count != int64(len(ids)) {
+			return errors.New("鸵鸟不存在")
+		}
+		if err := r.DB(ctx).Model(&model.Ostrich{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+			r.logger.Error("补录鸵鸟信息失败", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// ChangeStatus 后台按状态机变更鸵鸟状态，如出售、死亡
+func (r *ostrichRepository) ChangeStatus(ctx context.Context, id uint64, to uint8, actorID string, remark string) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		var ostrich model.Ostrich
+		if err := r.DB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).First(&ostrich).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("鸵鸟不存在")
+			}
+			r.logger.Error("查询鸵鸟失败", zap.Error(err))
+			return err
+		}
+		return r.Transit(ctx, r.DB(ctx), &ostrich, to, common.ORDER_ACTOR_ADMIN, actorID, remark)
+	})
+}
+
+// toOstrichItem 转换为鸵鸟信息，日龄按出生日期计算，未补录出生日期时按登记时间计算
+func toOstrichItem(ostrich model.Ostrich, now time.Time) model.OstrichItem {
+	born := ostrich.CreatedAt
+	if ostrich.BirthDate != nil {
+		born = *ostrich.BirthDate
+	}
+	ageDays := int(startOfDay(now).Sub(startOfDay(born)).Hours() / 24)
+	if ageDays < 0 {
+		ageDays = 0
+	}
+	return model.OstrichItem{
+		ID:          ostrich.ID,
+		TagNo:       ostrich.TagNo,
+		OrderItemID: ostrich.OrderItemID,
+		ProductID:   ostrich.ProductID,
+		ProductName: ostrich.ProductName,
+		Image:       ostrich.Image,
+		Type:        ostrich.Type,
+		TypeText:    getOstrichTypeText(ostrich.Type),
+		FarmName:    ostrich.FarmName,
+		Pen:         ostrich.Pen,
+		BirthDate:   ostrich.BirthDate,
+		AgeDays:     ageDays,
+		Status:      ostrich.Status,
+		StatusText:  getOstrichStatusText(ostrich.Status),
+		CreatedAt:   ostrich.CreatedAt,
+	}
+}
+
+// getOstrichTypeText 获取鸵鸟类型文本
+func getOstrichTypeText(ostrichType uint8) string {
+	if ostrichType == common.OSTRICH_TYPE_BREEDING {
+		return "种鸟"
+	}
+	return "商品鸟"
+}
+
+// getOstrichStatusText 获取鸵鸟状态文本
+func getOstrichStatusText(status uint8) string {
+	switch status {
+	case common.OSTRICH_STATUS_CHICK:
+		return "雏鸟"
+	case common.OSTRICH_STATUS_JUVENILE:
+		return "育成期"
+	case common.OSTRICH_STATUS_ADULT:
+		return "成年"
+	case common.OSTRICH_STATUS_SOLD:
+		return "已出售"
+	case common.OSTRICH_STATUS_DECEASED:
+		return "已死亡"
+	case common.OSTRICH_STATUS_CANCELLED:
+		return "已退订"
+	default:
+		return "未知状态"
+	}
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"app/internal/common"
+	"app/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository interface {
+	SaveTransaction(ctx context.Context, transaction *model.PaymentTransaction) error
+	GetTransactionByOutTradeNo(ctx context.Context, outTradeNo string) (*model.PaymentTransaction, error)
+	MarkPaid(ctx context.Context, outTradeNo string, transactionID string, paidAt time.Time) (bool, error)
+	GetRefundIDByRefundNo(ctx context.Context, refundNo string) (uint64, error)
+	MarkRefunding(ctx context.Context, outTradeNo string, refundNo string) error
+	MarkRefunded(ctx context.Context, refundNo string) (bool, error)
+}
+
+func NewPaymentRepository(
+	repository *Repository,
+) PaymentRepository {
+	return &paymentRepository{
+		Repository: repository,
+	}
+}
+
+type paymentRepository struct {
+	*Repository
+}
+
+// SaveTransaction 保存支付流水，同一商户订单号重复下单时只更新预支付信息
+func (r *paymentRepository) SaveTransaction(ctx context.Context, transaction *model.PaymentTransaction) error {
+	err := r.DB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "out_trade_no"}},
+		DoUpdates: clause.AssignmentColumns([]string{"provider", "amount", "prepay_id", "updated_at"}),
+	}).Create(transaction).Error
+	if err != nil {
+		r.logger.Error("保存支付流水失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetTransactionByOutTradeNo 按商户订单号查询支付流水，不存在时返回 nil
+func (r *paymentRepository) GetTransactionByOutTradeNo(ctx context.Context, outTradeNo string) (*model.PaymentTransaction, error) {
+	var transaction model.PaymentTransaction
+	if err := r.DB(ctx).Where("out_trade_no = ?", outTradeNo).First(&transaction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("查询支付流水失败", zap.Error(err))
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// MarkPaid 将待支付的流水标记为已支付，返回是否由本次调用完成变更
+func (r *paymentRepository) MarkPaid(ctx context.Context, outTradeNo string, transactionID string, paidAt time.Time) (bool, error) {
+	result := r.DB(ctx).Model(&model.PaymentTransaction{}).
+		Where("out_trade_no = ? AND status = ?", outTradeNo, common.PAYMENT_STATUS_PENDING).
+		Updates(map[string]interface{}{
+			"status":         common.PAYMENT_STATUS_PAID,
+			"transaction_id": transactionID,
+			"paid_at":        paidAt,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		r.logger.Error("更新支付流水失败", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetRefundIDByRefundNo 按退款单号查询退款单ID
+func (r *paymentRepository) GetRefundIDByRefundNo(ctx context.Context, refundNo string) (uint64, error) {
+	var refundOrder model.RefundOrder
+	if err := r.DB(ctx).Select("id").Where("refund_no = ?", refundNo).First(&refundOrder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("退款单不存在")
+		}
+		r.logger.Error("查询退款单失败", zap.Error(err))
+		return 0, err
+	}
+	return refundOrder.ID, nil
+}
+
+// MarkRefunding 订单已失效但收到支付时，将已支付的流水标记为待退回并记录退款单号，供对账和后台跟进
+func (r *paymentRepository) MarkRefunding(ctx context.Context, outTradeNo string, refundNo string) error {
+	if err := r.DB(ctx).Model(&model.PaymentTransaction{}).
+		Where("out_trade_no = ? AND status = ?", outTradeNo, common.PAYMENT_STATUS_PAID).
+		Updates(map[string]interface{}{
+			"status":     common.PAYMENT_STATUS_REFUNDING,
+			"refund_no":  refundNo,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		r.logger.Error("更新支付流水失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// MarkRefunded 将待退回的流水标记为已退回，返回退款单号是否属于待退回的流水
+func (r *paymentRepository) MarkRefunded(ctx context.Context, refundNo string) (bool, error) {
+	if refundNo == "" {
+		return false, nil
+	}
+	var transaction model.PaymentTransaction
+	if err := r.DB(ctx).Where("refund_no = ?", refundNo).First(&transaction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		r.logger.Error("查询支付流水失败", zap.Error(err))
+		return false, err
+	}
+	if err := r.DB(ctx).Model(&model.PaymentTransaction{}).
+		Where("id = ? AND status = ?", transaction.ID, common.PAYMENT_STATUS_REFUNDING).
+		Updates(map[string]interface{}{
+			"status":     common.PAYMENT_STATUS_REFUNDED,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		r.logger.Error("更新支付流水失败", zap.Error(err))
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"app/internal/common"
+	"app/internal/model"
+)
+
+// maxPayoutAccounts 每个用户最多绑定的收款账户数
+const maxPayoutAccounts = 10
+
+type PayoutAccountRepository interface {
+	BindPayoutAccount(ctx context.Context, userID string, req model.BindPayoutAccountRequest) error
+	GetPayoutAccountList(ctx context.Context, userID string) ([]model.PayoutAccountItem, error)
+	DeletePayoutAccount(ctx context.Context, userID string, accountID uint64) error
+	GetPayoutAccount(ctx context.Context, userID string, accountID uint64) (*model.PayoutAccount, error)
+}
+
+func NewPayoutAccountRepository(repository *Repository) PayoutAccountRepository {
+	return &payoutAccountRepository{
+		Repository: repository,
+	}
+}
+
+type payoutAccountRepository struct {
+	*Repository
+}
+
+// BindPayoutAccount 绑定收款账户，户名和账号加密后存储；第一个绑定的账户自动设为默认
+func (r *payoutAccountRepository) BindPayoutAccount(ctx context.Context, userID string, req model.BindPayoutAccountRequest) error {
+	accountNo := strings.TrimSpace(req.AccountNo)
+	switch req.Type {
+	case common.PAYOUT_TYPE_BANK:
+		if req.BankName == "" || accountNo == "" {
+			return errors.New("请填写银行名称和卡号")
+		}
+	case common.PAYOUT_TYPE_WECHAT:
+		var openID string
+		if err := r.DB(ctx).Model(&model.Account{}).Where("user_id = ?", userID).
+			Select("open_id").Scan(&openID).Error; err != nil {
+			r.logger.Error("查询用户openid失败", zap.Error(err))
+			return err
+		}
+		if openID == "" {
+			return errors.New("未绑定微信，无法提现到微信零钱")
+		}
+		req.BankName, accountNo = "", openID
+	default:
+		return errors.New("收款方式不合法")
+	}
+
+	encryptedName, err := common.AesEncrypt(strings.TrimSpace(req.AccountName))
+	if err != nil {
+		r.logger.Error("加密收款户名失败", zap.Error(err))
+		return err
+	}
+	encryptedNo, err := common.AesEncrypt(accountNo)
+	if err != nil {
+		r.logger.Error("加密收款账号失败", zap.Error(err))
+		return err
+	}
+
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		var accounts []model.PayoutAccount
+		if err := r.DB(ctx).Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+			r.logger.Error("查询收款账户失败", zap.Error(err))
+			return err
+		}
+		if len(accounts) >= maxPayoutAccounts {
+			return errors.New("收款账户数量已达上限")
+		}
+		// 加密结果是确定的，密文相同即为同一账号
+		for _, account := range accounts {
+			if account.Type == req.Type && account.AccountNo == encryptedNo {
+				return errors.New("该收款账户已绑定")
+			}
+		}
+
+		account := model.PayoutAccount{
+			UserID:      userID,
+			Type:        req.Type,
+			BankName:    req.BankName,
+			AccountName: encryptedName,
+			AccountNo:   encryptedNo,
+		}
+		if req.IsDefault || len(accounts) == 0 {
+			account.IsDefault = 1
+			if err := r.DB(ctx).Model(&model.PayoutAccount{}).Where("user_id = ?", userID).
+				Updates(map[string]interface{}{"is_default": 0, "updated_at": time.Now()}).Error; err != nil {
+				r.logger.Error("取消默认收款账户失败", zap.Error(err))
+				return err
+			}
+		}
+		if err := r.DB(ctx).Create(&account).Error; err != nil {
+			r.logger.Error("绑定收款账户失败", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// GetPayoutAccountList 获取收款账户列表，默认账户在前，户名和账号脱敏
+func (r *payoutAccountRepository) GetPayoutAccountList(ctx context.Context, userID string) ([]model.PayoutAccountItem, error) {
+	var accounts []model.PayoutAccount
+	if err := r.DB(ctx).Where("user_id = ?", userID).Order("is_default DESC, id DESC").Find(&accounts).Error; err != nil {
+		r.logger.Error("查询收款账户失败", zap.Error(err))
+		return nil, err
+	}
+	list := make([]model.PayoutAccountItem, 0, len(accounts))
+	for _, account := range accounts {
+		list = append(list, model.PayoutAccountItem{
+			ID:          account.ID,
+			Type:        account.Type,
+			BankName:    account.BankName,
+			AccountName: maskAccountName(decryptAccountField(account.AccountName)),
+			AccountNo:   maskAccountNo(decryptAccountField(account.AccountNo)),
+			IsDefault:   account.IsDefault,
+		})
+	}
+	return list, nil
+}
+
+// DeletePayoutAccount 删除收款账户，已提交的提现单保留收款信息快照，不受影响
+func (r *payoutAccountRepository) DeletePayoutAccount(ctx context.Context, userID string, accountID uint64) error {
+	result := r.DB(ctx).Where("id = ? AND user_id = ?", accountID, userID).Delete(&model.PayoutAccount{})
+	if result.Error != nil {
+		r.logger.Error("删除收款账户失败", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("收款账户不存在")
+	}
+	return nil
+}
+
+// GetPayoutAccount 获取用户的收款账户，户名和账号为密文
+func (r *payoutAccountRepository) GetPayoutAccount(ctx context.Context, userID string, accountID uint64) (*model.PayoutAccount, error) {
+	var account model.PayoutAccount
+	if err := r.DB(ctx).Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("收款账户不存在")
+		}
+		r.logger.Error("查询收款账户失败", zap.Error(err))
+		return nil, err
+	}
+	return &account, nil
+}
+
+// decryptAccountField 解密收款户名或账号，解密失败时返回空字符串，不展示密文
+func decryptAccountField(cipherText string) string {
+	if cipherText == "" {
+		return ""
+	}
+	plainText, err := common.AesDecryptText(cipherText)
+	if err != nil {
+		return ""
+	}
+	return plainText
+}
+
+// maskAccountName 户名只展示最后一个字，如 **三
+func maskAccountName(name string) string {
+	runes := []rune(name)
+	if len(runes) <= 1 {
+		return name
+	}
+	return strings.Repeat("*", len(runes)-1) + string(runes[len(runes)-1])
+}
+
+// maskAccountNo 账号只展示后四位，如 **** 1234
+func maskAccountNo(accountNo string) string {
+	if len(accountNo) <= 4 {
+		return accountNo
+	}
+	return "**** " + accountNo[len(accountNo)-4:]
+}
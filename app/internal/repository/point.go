@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"app/internal/common"
+	"app/internal/model"
+)
+
+// pointExpireBatchSize 每次处理的过期积分批次数
+const pointExpireBatchSize = 500
+
+// PointRepository 积分规则引擎：按 sys_params 中的规则发放积分，并处理积分过期
+// 积分变动都通过 LedgerRepository 记账，批次由记账时维护
+type PointRepository interface {
+	GetPointRules(ctx context.Context) (*model.PointRules, error)
+	AwardOrderPoints(ctx context.Context, tx *gorm.DB, orderItem *model.UserOrderItem) error
+	AwardReviewPoints(ctx context.Context, tx *gorm.DB, review *model.ProductReview) error
+	CheckIn(ctx context.Context, userID string) (*model.CheckInResponse, error)
+	GetPointLots(ctx context.Context, userID string) (*model.PointLotListResponse, error)
+	ExpirePoints(ctx context.Context) (int, error)
+	RemindExpiringPoints(ctx context.Context) ([]model.PointExpiringNotice, error)
+	MarkPointsReminded(ctx context.Context, lotIDs []uint64) error
+}
+
+func NewPointRepository(
+	repository *Repository,
+	settingsRepository SettingsRepository,
+	ledgerRepository LedgerRepository,
+) PointRepository {
+	return &pointRepository{
+		Repository:         repository,
+		settingsRepository: settingsRepository,
+		ledgerRepository:   ledgerRepository,
+	}
+}
+
+type pointRepository struct {
+	*Repository
+	settingsRepository SettingsRepository
+	ledgerRepository   LedgerRepository
+}
+
+// GetPointRules 获取积分规则，未配置时按每消费10元得1积分发放订单积分，积分不过期
+func (r *pointRepository) GetPointRules(ctx context.Context) (*model.PointRules, error) {
+	rules := &model.PointRules{DefaultOrderRatio: 0.1}
+	value, err := r.settingsRepository.GetSettings(ctx, common.SETTINGS_POINT_RULES)
+	if err != nil || value == "" {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(value), rules); err != nil {
+		r.logger.Error("解析积分规则失败", zap.Error(err))
+		return nil, err
+	}
+	return rules, nil
+}
+
+// AwardOrderPoints 订单确认收货后按会员等级的比例发放订单积分，用户第一笔订单额外发放首次购买积分
+func (r *pointRepository) AwardOrderPoints(ctx context.Context, tx *gorm.DB, orderItem *model.UserOrderItem) error {
+	rules, err := r.GetPointRules(ctx)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+
+	var role int
+	if err := tx.Model(&model.Account{}).Where("user_id = ?", orderItem.UserId).Select("role").Scan(&role).Error; err != nil {
+		r.logger.Error("查询用户会员等级失败", zap.Error(err))
+		return err
+	}
+	ratio := rules.DefaultOrderRatio
+	for _, orderRatio := range rules.OrderRatios {
+		if orderRatio.Role == role {
+			ratio = orderRatio.Ratio
+			break
+		}
+	}
+	points := int64(float64(orderItem.TotalFee.Fen()) * ratio / 100)
+	if err := r.award(ctx, tx, orderItem.UserId, common.BUSINESS_TYPE_ORDER, int(orderItem.ID), orderItem.ProductName, points, rules); err != nil {
+		return err
+	}
+
+	if rules.FirstPurchasePoints <= 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&model.UserAssetRecord{}).
+		Where("user_id = ? AND business_type = ? AND account_type = ?", orderItem.UserId,
+			common.BUSINESS_TYPE_FIRST_PURCHASE, common.LEDGER_ACCOUNT_USER).
+		Count(&count).Error; err != nil {
+		r.logger.Error("查询首次购买积分失败", zap.Error(err))
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return r.award(ctx, tx, orderItem.UserId, common.BUSINESS_TYPE_FIRST_PURCHASE, int(orderItem.ID), "首次购买",
+		int64(rules.FirstPurchasePoints), rules)
+}
+
+// AwardReviewPoints 带图评价发放晒图积分，每个订单项只发放一次
+func (r *pointRepository) AwardReviewPoints(ctx context.Context, tx *gorm.DB, review *model.ProductReview) error {
+	if review.Images == "" {
+		return nil
+	}
+	rules, err := r.GetPointRules(ctx)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	return r.award(ctx, tx, review.UserID, common.BUSINESS_TYPE_REVIEW, int(review.OrderItemID), "晒图评价",
+		int64(rules.ReviewPhotoPoints), rules)
+}
+
+// CheckIn 每日签到，同一天重复签到返回错误
+func (r *pointRepository) CheckIn(ctx context.Context, userID string) (*model.CheckInResponse, error) {
+	rules, err := r.GetPointRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checkIn := model.PointCheckIn{
+		UserID:      userID,
+		CheckInDate: time.Now().Format("2006-01-02"),
+		Points:      rules.CheckInPoints,
+	}
+	err = r.Transaction(ctx, func(ctx context.Context) error {
+		var count int64
+		if err := r.DB(ctx).Model(&model.PointCheckIn{}).
+			Where("user_id = ? AND check_in_date = ?", userID, checkIn.CheckInDate).Count(&count).Error; err != nil {
+			r.logger.Error("查询签到记录失败", zap.Error(err))
+			return err
+		}
+		if count > 0 {
+			return errors.New("今日已签到")
+		}
+		// 并发签到时由唯一索引保证只成功一次
+		if err := r.DB(ctx).Create(&checkIn).Error; err != nil {
+			r.logger.Error("创建签到记录失败", zap.Error(err))
+			return errors.New("今日已签到")
+		}
+		return r.award(ctx, r.DB(ctx), userID, common.BUSINESS_TYPE_CHECK_IN, int(checkIn.ID), "每日签到",
+			int64(rules.CheckInPoints), rules)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &model.CheckInResponse{
+		CheckInDate: checkIn.CheckInDate,
+		Points:      checkIn.Points,
+	}, nil
+}
+
+// GetPointLots 获取用户未用完的积分批次，按过期时间先后排序
+func (r *pointRepository) GetPointLots(ctx context.Context, userID string) (*model.PointLotListResponse, error) {
+	rules, err := r.GetPointRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var lots []model.PointLot
+	if err := r.DB(ctx).Where("user_id = ? AND remaining > 0", userID).
+		Order("expire_at IS NULL, expire_at ASC, id ASC").Find(&lots).Error; err != nil {
+		r.logger.Error("查询积分批次失败", zap.Error(err))
+		return nil, err
+	}
+	response := &model.PointLotListResponse{List: make([]model.PointLotItem, 0, len(lots))}
+	remindBefore := time.Now().AddDate(0, 0, rules.RemindDays)
+	for _, lot := range lots {
+		response.Points += lot.Remaining
+		if lot.ExpireAt != nil && lot.ExpireAt.Before(remindBefore) {
+			response.ExpiringPoints += lot.Remaining
+		}
+		response.List = append(response.List, model.PointLotItem{
+			ID:           lot.ID,
+			BusinessType: lot.BusinessType,
+			Points:       lot.Points,
+			Remaining:    lot.Remaining,
+			ExpireAt:     lot.ExpireAt,
+			CreatedAt:    lot.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// ExpirePoints 扣除已过期批次的剩余积分，返回处理的批次数；单个批次失败不影响其他批次
+func (r *pointRepository) ExpirePoints(ctx context.Context) (int, error) {
+	var lots []model.PointLot
+	if err := r.DB(ctx).Where("expire_at <= ? AND remaining > 0", time.Now()).
+		Order("id ASC").Limit(pointExpireBatchSize).Find(&lots).Error; err != nil {
+		r.logger.Error("查询过期积分批次失败", zap.Error(err))
+		return 0, err
+	}
+	expired := 0
+	for _, lot := range lots {
+		if err := r.expireLot(ctx, lot); err != nil {
+			r.logger.Error("积分过期处理失败", zap.Uint64("lot_id", lot.ID), zap.Error(err))
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// expireLot 与积分扣减一致，先锁定用户资产再读取批次，避免加锁顺序不同导致死锁
+func (r *pointRepository) expireLot(ctx context.Context, lot model.PointLot) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.DB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", lot.UserID).First(&model.UserAsset{}).Error; err != nil {
+			return err
+		}
+		if err := r.DB(ctx).Where("id = ?", lot.ID).First(&lot).Error; err != nil {
+			return err
+		}
+		if lot.Remaining <= 0 {
+			return nil
+		}
+		return r.ledgerRepository.Post(ctx, r.DB(ctx), model.LedgerEntry{
+			UserID:        lot.UserID,
+			BusinessType:  common.BUSINESS_TYPE_POINT_EXPIRE,
+			ActionType:    common.ACTION_TYPE_EXPIRE,
+			AssetType:     common.ASSET_TYPE_POINT,
+			Amount:        -lot.Remaining,
+			RelationID:    int(lot.ID),
+			RelationTitle: "积分过期",
+			PointLotID:    lot.ID,
+		})
+	})
+}
+
+// RemindExpiringPoints 汇总提醒期内即将过期且未提醒过的积分，按用户返回提醒内容
+// 推送成功后由调用方通过 MarkPointsReminded 标记已提醒，推送失败的批次下次任务仍会提醒
+func (r *pointRepository) RemindExpiringPoints(ctx context.Context) ([]model.PointExpiringNotice, error) {
+	rules, err := r.GetPointRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rules.ExpireDays <= 0 || rules.RemindDays <= 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	var lots []model.PointLot
+	if err := r.DB(ctx).Where("expire_at > ? AND expire_at <= ? AND remaining > 0 AND reminded = 0",
+		now, now.AddDate(0, 0, rules.RemindDays)).
+		Order("expire_at ASC").Limit(pointExpireBatchSize).Find(&lots).Error; err != nil {
+		r.logger.Error("查询即将过期积分失败", zap.Error(err))
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, nil
+	}
+
+	noticeIndex := make(map[string]int)
+	notices := make([]model.PointExpiringNotice, 0)
+	for _, lot := range lots {
+		i, ok := noticeIndex[lot.UserID]
+		if !ok {
+			// 批次按过期时间排序，第一个批次即最早过期时间
+			i = len(notices)
+			noticeIndex[lot.UserID] = i
+			notices = append(notices, model.PointExpiringNotice{
+				Module:   common.MODULE_TYPE_POINT,
+				UserID:   lot.UserID,
+				ExpireAt: *lot.ExpireAt,
+			})
+		}
+		notices[i].LotIDs = append(notices[i].LotIDs, lot.ID)
+		notices[i].Points += lot.Remaining
+	}
+	for i := range notices {
+		notices[i].Message = fmt.Sprintf("您有%d积分将于%s起陆续过期，请尽快使用",
+			notices[i].Points, notices[i].ExpireAt.Format("2006-01-02"))
+	}
+	return notices, nil
+}
+
+// MarkPointsReminded 将已推送提醒的积分批次标记为已提醒
+func (r *pointRepository) MarkPointsReminded(ctx context.Context, lotIDs []uint64) error {
+	if len(lotIDs) == 0 {
+		return nil
+	}
+	if err := r.DB(ctx).Model(&model.PointLot{}).Where("id IN ?", lotIDs).Updates(map[string]interface{}{
+		"reminded":   1,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		r.logger.Error("标记积分过期提醒失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// award 发放积分并按规则设置过期时间，同一业务单据只发放一次
+func (r *pointRepository) award(ctx context.Context, tx *gorm.DB, userID string, businessType int8, relationID int,
+	title string, points int64, rules *model.PointRules) error {
+	if points <= 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&model.UserAssetRecord{}).
+		Where("user_id = ? AND business_type = ? AND relation_id = ? AND asset_type = ? AND account_type = ?",
+			userID, businessType, relationID, common.ASSET_TYPE_POINT, common.LEDGER_ACCOUNT_USER).
+		Count(&count).Error; err != nil {
+		r.logger.Error("查询积分发放记录失败", zap.Error(err))
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	entry := model.LedgerEntry{
+		UserID:        userID,
+		BusinessType:  businessType,
+		ActionType:    common.ACTION_TYPE_REWARD,
+		AssetType:     common.ASSET_TYPE_POINT,
+		Amount:        points,
+		RelationID:    relationID,
+		RelationTitle: title,
+	}
+	if rules.ExpireDays > 0 {
+		expireAt := time.Now().AddDate(0, 0, rules.ExpireDays)
+		entry.ExpireAt = &expireAt
+	}
+	return r.ledgerRepository.Post(ctx, tx, entry)
+}
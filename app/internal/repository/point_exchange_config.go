@@ -3,7 +3,6 @@ package repository
 import (
 	"app/internal/model"
 	"context"
-	"fmt"
 	"time"
 
 	"app/internal/common"
@@ -14,14 +13,16 @@ type PointExchangeConfigRepository interface {
 	ExchangePoints(ctx context.Context, userID string, configID uint64) error
 }
 
-func NewPointExchangeConfigRepository(repository *Repository) PointExchangeConfigRepository {
+func NewPointExchangeConfigRepository(repository *Repository, ledgerRepository LedgerRepository) PointExchangeConfigRepository {
 	return &pointExchangeConfigRepository{
-		Repository: repository,
+		Repository:       repository,
+		ledgerRepository: ledgerRepository,
 	}
 }
 
 type pointExchangeConfigRepository struct {
 	*Repository
+	ledgerRepository LedgerRepository
 }
 
 func (r *pointExchangeConfigRepository) GetPointExchangeConfigList(ctx context.Context, userID string) (*model.PointExchangeConfigListResponse, error) {
@@ -85,46 +86,24 @@ func (r *pointExchangeConfigRepository) ExchangePoints(ctx context.Context, user
 		return err
 	}
 
-	// 获取用户资产
-	var userAsset model.UserAsset
-	if err := r.DB(ctx).Where("user_id = ?", userID).First(&userAsset).Error; err != nil {
-		r.logger.Error("获取用户资产失败: " + err.Error())
-		return err
-	}
-
-	// 检查用户积分是否足够
-	if userAsset.Points < config.Points {
-		return fmt.Errorf("积分不足")
-	}
-
 	// 开始事务
 	tx := r.DB(ctx).Begin()
 
-	// 扣除用户积分
-	if err := tx.Model(&model.UserAsset{}).Where("user_id = ?", userID).
-		Update("points", userAsset.Points-config.Points).Error; err != nil {
+	// 扣除用户积分，积分不足时回滚
+	if err := r.ledgerRepository.Post(ctx, tx, model.LedgerEntry{
+		UserID:        userID,
+		BusinessType:  common.BUSINESS_TYPE_EXCHANGE,
+		ActionType:    common.ACTION_TYPE_EXCHANGE,
+		AssetType:     common.ASSET_TYPE_POINT,
+		Amount:        -int64(config.Points),
+		RelationID:    int(config.ID),
+		RelationTitle: config.Title,
+	}); err != nil {
 		tx.Rollback()
 		r.logger.Error("扣除用户积分失败: " + err.Error())
 		return err
 	}
 
-	// 记录积分变动
-	assetRecord := &model.UserAssetRecord{
-		UserId:       userID,
-		BusinessType: common.BUSINESS_TYPE_EXCHANGE,
-		ActionType:   common.ACTION_TYPE_EXCHANGE,
-		AssetType:    common.ASSET_TYPE_POINT,
-		ActionNum:    float32(config.Points),
-		LeftNum:      float32(userAsset.Points - config.Points),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-	if err := tx.Create(assetRecord).Error; err != nil {
-		tx.Rollback()
-		r.logger.Error("记录积分变动失败: " + err.Error())
-		return err
-	}
-
 	// 记录积分兑换记录
 	pointExchangeRecord := &model.PointExchangeRecord{
 		UserID:         userID,
@@ -2,11 +2,18 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
 
+	v1 "app/api/v1"
+	"app/internal/common"
 	"app/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
@@ -14,6 +21,11 @@ type ProductRepository interface {
 	GetRecommendProductList(ctx context.Context) ([]*model.ProductListItemDTO, error)
 	GetProductByID(ctx context.Context, id int, userId string) (*model.ProductListItemDTO, error)
 	GetProductDetailsByCartIDs(ctx context.Context, cartIDs []uint64) ([]model.ProductListItemDTO, error)
+	ReserveStock(ctx context.Context, tx *gorm.DB, productID uint64, quantity int) error
+	CommitStock(ctx context.Context, tx *gorm.DB, productID uint64, quantity int) error
+	ReleaseStock(ctx context.Context, tx *gorm.DB, productID uint64, quantity int) error
+	RestockRefund(ctx context.Context, tx *gorm.DB, productID uint64, quantity int) error
+	UpdateStock(ctx context.Context, productID uint64, stock int) error
 }
 
 func NewProductRepository(
@@ -97,6 +109,7 @@ func (r *productRepository) GetProductList(ctx context.Context, userId string) (
 					ProductUnit:          "", // You may need to add this field to the Product model
 					ProductSpec:          product.Specification,
 					ProductSales:         product.Sales,
+					ProductStock:         product.Stock,
 					ProductSpecification: "¥" + strconv.FormatFloat(product.CourierFeeMin, 'f', -1, 64) + "-" + strconv.FormatFloat(product.CourierFeeMax, 'f', -1, 64),
 					HeaderImg:            product.HeaderImg,
 					ProductImages:        productImages,
@@ -144,6 +157,7 @@ func (r *productRepository) GetRecommendProductList(ctx context.Context) ([]*mod
 			ProductOriginalPrice: product.OriginPrice,
 			ProductUnit:          "", // You may need to add this field to the Product model
 			ProductSpec:          product.Specification,
+			ProductStock:         product.Stock,
 		})
 	}
 
@@ -156,11 +170,29 @@ func (r *productRepository) GetProductByID(ctx context.Context, id int, userId s
 	if err := r.DB(ctx).Where("id = ?", id).First(&product).Error; err != nil {
 		return nil, err
 	}
-	// Get coupons
+	// 获取适用于该商品的优惠券：全部商品、商品所在分类或指定了该商品
+	categoryIds := make([]string, 0, 2)
+	if product.Category1ID != nil {
+		categoryIds = append(categoryIds, strconv.Itoa(*product.Category1ID))
+	}
+	if product.Category2ID != nil {
+		categoryIds = append(categoryIds, strconv.Itoa(*product.Category2ID))
+	}
+	scope := r.DB(ctx).Where("scope_type = ?", common.COUPON_SCOPE_ALL).
+		Or("scope_type = ? AND FIND_IN_SET(?, scope_ids)", common.COUPON_SCOPE_PRODUCT, strconv.Itoa(int(product.ID)))
+	for _, categoryID := range categoryIds {
+		scope = scope.Or("scope_type = ? AND FIND_IN_SET(?, scope_ids)", common.COUPON_SCOPE_CATEGORY, categoryID)
+	}
 	var coupons []*model.ProductCoupon
-	if err := r.DB(ctx).Where("product_id = ?", product.ID).Find(&coupons).Error; err != nil {
+	if err := r.DB(ctx).Where(scope).
+		Where("deadline IS NULL OR deadline > ?", time.Now()).
+		Find(&coupons).Error; err != nil {
 		return nil, err
 	}
+	couponIds := make([]uint, 0, len(coupons))
+	for _, coupon := range coupons {
+		couponIds = append(couponIds, coupon.ID)
+	}
 	// Get evaluations
 	var evaluations []*model.ProductReview
 	if err := r.DB(ctx).Where("product_id = ?", product.ID).Find(&evaluations).Error; err != nil {
@@ -168,8 +200,10 @@ func (r *productRepository) GetProductByID(ctx context.Context, id int, userId s
 	}
 	// 用户是否已经领取优惠券
 	userCouponList := []*model.UserCoupon{}
-	if err := r.DB(ctx).Where("user_id = ? AND product_id = ?", userId, id).Find(&userCouponList).Error; err != nil {
-		return nil, err
+	if len(couponIds) > 0 {
+		if err := r.DB(ctx).Where("user_id = ? AND coupon_id IN ? AND type = ?", userId, couponIds, 1).Find(&userCouponList).Error; err != nil {
+			return nil, err
+		}
 	}
 	userCouponMap := make(map[uint64]uint8)
 	for _, userCoupon := range userCouponList {
@@ -191,7 +225,13 @@ func (r *productRepository) GetProductByID(ctx context.Context, id int, userId s
 			CouponID:          coupon.ID,
 			CouponName:        coupon.CouponName,
 			CouponPrice:       coupon.CouponPrice,
+			Kind:              coupon.Kind,
+			DiscountRate:      coupon.DiscountRate,
+			MaxDiscount:       coupon.MaxDiscount,
 			AvailableMinPrice: coupon.AvailableMinPrice,
+			ScopeType:         coupon.ScopeType,
+			TotalQuantity:     coupon.TotalQuantity,
+			ClaimedQuantity:   coupon.ClaimedQuantity,
 			Deadline:          deadlineStr,
 			IsReceived:        isReceived,
 		}
@@ -229,6 +269,7 @@ func (r *productRepository) GetProductByID(ctx context.Context, id int, userId s
 		ProductUnit:          "", // You may need to add this field to the Product model
 		ProductSpec:          product.Specification,
 		ProductSales:         product.Sales,
+		ProductStock:         product.Stock,
 		ProductSpecification: "¥" + strconv.FormatFloat(product.CourierFeeMin, 'f', -1, 64) + "-" + strconv.FormatFloat(product.CourierFeeMax, 'f', -1, 64),
 		HeaderImg:            product.HeaderImg,
 		ProductImages:        strings.Split(product.BannerImg, ","),
@@ -282,6 +323,7 @@ func (r *productRepository) GetProductDetailsByCartIDs(ctx context.Context, cart
 			ProductUnit:          "", // You may need to add this field to the Product model
 			ProductSpec:          product.Specification,
 			ProductSales:         product.Sales,
+			ProductStock:         product.Stock,
 			ProductSpecification: "¥" + strconv.FormatFloat(product.CourierFeeMin, 'f', -1, 64) + "-" + strconv.FormatFloat(product.CourierFeeMax, 'f', -1, 64),
 			HeaderImg:            product.HeaderImg,
 			ProductImages:        strings.Split(product.BannerImg, ","),
@@ -302,3 +344,109 @@ func (r *productRepository) GetProductDetailsByCartIDs(ctx context.Context, cart
 
 	return productsDTO, nil
 }
+
+// stockExpr 增减可售库存，不限库存的商品保持不变
+func stockExpr(delta int) clause.Expr {
+	return gorm.Expr("CASE WHEN stock = ? THEN stock ELSE stock + ? END", common.PRODUCT_STOCK_UNLIMITED, delta)
+}
+
+// ReserveStock 下单时锁定库存，库存不足时返回 ErrStockNotEnough
+func (r *productRepository) ReserveStock(ctx context.Context, tx *gorm.DB, productID uint64, quantity int) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	result := tx.Model(&model.Product{}).
+		Where("id = ? AND (stock >= ? OR stock = ?)", productID, quantity, common.PRODUCT_STOCK_UNLIMITED).
+		Updates(map[string]interface{}{
+			"stock":        stockExpr(-quantity),
+			"locked_stock": gorm.Expr("locked_stock + ?", quantity),
+		})
+	if result.Error != nil {
+		r.logger.Error("锁定库存失败", zap.Uint64("product_id", productID), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return v1.ErrStockNotEnough
+	}
+	return nil
+}
+
+// CommitStock 支付成功后扣减锁定库存并增加销量
+func (r *productRepository) CommitStock(ctx context.Context, tx *gorm.DB, productID uint64, quantity int) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	result := tx.Model(&model.Product{}).
+		Where("id = ? AND locked_stock >= ?", productID, quantity).
+		Updates(map[string]interface{}{
+			"locked_stock": gorm.Expr("locked_stock - ?", quantity),
+			"sales":        gorm.Expr("sales + ?", quantity),
+		})
+	if result.Error != nil {
+		r.logger.Error("扣减库存失败", zap.Uint64("product_id", productID), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		r.logger.Error("锁定库存不足，无法扣减", zap.Uint64("product_id", productID), zap.Int("quantity", quantity))
+		return v1.ErrStockNotEnough
+	}
+	return nil
+}
+
+// ReleaseStock 订单取消或超时后归还锁定库存
+func (r *productRepository) ReleaseStock(ctx context.Context, tx *gorm.DB, productID uint64, quantity int) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	result := tx.Model(&model.Product{}).
+		Where("id = ? AND locked_stock >= ?", productID, quantity).
+		Updates(map[string]interface{}{
+			"stock":        stockExpr(quantity),
+			"locked_stock": gorm.Expr("locked_stock - ?", quantity),
+		})
+	if result.Error != nil {
+		r.logger.Error("释放库存失败", zap.Uint64("product_id", productID), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		r.logger.Warn("锁定库存不足，忽略释放", zap.Uint64("product_id", productID), zap.Int("quantity", quantity))
+	}
+	return nil
+}
+
+// RestockRefund 已支付订单退款后归还库存并扣减销量
+func (r *productRepository) RestockRefund(ctx context.Context, tx *gorm.DB, productID uint64, quantity int) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	if err := tx.Model(&model.Product{}).
+		Where("id = ?", productID).
+		Updates(map[string]interface{}{
+			"stock": stockExpr(quantity),
+			"sales": gorm.Expr("GREATEST(sales - ?, 0)", quantity),
+		}).Error; err != nil {
+		r.logger.Error("退款归还库存失败", zap.Uint64("product_id", productID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateStock 运营设置商品的可售库存，-1表示不限库存；待支付订单锁定的库存不受影响
+func (r *productRepository) UpdateStock(ctx context.Context, productID uint64, stock int) error {
+	if stock < common.PRODUCT_STOCK_UNLIMITED {
+		return errors.New("库存不合法")
+	}
+	var count int64
+	if err := r.DB(ctx).Model(&model.Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+		r.logger.Error("查询商品失败", zap.Error(err))
+		return err
+	}
+	if count == 0 {
+		return errors.New("商品不存在")
+	}
+	if err := r.DB(ctx).Model(&model.Product{}).Where("id = ?", productID).Update("stock", stock).Error; err != nil {
+		r.logger.Error("更新商品库存失败", zap.Uint64("product_id", productID), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -6,7 +6,9 @@ import (
 	"strings"
 	"time"
 
+	"app/internal/common"
 	"app/internal/model"
+	"app/pkg/money"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -24,11 +26,15 @@ type ProductReviewRepository interface {
 
 type productReviewRepository struct {
 	*Repository
+	orderStatusRepository OrderStatusRepository
+	pointRepository       PointRepository
 }
 
-func NewProductReviewRepository(repository *Repository) ProductReviewRepository {
+func NewProductReviewRepository(repository *Repository, orderStatusRepository OrderStatusRepository, pointRepository PointRepository) ProductReviewRepository {
 	return &productReviewRepository{
-		Repository: repository,
+		Repository:            repository,
+		orderStatusRepository: orderStatusRepository,
+		pointRepository:       pointRepository,
 	}
 }
 
@@ -89,21 +95,25 @@ func (r *productReviewRepository) CreateReview(ctx context.Context, userID strin
 		PraiseNums:      0,
 	}
 
-	// 保存到数据库
-	if err := r.DB(ctx).Create(&review).Error; err != nil {
-		r.logger.Error("创建评价失败", zap.Error(err))
-		return err
-	}
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		// 保存到数据库
+		if err := r.DB(ctx).Create(&review).Error; err != nil {
+			r.logger.Error("创建评价失败", zap.Error(err))
+			return err
+		}
 
-	// 更新订单项状态
-	if err := r.DB(ctx).Model(&model.UserOrderItem{}).
-		Where("id = ?", req.OrderItemID).
-		Update("status", 4).Error; err != nil {
-		r.logger.Error("更新订单项状态失败", zap.Error(err))
-		return err
-	}
+		// 评价后订单完成
+		if err := r.orderStatusRepository.Transit(ctx, nil, &orderItem, common.ORDER_STATUS_COMPLETE, common.ORDER_ACTOR_USER, userID, "评价商品"); err != nil {
+			return err
+		}
 
-	return nil
+		// 晒图评价奖励积分
+		if err := r.pointRepository.AwardReviewPoints(ctx, r.DB(ctx), &review); err != nil {
+			r.logger.Error("发放评价积分失败", zap.Error(err))
+			return err
+		}
+		return nil
+	})
 }
 
 // GetProductReviews 获取商品评价列表
@@ -396,7 +406,7 @@ func (r *productReviewRepository) convertToReviewDTOs(ctx context.Context, revie
 			StatusText:    getReviewStatusText(int(review.Status)),
 			ProductName:   product.ProductName,
 			ProductImage:  product.HeaderImg,
-			Price:         product.CurrentPrice,
+			Price:         money.FromYuan(product.CurrentPrice),
 			Quantity:      int(userOrderItem.Quantity),
 			ReviewStatus:  review.Status,
 			OrderTime:     review.CreatedAt.Format("2006-01-02"),
@@ -536,7 +546,7 @@ func (r *productReviewRepository) GetReviewDetail(ctx context.Context, reviewID
 		ProductName:     product.ProductName,
 		ProductImage:    product.HeaderImg,
 		ProductSpec:     product.Specification,
-		Price:           product.CurrentPrice,
+		Price:           money.FromYuan(product.CurrentPrice),
 		Quantity:        int(orderItem.Quantity),
 		StoreName:       product.StoreName,
 		StoreIcon:       product.StoreIcon,
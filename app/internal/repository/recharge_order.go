@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"app/internal/common"
+	"app/internal/model"
+	"app/pkg/money"
+)
+
+type RechargeOrderRepository interface {
+	GetBonusTiers(ctx context.Context) ([]model.RechargeBonusTier, error)
+	CreateRecharge(ctx context.Context, userID string, amount money.Money) (*model.RechargeOrder, error)
+	CompleteRecharge(ctx context.Context, rechargeID uint64, paidAt time.Time) error
+	GetRechargeList(ctx context.Context, userID string, status *uint8, page, pageSize int) (*model.RechargeListResponse, error)
+}
+
+func NewRechargeOrderRepository(
+	repository *Repository,
+	settingsRepository SettingsRepository,
+	ledgerRepository LedgerRepository,
+) RechargeOrderRepository {
+	return &rechargeOrderRepository{
+		Repository:         repository,
+		settingsRepository: settingsRepository,
+		ledgerRepository:   ledgerRepository,
+	}
+}
+
+type rechargeOrderRepository struct {
+	*Repository
+	settingsRepository SettingsRepository
+	ledgerRepository   LedgerRepository
+}
+
+// GetBonusTiers 获取充值赠送档位，按充值满额从低到高排序，未配置时不赠送
+func (r *rechargeOrderRepository) GetBonusTiers(ctx context.Context) ([]model.RechargeBonusTier, error) {
+	tiers := make([]model.RechargeBonusTier, 0)
+	value, err := r.settingsRepository.GetSettings(ctx, common.SETTINGS_RECHARGE_BONUS_TIERS)
+	if err != nil || value == "" {
+		return tiers, nil
+	}
+	if err := json.Unmarshal([]byte(value), &tiers); err != nil {
+		r.logger.Error("解析充值赠送档位失败", zap.Error(err))
+		return nil, err
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].Amount < tiers[j].Amount
+	})
+	return tiers, nil
+}
+
+// CreateRecharge 创建待支付的充值单，赠送金额取达到的最高档位
+func (r *rechargeOrderRepository) CreateRecharge(ctx context.Context, userID string, amount money.Money) (*model.RechargeOrder, error) {
+	tiers, err := r.GetBonusTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var bonus money.Money
+	for _, tier := range tiers {
+		if amount >= tier.Amount {
+			bonus = tier.Bonus
+		}
+	}
+	rechargeOrder := &model.RechargeOrder{
+		RechargeNo:  fmt.Sprintf("R%s", common.GenerateOrderNo()),
+		UserID:      userID,
+		Amount:      amount,
+		BonusAmount: bonus,
+		Status:      common.RECHARGE_STATUS_PENDING,
+	}
+	if err := r.DB(ctx).Create(rechargeOrder).Error; err != nil {
+		r.logger.Error("创建充值单失败", zap.Error(err))
+		return nil, err
+	}
+	return rechargeOrder, nil
+}
+
+// CompleteRecharge 支付成功后给用户入账，充值金额和赠送金额分别记账；已到账的充值单不重复处理
+// 超时关闭的充值单收到支付时同样入账
+func (r *rechargeOrderRepository) CompleteRecharge(ctx context.Context, rechargeID uint64, paidAt time.Time) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		var rechargeOrder model.RechargeOrder
+		if err := r.DB(ctx).Where("id = ?", rechargeID).First(&rechargeOrder).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("充值单不存在")
+			}
+			return err
+		}
+		result := r.DB(ctx).Model(&model.RechargeOrder{}).
+			Where("id = ? AND status IN ?", rechargeID, []uint8{common.RECHARGE_STATUS_PENDING, common.RECHARGE_STATUS_CLOSED}).
+			Updates(map[string]interface{}{
+				"status":     common.RECHARGE_STATUS_PAID,
+				"paid_at":    paidAt,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			r.logger.Error("更新充值单状态失败", zap.Error(result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := r.ledgerRepository.Post(ctx, r.DB(ctx), model.LedgerEntry{
+			UserID:        rechargeOrder.UserID,
+			BusinessType:  common.BUSINESS_TYPE_RECHARGE,
+			ActionType:    common.ACTION_TYPE_RECHARGE,
+			AssetType:     common.ASSET_TYPE_BALANCE,
+			Amount:        rechargeOrder.Amount.Fen(),
+			RelationID:    int(rechargeOrder.ID),
+			RelationTitle: "充值",
+		}); err != nil {
+			return err
+		}
+		if rechargeOrder.BonusAmount > 0 {
+			return r.ledgerRepository.Post(ctx, r.DB(ctx), model.LedgerEntry{
+				UserID:        rechargeOrder.UserID,
+				BusinessType:  common.BUSINESS_TYPE_RECHARGE,
+				ActionType:    common.ACTION_TYPE_REWARD,
+				AssetType:     common.ASSET_TYPE_BALANCE,
+				Amount:        rechargeOrder.BonusAmount.Fen(),
+				RelationID:    int(rechargeOrder.ID),
+				RelationTitle: "充值赠送",
+			})
+		}
+		return nil
+	})
+}
+
+// GetRechargeList 获取充值记录，超时未支付的充值单先标记为已关闭
+func (r *rechargeOrderRepository) GetRechargeList(ctx context.Context, userID string, status *uint8, page, pageSize int) (*model.RechargeListResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	deadline := time.Now().Add(-common.RECHARGE_PAY_TIMEOUT * time.Minute)
+	if err := r.DB(ctx).Model(&model.RechargeOrder{}).
+		Where("user_id = ? AND status = ? AND created_at < ?", userID, common.RECHARGE_STATUS_PENDING, deadline).
+		Updates(map[string]interface{}{
+			"status":     common.RECHARGE_STATUS_CLOSED,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		r.logger.Error("关闭超时充值单失败", zap.Error(err))
+		return nil, err
+	}
+
+	query := r.DB(ctx).Model(&model.RechargeOrder{}).Where("user_id = ?", userID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("查询充值记录总数失败", zap.Error(err))
+		return nil, err
+	}
+	var rechargeOrders []model.RechargeOrder
+	if err := query.Order("created_at DESC").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&rechargeOrders).Error; err != nil {
+		r.logger.Error("查询充值记录失败", zap.Error(err))
+		return nil, err
+	}
+
+	list := make([]model.RechargeListItem, 0, len(rechargeOrders))
+	for _, order := range rechargeOrders {
+		list = append(list, model.RechargeListItem{
+			ID:          order.ID,
+			RechargeNo:  order.RechargeNo,
+			Amount:      order.Amount,
+			BonusAmount: order.BonusAmount,
+			Status:      order.Status,
+			StatusText:  getRechargeStatusText(order.Status),
+			PaidAt:      order.PaidAt,
+			CreatedAt:   order.CreatedAt,
+		})
+	}
+	return &model.RechargeListResponse{
+		Total: total,
+		List:  list,
+		Page:  page,
+		Size:  pageSize,
+	}, nil
+}
+
+// getRechargeStatusText 获取充值状态文本
+func getRechargeStatusText(status uint8) string {
+	switch status {
+	case common.RECHARGE_STATUS_PENDING:
+		return "待支付"
+	case common.RECHARGE_STATUS_PAID:
+		return "已到账"
+	case common.RECHARGE_STATUS_CLOSED:
+		return "已关闭"
+	default:
+		return "未知状态"
+	}
+}
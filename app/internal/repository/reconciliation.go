@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"app/internal/common"
+	"app/internal/model"
+	"app/pkg/config"
+)
+
+// reconcileBatchSize 每批对账的用户数
+const reconcileBatchSize = 500
+
+type ReconciliationRepository interface {
+	Reconcile(ctx context.Context, autoCorrect bool) (*model.ReconciliationRun, error)
+	GetRuns(ctx context.Context, req model.ReconciliationRunListRequest) (*model.ReconciliationRunListResponse, error)
+	GetDiscrepancies(ctx context.Context, runID uint64) ([]model.ReconciliationDiscrepancy, error)
+}
+
+func NewReconciliationRepository(
+	repository *Repository,
+	ledgerRepository LedgerRepository,
+) ReconciliationRepository {
+	return &reconciliationRepository{
+		Repository:       repository,
+		ledgerRepository: ledgerRepository,
+	}
+}
+
+type reconciliationRepository struct {
+	*Repository
+	ledgerRepository LedgerRepository
+}
+
+// reconcileSnapshot 一批用户的流水和业务单据汇总，余额单位为分
+type reconcileSnapshot struct {
+	ledger           map[string]map[int8]int64 // 用户账户流水合计，按资产类型区分
+	orderLedger      map[string]int64          // 订单余额扣款流水合计
+	orderExpected    map[string]int64          // 按余额支付订单和已完成退款计算的应有扣款
+	withdrawLedger   map[string]int64          // 提现流水合计
+	withdrawExpected map[string]int64          // 按提现单计算的应有扣款
+	frozenExpected   map[string]int64          // 冻结中的冻结单未扣除金额合计
+	pointLots        map[string]int64          // 积分批次剩余合计
+}
+
+type reconcileSum struct {
+	UserID    string
+	AssetType int8
+	Total     int64
+}
+
+// Reconcile 按流水和订单、提现、退款单据重新核算每个用户的余额和积分，记录差异
+// autoCorrect 为 true 时对资产与流水不符的用户补记调整流水；缓存与资产不符时直接删除缓存
+func (r *reconciliationRepository) Reconcile(ctx context.Context, autoCorrect bool) (*model.ReconciliationRun, error) {
+	run := &model.ReconciliationRun{
+		Status:    common.RECONCILE_STATUS_RUNNING,
+		StartedAt: time.Now(),
+	}
+	if autoCorrect {
+		run.AutoCorrect = 1
+	}
+	if err := r.DB(ctx).Create(run).Error; err != nil {
+		r.logger.Error("创建对账任务失败", zap.Error(err))
+		return nil, err
+	}
+
+	err := r.reconcile(ctx, run, autoCorrect)
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = common.RECONCILE_STATUS_DONE
+	if err != nil {
+		run.Status = common.RECONCILE_STATUS_FAILED
+		run.Error = err.Error()
+		if len(run.Error) > 500 {
+			run.Error = run.Error[:500]
+		}
+	}
+	if err := r.DB(ctx).Model(run).Updates(map[string]interface{}{
+		"status":            run.Status,
+		"user_count":        run.UserCount,
+		"discrepancy_count": run.DiscrepancyCount,
+		"corrected_count":   run.CorrectedCount,
+		"error":             run.Error,
+		"finished_at":       run.FinishedAt,
+	}).Error; err != nil {
+		r.logger.Error("更新对账任务失败", zap.Error(err))
+	}
+	return run, err
+}
+
+// GetRuns 分页获取对账任务，按开始时间倒序
+func (r *reconciliationRepository) GetRuns(ctx context.Context, req model.ReconciliationRunListRequest) (*model.ReconciliationRunListResponse, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	var total int64
+	if err := r.DB(ctx).Model(&model.ReconciliationRun{}).Count(&total).Error; err != nil {
+		r.logger.Error("查询对账任务总数失败", zap.Error(err))
+		return nil, err
+	}
+	runs := make([]model.ReconciliationRun, 0)
+	if err := r.DB(ctx).Order("id DESC").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&runs).Error; err != nil {
+		r.logger.Error("查询对账任务失败", zap.Error(err))
+		return nil, err
+	}
+	return &model.ReconciliationRunListResponse{
+		Total: total,
+		List:  runs,
+		Page:  page,
+		Size:  pageSize,
+	}, nil
+}
+
+// GetDiscrepancies 获取对账任务发现的差异
+func (r *reconciliationRepository) GetDiscrepancies(ctx context.Context, runID uint64) ([]model.ReconciliationDiscrepancy, error) {
+	discrepancies := make([]model.ReconciliationDiscrepancy, 0)
+	if err := r.DB(ctx).Where("run_id = ?", runID).Order("id ASC").Find(&discrepancies).Error; err != nil {
+		r.logger.Error("查询对账差异失败", zap.Error(err))
+		return nil, err
+	}
+	return discrepancies, nil
+}
+
+func (r *reconciliationRepository) reconcile(ctx context.Context, run *model.ReconciliationRun, autoCorrect bool) error {
+	lastID := 0
+	for {
+		var assets []model.UserAsset
+		if err := r.DB(ctx).Where("id > ?", lastID).Order("id ASC").Limit(reconcileBatchSize).Find(&assets).Error; err != nil {
+			r.logger.Error("查询用户资产失败", zap.Error(err))
+			return err
+		}
+		if len(assets) == 0 {
+			return nil
+		}
+		lastID = assets[len(assets)-1].ID
+		run.UserCount += len(assets)
+
+		userIds := make([]string, 0, len(assets))
+		for _, asset := range assets {
+			userIds = append(userIds, asset.UserID)
+		}
+		snapshot, err := r.snapshot(r.DB(ctx), userIds)
+		if err != nil {
+			return err
+		}
+		for _, asset := range assets {
+			// 批量汇总时资产可能正在变动，有差异的用户加锁后重新核对
+			if len(snapshot.diff(run.ID, asset)) == 0 {
+				continue
+			}
+			discrepancies, err := r.checkUser(ctx, run.ID, asset.UserID, autoCorrect)
+			if err != nil {
+				return err
+			}
+			for _, d := range discrepancies {
+				run.DiscrepancyCount++
+				if d.Corrected == 1 {
+					run.CorrectedCount++
+				}
+			}
+		}
+
+		discrepancies, err := r.checkCache(ctx, run.ID, assets)
+		if err != nil {
+			return err
+		}
+		run.DiscrepancyCount += len(discrepancies)
+		run.CorrectedCount += len(discrepancies)
+	}
+}
+
+// checkUser 锁定用户资产后核对，资产变动需要等待核对完成，结果不受并发写入影响
+func (r *reconciliationRepository) checkUser(ctx context.Context, runID uint64, userID string, autoCorrect bool) ([]model.ReconciliationDiscrepancy, error) {
+	var discrepancies []model.ReconciliationDiscrepancy
+	err := r.Transaction(ctx, func(ctx context.Context) error {
+		tx := r.DB(ctx)
+		var asset model.UserAsset
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).First(&asset).Error; err != nil {
+			return err
+		}
+		snapshot, err := r.snapshot(tx, []string{userID})
+		if err != nil {
+			return err
+		}
+		discrepancies = snapshot.diff(runID, asset)
+		for i := range discrepancies {
+			d := &discrepancies[i]
+			if !autoCorrect || d.Type != common.RECONCILE_TYPE_LEDGER {
+				continue
+			}
+			if err := r.ledgerRepository.Compensate(ctx, tx, model.LedgerEntry{
+				UserID:        userID,
+				BusinessType:  common.BUSINESS_TYPE_ADJUST,
+				ActionType:    common.ACTION_TYPE_ADJUST,
+				AssetType:     d.AssetType,
+				Amount:        d.Diff,
+				RelationID:    int(runID),
+				RelationTitle: "对账调整",
+			}); err != nil {
+				return err
+			}
+			d.Corrected = 1
+		}
+		if len(discrepancies) == 0 {
+			return nil
+		}
+		if err := tx.Create(&discrepancies).Error; err != nil {
+			r.logger.Error("记录对账差异失败", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	return discrepancies, err
+}
+
+// checkCache 核对缓存中的用户资产，与数据库不一致时删除缓存
+func (r *reconciliationRepository) checkCache(ctx context.Context, runID uint64, assets []model.UserAsset) ([]model.ReconciliationDiscrepancy, error) {
+	keys := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		keys = append(keys, common.PREFFIX_USER_ASSET+asset.UserID)
+	}
+	values, err := config.Rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		r.logger.Error("查询用户资产缓存失败", zap.Error(err))
+		return nil, err
+	}
+	discrepancies := make([]model.ReconciliationDiscrepancy, 0)
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var cached model.UserAsset
+		invalid := json.Unmarshal([]byte(str), &cached) != nil
+		rows := []model.ReconciliationDiscrepancy{
+			{
+				RunID:       runID,
+				UserID:      assets[i].UserID,
+				Type:        common.RECONCILE_TYPE_CACHE,
+				AssetType:   common.ASSET_TYPE_BALANCE,
+				ActualNum:   cached.Balance.Fen(),
+				ExpectedNum: assets[i].Balance.Fen(),
+				Diff:        (cached.Balance - assets[i].Balance).Fen(),
+				Corrected:   1,
+			},
+			{
+				RunID:       runID,
+				UserID:      assets[i].UserID,
+				Type:        common.RECONCILE_TYPE_CACHE,
+				AssetType:   common.ASSET_TYPE_POINT,
+				ActualNum:   int64(cached.Points),
+				ExpectedNum: int64(assets[i].Points),
+				Diff:        int64(cached.Points - assets[i].Points),
+				Corrected:   1,
+			},
+		}
+		stale := invalid
+		for _, row := range rows {
+			if row.Diff != 0 {
+				discrepancies = append(discrepancies, row)
+				stale = true
+			} else if invalid {
+				// 缓存无法解析时也记录一条差异
+				discrepancies = append(discrepancies, row)
+				invalid = false
+			}
+		}
+		if !stale {
+			continue
+		}
+		if err := config.Rdb.Del(ctx, keys[i]).Err(); err != nil {
+			r.logger.Error("删除用户资产缓存失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	if len(discrepancies) == 0 {
+		return discrepancies, nil
+	}
+	if err := r.DB(ctx).Create(&discrepancies).Error; err != nil {
+		r.logger.Error("记录对账差异失败", zap.Error(err))
+		return nil, err
+	}
+	return discrepancies, nil
+}
+
+// snapshot 汇总用户的流水和业务单据
+func (r *reconciliationRepository) snapshot(db *gorm.DB, userIds []string) (*reconcileSnapshot, error) {
+	s := &reconcileSnapshot{
+		ledger:           make(map[string]map[int8]int64, len(userIds)),
+		orderLedger:      make(map[string]int64, len(userIds)),
+		orderExpected:    make(map[string]int64, len(userIds)),
+		withdrawLedger:   make(map[string]int64, len(userIds)),
+		withdrawExpected: make(map[string]int64, len(userIds)),
+		frozenExpected:   make(map[string]int64, len(userIds)),
+		pointLots:        make(map[string]int64, len(userIds)),
+	}
+
+	var sums []reconcileSum
+	if err := db.Model(&model.UserAssetRecord{}).
+		Select("user_id, asset_type, COALESCE(SUM(action_num), 0) AS total").
+		Where("user_id IN ? AND account_type = ? AND asset_type IN ?", userIds, common.LEDGER_ACCOUNT_USER,
+			[]int8{common.ASSET_TYPE_POINT, common.ASSET_TYPE_BALANCE, common.ASSET_TYPE_FROZEN}).
+		Group("user_id, asset_type").Scan(&sums).Error; err != nil {
+		r.logger.Error("汇总资产流水失败", zap.Error(err))
+		return nil, err
+	}
+	for _, sum := range sums {
+		if s.ledger[sum.UserID] == nil {
+			s.ledger[sum.UserID] = make(map[int8]int64, 3)
+		}
+		s.ledger[sum.UserID][sum.AssetType] = sum.Total
+	}
+
+	if err := r.sumLedger(db, userIds, common.BUSINESS_TYPE_ORDER, s.orderLedger); err != nil {
+		return nil, err
+	}
+	if err := r.sumLedger(db, userIds, common.BUSINESS_TYPE_WITHDRAW, s.withdrawLedger); err != nil {
+		return nil, err
+	}
+
+	// 余额支付的订单下单时扣款，退款完成后退回
+	queries := []struct {
+		db   *gorm.DB
+		sign int64
+		into map[string]int64
+	}{
+		{
+			db: db.Table("user_order_item AS i").
+				Joins("JOIN user_order AS o ON o.id = i.order_id").
+				Select("i.user_id AS user_id, COALESCE(SUM(i.total_fee), 0) AS total").
+				Where("i.user_id IN ? AND o.payment_method = ?", userIds, common.PAYMENT_METHOD_BALANCE).
+				Group("i.user_id"),
+			sign: -1,
+			into: s.orderExpected,
+		},
+		{
+			db: db.Table("refund_order AS ro").
+				Joins("JOIN user_order AS o ON o.id = ro.order_id").
+				Select("ro.user_id AS user_id, COALESCE(SUM(ro.refund_amount), 0) AS total").
+				Where("ro.user_id IN ? AND o.payment_method = ? AND ro.status = ?", userIds,
+					common.PAYMENT_METHOD_BALANCE, common.REFUND_STATUS_SUCCESS).
+				Group("ro.user_id"),
+			sign: 1,
+			into: s.orderExpected,
+		},
+		{
+			// 被拒绝且已退回的提现不扣款
+			db: db.Model(&model.WithdrawOrder{}).
+				Select("user_id, COALESCE(SUM(amount), 0) AS total").
+				Where("user_id IN ? AND (status <> ? OR settled = 0)", userIds, common.WITHDRAW_STATUS_REJECTED).
+				Group("user_id"),
+			sign: -1,
+			into: s.withdrawExpected,
+		},
+		{
+			db: db.Model(&model.UserAssetHold{}).
+				Select("user_id, COALESCE(SUM(amount - captured_amount), 0) AS total").
+				Where("user_id IN ? AND status = ?", userIds, common.HOLD_STATUS_HELD).
+				Group("user_id"),
+			sign: 1,
+			into: s.frozenExpected,
+		},
+		{
+			db: db.Model(&model.PointLot{}).
+				Select("user_id, COALESCE(SUM(remaining), 0) AS total").
+				Where("user_id IN ? AND remaining > 0", userIds).
+				Group("user_id"),
+			sign: 1,
+			into: s.pointLots,
+		},
+	}
+	for _, q := range queries {
+		var sums []reconcileSum
+		if err := q.db.Scan(&sums).Error; err != nil {
+			r.logger.Error("汇总业务单据失败", zap.Error(err))
+			return nil, err
+		}
+		for _, sum := range sums {
+			q.into[sum.UserID] += q.sign * sum.Total
+		}
+	}
+	return s, nil
+}
+
+// sumLedger 汇总某类业务的余额流水
+func (r *reconciliationRepository) sumLedger(db *gorm.DB, userIds []string, businessType int8, into map[string]int64) error {
+	var sums []reconcileSum
+	if err := db.Model(&model.UserAssetRecord{}).
+		Select("user_id, COALESCE(SUM(action_num), 0) AS total").
+		Where("user_id IN ? AND account_type = ? AND asset_type = ? AND business_type = ?", userIds,
+			common.LEDGER_ACCOUNT_USER, common.ASSET_TYPE_BALANCE, businessType).
+		Group("user_id").Scan(&sums).Error; err != nil {
+		r.logger.Error("汇总资产流水失败", zap.Error(err))
+		return err
+	}
+	for _, sum := range sums {
+		into[sum.UserID] = sum.Total
+	}
+	return nil
+}
+
+// diff 比较用户资产与汇总结果，返回差异
+func (s *reconcileSnapshot) diff(runID uint64, asset model.UserAsset) []model.ReconciliationDiscrepancy {
+	checks := []struct {
+		typ       uint8
+		assetType int8
+		actual    int64
+		expected  int64
+	}{
+		{common.RECONCILE_TYPE_LEDGER, common.ASSET_TYPE_BALANCE, asset.Balance.Fen(), s.ledger[asset.UserID][common.ASSET_TYPE_BALANCE]},
+		{common.RECONCILE_TYPE_LEDGER, common.ASSET_TYPE_POINT, int64(asset.Points), s.ledger[asset.UserID][common.ASSET_TYPE_POINT]},
+		{common.RECONCILE_TYPE_LEDGER, common.ASSET_TYPE_FROZEN, asset.FrozenBalance.Fen(), s.ledger[asset.UserID][common.ASSET_TYPE_FROZEN]},
+		{common.RECONCILE_TYPE_ORDER, common.ASSET_TYPE_BALANCE, s.orderLedger[asset.UserID], s.orderExpected[asset.UserID]},
+		{common.RECONCILE_TYPE_WITHDRAW, common.ASSET_TYPE_BALANCE, s.withdrawLedger[asset.UserID], s.withdrawExpected[asset.UserID]},
+		{common.RECONCILE_TYPE_FROZEN, common.ASSET_TYPE_FROZEN, asset.FrozenBalance.Fen(), s.frozenExpected[asset.UserID]},
+		{common.RECONCILE_TYPE_POINT, common.ASSET_TYPE_POINT, int64(asset.Points), s.pointLots[asset.UserID]},
+	}
+	discrepancies := make([]model.ReconciliationDiscrepancy, 0)
+	for _, c := range checks {
+		if c.actual == c.expected {
+			continue
+		}
+		discrepancies = append(discrepancies, model.ReconciliationDiscrepancy{
+			RunID:       runID,
+			UserID:      asset.UserID,
+			Type:        c.typ,
+			AssetType:   c.assetType,
+			ActualNum:   c.actual,
+			ExpectedNum: c.expected,
+			Diff:        c.actual - c.expected,
+		})
+	}
+	return discrepancies
+}
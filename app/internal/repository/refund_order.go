@@ -10,6 +10,7 @@ import (
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RefundOrderRepository interface {
@@ -19,15 +20,34 @@ type RefundOrderRepository interface {
 	CancelRefund(ctx context.Context, userID string, refundID uint64) error
 	GetRefundDetailByID(ctx context.Context, refundID uint64) (*model.RefundDetailResponse, error)
 	DeleteRefund(ctx context.Context, userID string, refundID uint64) error
+	CompleteRefund(ctx context.Context, refundID uint64, actorType uint8, actorID string) error
+	GetPendingRefund(ctx context.Context, refundID uint64) (*model.RefundOrder, *model.UserOrder, error)
+	ApproveBalanceRefund(ctx context.Context, refundID uint64, operator string) error
+	RejectRefund(ctx context.Context, refundID uint64, operator string, reason string) error
+	MarkRefundProcessing(ctx context.Context, refundID uint64) error
 }
 
 type refundOrderRepository struct {
 	*Repository
+	orderStatusRepository OrderStatusRepository
+	productRepository     ProductRepository
+	memberTierRepository  MemberTierRepository
+	userAssetRepository   UserAssetRepository
+	userCouponRepository  UserCouponRepository
+	ostrichRepository     OstrichRepository
+	ledgerRepository      LedgerRepository
 }
 
-func NewRefundOrderRepository(repository *Repository) RefundOrderRepository {
+func NewRefundOrderRepository(repository *Repository, orderStatusRepository OrderStatusRepository, productRepository ProductRepository, memberTierRepository MemberTierRepository, userAssetRepository UserAssetRepository, userCouponRepository UserCouponRepository, ostrichRepository OstrichRepository, ledgerRepository LedgerRepository) RefundOrderRepository {
 	return &refundOrderRepository{
-		Repository: repository,
+		Repository:            repository,
+		orderStatusRepository: orderStatusRepository,
+		productRepository:     productRepository,
+		memberTierRepository:  memberTierRepository,
+		userAssetRepository:   userAssetRepository,
+		userCouponRepository:  userCouponRepository,
+		ostrichRepository:     ostrichRepository,
+		ledgerRepository:      ledgerRepository,
 	}
 }
 
@@ -88,7 +108,8 @@ func (r *refundOrderRepository) CreateRefund(ctx context.Context, userID string,
 		return err
 	}
 	// 变更订单状态
-	if err := tx.Model(&orderItem).Update("status", common.ORDER_STATUS_REFUNDED).Error; err != nil {
+	// 退款相关的状态变更只能由退款流程执行，用户不能通过修改订单状态直接进入
+	if err := r.orderStatusRepository.Transit(ctx, tx, &orderItem, common.ORDER_STATUS_REFUNDED, common.ORDER_ACTOR_SYSTEM, userID, "用户申请退款"); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -98,6 +119,19 @@ func (r *refundOrderRepository) CreateRefund(ctx context.Context, userID string,
 		tx.Rollback()
 		return errors.New("退款数量不合法")
 	}
+
+	// 余额支付的订单，审核期间退款金额退入冻结余额
+	var order model.UserOrder
+	if err := tx.Where("id = ?", orderItem.OrderID).First(&order).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if order.PaymentMethod == common.PAYMENT_METHOD_BALANCE {
+		if err := r.holdRefund(ctx, tx, &refundOrder); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return err
@@ -221,6 +255,222 @@ func (r *refundOrderRepository) GetRefundDetail(ctx context.Context, userID stri
 	return response, nil
 }
 
+// CompleteRefund 退款完成，关闭订单并归还库存
+func (r *refundOrderRepository) CompleteRefund(ctx context.Context, refundID uint64, actorType uint8, actorID string) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		var refundOrder model.RefundOrder
+		if err := r.DB(ctx).Where("id = ?", refundID).First(&refundOrder).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("退款单不存在")
+			}
+			return err
+		}
+
+		now := time.Now()
+		result := r.DB(ctx).Model(&model.RefundOrder{}).
+			Where("id = ? AND status = ?", refundID, common.REFUND_STATUS_PENDING).
+			Updates(map[string]interface{}{
+				"status":          common.REFUND_STATUS_SUCCESS,
+				"completion_time": now.Format("2006-01-02 15:04:05"),
+				"updated_at":      now,
+			})
+		if result.Error != nil {
+			r.logger.Error("更新退款单状态失败", zap.Error(result.Error))
+			return result.Error
+		}
+		// 已处理过的退款单不重复处理
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		var orderItem model.UserOrderItem
+		if err := r.DB(ctx).Where("id = ?", refundOrder.OrderItemID).First(&orderItem).Error; err != nil {
+			return err
+		}
+		if err := r.orderStatusRepository.Transit(ctx, nil, &orderItem, common.ORDER_STATUS_CLOSED, actorType, actorID, "退款完成"); err != nil {
+			return err
+		}
+		if err := r.productRepository.RestockRefund(ctx, nil, orderItem.ProductID, orderItem.Quantity); err != nil {
+			return err
+		}
+		if err := r.userAssetRepository.AddConsumption(ctx, nil, refundOrder.UserID, -refundOrder.RefundAmount); err != nil {
+			return err
+		}
+		// 订单项已关闭，退订登记的认养鸵鸟
+		if err := r.ostrichRepository.CancelByOrderItem(ctx, nil, orderItem.ID, "退款完成"); err != nil {
+			return err
+		}
+		// 全额退款时退还订单项使用的优惠券，已过截止时间的由过期任务标记为已过期
+		if refundOrder.RefundAmount >= orderItem.TotalFee {
+			if err := r.userCouponRepository.ReleaseCoupon(ctx, nil, &orderItem); err != nil {
+				return err
+			}
+		}
+		// 退款后消费金额减少，重新计算会员身份
+		return r.memberTierRepository.EvaluateRole(ctx, nil, refundOrder.UserID, common.ORDER_ACTOR_SYSTEM, "", "退款完成")
+	})
+}
+
+// GetPendingRefund 查询待审核的退款单及其所属订单
+func (r *refundOrderRepository) GetPendingRefund(ctx context.Context, refundID uint64) (*model.RefundOrder, *model.UserOrder, error) {
+	var refundOrder model.RefundOrder
+	if err := r.DB(ctx).Where("id = ?", refundID).First(&refundOrder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("退款单不存在")
+		}
+		return nil, nil, err
+	}
+	if refundOrder.Status != common.REFUND_STATUS_PENDING {
+		return nil, nil, errors.New("退款单已处理")
+	}
+	var order model.UserOrder
+	if err := r.DB(ctx).Where("id = ?", refundOrder.OrderID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("订单不存在")
+		}
+		return nil, nil, err
+	}
+	return &refundOrder, &order, nil
+}
+
+// ApproveBalanceRefund 审核通过余额支付订单的退款，退款金额退回可用余额并完成退款
+func (r *refundOrderRepository) ApproveBalanceRefund(ctx context.Context, refundID uint64, operator string) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		refundOrder, err := r.lockPendingRefund(ctx, refundID)
+		if err != nil {
+			return err
+		}
+		held, err := r.refundHeld(r.DB(ctx), refundID)
+		if err != nil {
+			return err
+		}
+		if held {
+			if err := r.ledgerRepository.Release(ctx, r.DB(ctx), common.BUSINESS_TYPE_ORDER, int(refundID)); err != nil {
+				return err
+			}
+		} else {
+			// 冻结逻辑上线前申请的退款没有冻结记录，直接退回余额
+			if err := r.ledgerRepository.Post(ctx, r.DB(ctx), model.LedgerEntry{
+				UserID:        refundOrder.UserID,
+				BusinessType:  common.BUSINESS_TYPE_ORDER,
+				ActionType:    common.ACTION_TYPE_REFUND,
+				AssetType:     common.ASSET_TYPE_BALANCE,
+				Amount:        refundOrder.RefundAmount.Fen(),
+				RelationID:    int(refundOrder.ID),
+				RelationTitle: refundOrder.ProductName + "退款",
+			}); err != nil {
+				return err
+			}
+		}
+		return r.CompleteRefund(ctx, refundID, common.ORDER_ACTOR_ADMIN, operator)
+	})
+}
+
+// RejectRefund 审核拒绝退款，订单项恢复到申请退款前的状态，扣除审核期间退入的冻结金额
+func (r *refundOrderRepository) RejectRefund(ctx context.Context, refundID uint64, operator string, reason string) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		refundOrder, err := r.lockPendingRefund(ctx, refundID)
+		if err != nil {
+			return err
+		}
+		if refundOrder.ProcessTime != "" {
+			return errors.New("退款已提交渠道处理")
+		}
+		now := time.Now()
+		if err := r.DB(ctx).Model(refundOrder).Updates(map[string]interface{}{
+			"status":        common.REFUND_STATUS_REJECTED,
+			"reject_reason": reason,
+			"process_time":  now.Format("2006-01-02 15:04:05"),
+			"updated_at":    now,
+		}).Error; err != nil {
+			r.logger.Error("更新退款单状态失败", zap.Error(err))
+			return err
+		}
+
+		var orderItem model.UserOrderItem
+		if err := r.DB(ctx).Where("id = ?", refundOrder.OrderItemID).First(&orderItem).Error; err != nil {
+			return err
+		}
+		originStatus, err := r.orderStatusRepository.GetStatusBefore(ctx, nil, orderItem.ID, common.ORDER_STATUS_REFUNDED)
+		if err != nil {
+			return err
+		}
+		if err := r.orderStatusRepository.Transit(ctx, nil, &orderItem, originStatus, common.ORDER_ACTOR_ADMIN, operator, "退款被拒绝"); err != nil {
+			return err
+		}
+		return r.captureRefundHold(ctx, r.DB(ctx), refundID)
+	})
+}
+
+// MarkRefundProcessing 记录退款单提交渠道退款的处理时间，已提交的退款单不能再拒绝
+func (r *refundOrderRepository) MarkRefundProcessing(ctx context.Context, refundID uint64) error {
+	now := time.Now()
+	if err := r.DB(ctx).Model(&model.RefundOrder{}).
+		Where("id = ? AND status = ? AND (process_time = '' OR process_time IS NULL)", refundID, common.REFUND_STATUS_PENDING).
+		Updates(map[string]interface{}{
+			"process_time": now.Format("2006-01-02 15:04:05"),
+			"updated_at":   now,
+		}).Error; err != nil {
+		r.logger.Error("更新退款单处理时间失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// lockPendingRefund 锁定待审核的退款单
+func (r *refundOrderRepository) lockPendingRefund(ctx context.Context, refundID uint64) (*model.RefundOrder, error) {
+	var refundOrder model.RefundOrder
+	if err := r.DB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", refundID).First(&refundOrder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("退款单不存在")
+		}
+		return nil, err
+	}
+	if refundOrder.Status != common.REFUND_STATUS_PENDING {
+		return nil, errors.New("退款单已处理")
+	}
+	return &refundOrder, nil
+}
+
+// holdRefund 余额支付订单申请退款时，退款金额先退回余额再冻结，审核通过后解冻
+func (r *refundOrderRepository) holdRefund(ctx context.Context, tx *gorm.DB, refundOrder *model.RefundOrder) error {
+	entry := model.LedgerEntry{
+		UserID:        refundOrder.UserID,
+		BusinessType:  common.BUSINESS_TYPE_ORDER,
+		ActionType:    common.ACTION_TYPE_REFUND,
+		AssetType:     common.ASSET_TYPE_BALANCE,
+		Amount:        refundOrder.RefundAmount.Fen(),
+		RelationID:    int(refundOrder.ID),
+		RelationTitle: refundOrder.ProductName + "退款",
+	}
+	if err := r.ledgerRepository.Post(ctx, tx, entry); err != nil {
+		return err
+	}
+	entry.RelationTitle = "退款冻结"
+	return r.ledgerRepository.Hold(ctx, tx, entry)
+}
+
+// captureRefundHold 退款撤销或被拒绝时扣除审核期间退入的冻结金额，没有冻结记录的退款单不处理
+func (r *refundOrderRepository) captureRefundHold(ctx context.Context, tx *gorm.DB, refundID uint64) error {
+	held, err := r.refundHeld(tx, refundID)
+	if err != nil || !held {
+		return err
+	}
+	return r.ledgerRepository.Capture(ctx, tx, common.BUSINESS_TYPE_ORDER, int(refundID))
+}
+
+// refundHeld 退款单是否有冻结记录
+func (r *refundOrderRepository) refundHeld(tx *gorm.DB, refundID uint64) (bool, error) {
+	var count int64
+	if err := tx.Model(&model.UserAssetHold{}).
+		Where("business_type = ? AND relation_id = ?", common.BUSINESS_TYPE_ORDER, int(refundID)).
+		Count(&count).Error; err != nil {
+		r.logger.Error("查询冻结记录失败", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // getRefundStatusText 获取退款状态文本
 func getRefundStatusText(status uint8) string {
 	switch status {
@@ -228,7 +478,7 @@ func getRefundStatusText(status uint8) string {
 		return "退款进行中"
 	case 1:
 		return "已退款"
-	case 3:
+	case 2:
 		return "已拒绝"
 	default:
 		return "未知状态"
@@ -284,15 +534,35 @@ func (r *refundOrderRepository) CancelRefund(ctx context.Context, userID string,
 	}()
 
 	// 恢复订单项状态为原始状态
-	if err := tx.Model(&model.UserOrderItem{}).
-		Where("id = ?", refundOrder.OrderItemID).
-		Update("status", refundOrder.OriginStatus).Error; err != nil {
+	var orderItem model.UserOrderItem
+	if err := tx.Where("id = ?", refundOrder.OrderItemID).First(&orderItem).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
+	// 只恢复到状态记录中申请退款前的状态
+	originStatus, err := r.orderStatusRepository.GetStatusBefore(ctx, tx, orderItem.ID, common.ORDER_STATUS_REFUNDED)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := r.orderStatusRepository.Transit(ctx, tx, &orderItem, originStatus, common.ORDER_ACTOR_SYSTEM, userID, "用户撤销退款"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 删除退款单，审核已处理的不允许撤销
+	result := tx.Where("status = ?", common.REFUND_STATUS_PENDING).Delete(&refundOrder)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errors.New("当前退款单状态不允许撤销")
+	}
 
-	// 删除退款单
-	if err := tx.Delete(&refundOrder).Error; err != nil {
+	// 退款撤销，扣除审核期间退入的冻结金额
+	if err := r.captureRefundHold(ctx, tx, refundOrder.ID); err != nil {
 		tx.Rollback()
 		return err
 	}
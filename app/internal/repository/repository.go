@@ -70,6 +70,14 @@ func (r *Repository) Transaction(ctx context.Context, fn func(ctx context.Contex
 			},
 		)
 	}
+	// 已处于事务中时复用外层事务，避免嵌套调用时开启独立事务
+	if v, ok := ctx.Value(ctxTxKey).(*gorm.DB); ok {
+		return v.Transaction(
+			func(tx *gorm.DB) error {
+				return fn(context.WithValue(ctx, ctxTxKey, tx))
+			},
+		)
+	}
 	return r.db[0].WithContext(ctx).Transaction(
 		func(tx *gorm.DB) error {
 			ctx = context.WithValue(ctx, ctxTxKey, tx)
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"app/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type StoreRepository interface {
+	GetStore(ctx context.Context, storeID uint64) (*model.Store, error)
+	GetStoresByIDs(ctx context.Context, storeIds []uint64) (map[uint64]model.Store, error)
+	GetStoreRating(ctx context.Context, storeID uint64) (*model.StoreRatingDTO, error)
+	GetStoreProducts(ctx context.Context, storeID uint64, page, pageSize int) ([]model.ProductListItemDTO, int64, error)
+}
+
+func NewStoreRepository(
+	repository *Repository,
+) StoreRepository {
+	return &storeRepository{
+		Repository: repository,
+	}
+}
+
+type storeRepository struct {
+	*Repository
+}
+
+// GetStore 获取店铺信息
+func (r *storeRepository) GetStore(ctx context.Context, storeID uint64) (*model.Store, error) {
+	var store model.Store
+	if err := r.DB(ctx).Where("id = ?", storeID).First(&store).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("店铺不存在")
+		}
+		r.logger.Error("查询店铺失败", zap.Error(err))
+		return nil, err
+	}
+	return &store, nil
+}
+
+// GetStoresByIDs 批量获取店铺信息
+func (r *storeRepository) GetStoresByIDs(ctx context.Context, storeIds []uint64) (map[uint64]model.Store, error) {
+	storeMap := make(map[uint64]model.Store, len(storeIds))
+	if len(storeIds) == 0 {
+		return storeMap, nil
+	}
+	var stores []model.Store
+	if err := r.DB(ctx).Where("id IN ?", storeIds).Find(&stores).Error; err != nil {
+		r.logger.Error("查询店铺失败", zap.Error(err))
+		return nil, err
+	}
+	for _, store := range stores {
+		storeMap[store.ID] = store
+	}
+	return storeMap, nil
+}
+
+// GetStoreRating 统计店铺所有商品已通过评价的平均分
+func (r *storeRepository) GetStoreRating(ctx context.Context, storeID uint64) (*model.StoreRatingDTO, error) {
+	var rating model.StoreRatingDTO
+	if err := r.DB(ctx).Table("product_review AS pr").
+		Joins("JOIN product AS p ON p.id = pr.product_id").
+		Where("p.store_id = ? AND pr.status = ?", storeID, 1).
+		Select(`COALESCE(AVG(pr.rating), 0) AS rating,
+			COALESCE(AVG(pr.freshness_rating), 0) AS freshness_rating,
+			COALESCE(AVG(pr.packaging_rating), 0) AS packaging_rating,
+			COALESCE(AVG(pr.delivery_rating), 0) AS delivery_rating,
+			COALESCE(AVG(pr.service_rating), 0) AS service_rating,
+			COUNT(*) AS review_count`).
+		Scan(&rating).Error; err != nil {
+		r.logger.Error("统计店铺评分失败", zap.Error(err))
+		return nil, err
+	}
+	// 保留一位小数
+	rating.Rating = math.Round(rating.Rating*10) / 10
+	rating.FreshnessRating = math.Round(rating.FreshnessRating*10) / 10
+	rating.PackagingRating = math.Round(rating.PackagingRating*10) / 10
+	rating.DeliveryRating = math.Round(rating.DeliveryRating*10) / 10
+	rating.ServiceRating = math.Round(rating.ServiceRating*10) / 10
+	return &rating, nil
+}
+
+// GetStoreProducts 分页获取店铺的商品
+func (r *storeRepository) GetStoreProducts(ctx context.Context, storeID uint64, page, pageSize int) ([]model.ProductListItemDTO, int64, error) {
+	query := r.DB(ctx).Model(&model.Product{}).Where("store_id = ?", storeID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.logger.Error("统计店铺商品数量失败", zap.Error(err))
+		return nil, 0, err
+	}
+	var products []model.Product
+	if err := query.Order("recommend_sort ASC, id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&products).Error; err != nil {
+		r.logger.Error("查询店铺商品失败", zap.Error(err))
+		return nil, 0, err
+	}
+	productsDTO := make([]model.ProductListItemDTO, 0, len(products))
+	for _, product := range products {
+		productsDTO = append(productsDTO, model.ProductListItemDTO{
+			ProductID:            product.ID,
+			ProductName:          product.ProductName,
+			ProductCurrentPrice:  product.CurrentPrice,
+			ProductOriginalPrice: product.OriginPrice,
+			ProductSpec:          product.Specification,
+			ProductSales:         product.Sales,
+			ProductStock:         product.Stock,
+			ProductSpecification: product.Specification,
+			HeaderImg:            product.HeaderImg,
+			ProductIsSpecial:     product.IsSpecial,
+			CourierFeeMin:        product.CourierFeeMin,
+			CourierFeeMax:        product.CourierFeeMax,
+			MemberDiscount:       product.MemberDiscount,
+		})
+	}
+	return productsDTO, total, nil
+}
@@ -13,6 +13,7 @@ type UserAddressRepository interface {
 	AddAddress(ctx context.Context, userID string, address model.AddAddressRequest) error
 	UpdateAddress(ctx context.Context, userID string, address model.UpdateAddressRequest) error
 	GetUserAddresses(ctx context.Context, userID string) ([]model.UserAddress, error)
+	GetUserAddress(ctx context.Context, userID string, addressID uint64) (*model.UserAddress, error)
 	DeleteAddress(ctx context.Context, userID string, addressID uint64) error
 }
 
@@ -98,6 +99,15 @@ func (r *userAddressRepository) GetUserAddresses(ctx context.Context, userID str
 	return addresses, nil
 }
 
+// GetUserAddress 获取用户的某个收货地址
+func (r *userAddressRepository) GetUserAddress(ctx context.Context, userID string, addressID uint64) (*model.UserAddress, error) {
+	var address model.UserAddress
+	if err := r.DB(ctx).Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
 func (r *userAddressRepository) DeleteAddress(ctx context.Context, userID string, addressID uint64) error {
 	// Check if the address belongs to the user and delete it
 	result := r.DB(ctx).Where("id = ? AND user_id = ?", addressID, userID).Delete(&model.UserAddress{})
@@ -5,35 +5,32 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/goccy/go-json"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"app/internal/common"
 	"app/internal/model"
 	"app/pkg/config"
+	"app/pkg/money"
 )
 
 type UserAssetRepository interface {
 	Create(ctx context.Context, userResource *model.UserAsset) error
 	GetUserAsset(ctx context.Context, userId string) (*model.UserAsset, error)
-	UpdateUserAsset(ctx context.Context, tx *gorm.DB, userId string, bizType, actionType, assetType int8, rewardNum float32, relationId int, relationTitle string) error
-	RechargeBalance(ctx context.Context, userID string, amount float64) error
-	WithdrawBalance(ctx context.Context, userID string, amount float64) error
+	WithdrawBalance(ctx context.Context, userID string, amount money.Money) error
+	AddConsumption(ctx context.Context, tx *gorm.DB, userID string, amount money.Money) error
 }
 
 func NewUserAssetRepository(
 	repository *Repository,
-	userAssetRecordRepository UserAssetRecordRepository,
 ) UserAssetRepository {
 	return &userAssetRepository{
-		Repository:                repository,
-		userAssetRecordRepository: userAssetRecordRepository,
+		Repository: repository,
 	}
 }
 
 type userAssetRepository struct {
 	*Repository
-	userAssetRecordRepository UserAssetRecordRepository
 }
 
 func (r *userAssetRepository) Create(ctx context.Context, userResource *model.UserAsset) error {
@@ -54,144 +51,7 @@ func (r *userAssetRepository) GetUserAsset(ctx context.Context, userId string) (
 	return &userAsset, nil
 }
 
-func (r *userAssetRepository) UpdateUserAsset(
-	ctx context.Context, tx *gorm.DB, userId string, bizType, actionType,
-	assetType int8, rewardNum float32, relationId int, relationTitle string,
-) error {
-	var userAsset model.UserAsset
-	if err := tx.Where("user_id = ?", userId).First(&userAsset).Error; err != nil {
-		r.logger.Debug("First error info: " + err.Error())
-		return err
-	}
-	// 资产是否够
-	if assetType == common.ASSET_TYPE_POINT {
-		if rewardNum < 0 && userAsset.Points < userAsset.Points+int(rewardNum) {
-			return fmt.Errorf("积分不足")
-		}
-	} else if assetType == common.ASSET_TYPE_BALANCE {
-		if rewardNum < 0 && userAsset.Balance < userAsset.Balance+float64(rewardNum) {
-			return fmt.Errorf("余额不足")
-		}
-	}
-	leftNum := float64(0)
-	switch assetType {
-	case common.ASSET_TYPE_POINT:
-		userAsset.Points += int(rewardNum)
-		leftNum = float64(userAsset.Points)
-	case common.ASSET_TYPE_BALANCE:
-		userAsset.Balance += float64(rewardNum)
-		leftNum = float64(userAsset.Balance)
-	}
-	userAsset.UpdatedAt = time.Now()
-	// 使用事务
-	if err := tx.Model(&model.UserAsset{}).Where("user_id = ?", userId).Updates(
-		map[string]interface{}{
-			"points":     userAsset.Points,
-			"balance":    userAsset.Balance,
-			"updated_at": userAsset.UpdatedAt,
-		},
-	).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-	// 更新资产
-	if err := tx.Model(&model.UserAsset{}).Where(
-		"user_id = ?", userId,
-	).Save(&userAsset).Error; err != nil {
-		tx.Rollback()
-		r.logger.Debug("Model Updates error info: " + err.Error())
-		return err
-	}
-	// 添加资产更新记录
-	if err := r.userAssetRecordRepository.CreateUserAssetRecord(
-		ctx,
-		&model.UserAssetRecord{
-			UserId:        userId,
-			BusinessType:  bizType,
-			ActionType:    actionType,
-			AssetType:     assetType,
-			ActionNum:     float32(rewardNum),
-			LeftNum:       float32(leftNum),
-			RelationId:    relationId,
-			RelationTitle: relationTitle,
-		},
-	); err != nil {
-		tx.Rollback()
-		r.logger.Debug("Create error info: ", err.Error())
-		return err
-	}
-
-	userAssetBytes, err := json.Marshal(userAsset)
-	if err != nil {
-		r.logger.Debug("json.Marshal error info: " + err.Error())
-		return err
-	}
-	if err = config.Rdb.Set(ctx, common.PREFFIX_USER_ASSET+userId, string(userAssetBytes), 24*time.Hour).Err(); err != nil {
-		r.logger.Debug("Set error info: " + err.Error())
-		return err
-	}
-	return nil
-}
-
-func (r *userAssetRepository) RechargeBalance(ctx context.Context, userID string, amount float64) error {
-	// 获取当前用户资产
-	userAsset, err := r.GetUserAsset(ctx, userID)
-	if err != nil {
-		return err
-	}
-
-	// 保存到数据库
-	tx := r.DB(ctx).Begin()
-	if err := tx.Model(&model.UserAsset{}).Where("user_id = ?", userID).Updates(
-		map[string]interface{}{
-			"balance":    userAsset.Balance,
-			"updated_at": userAsset.UpdatedAt,
-		},
-	).Error; err != nil {
-		r.logger.Debug("充值更新余额失败: " + err.Error())
-		tx.Rollback()
-		return err
-	}
-
-	// 添加资产更新记录
-	if err := r.UpdateUserAsset(
-		ctx,
-		tx,
-		userID,
-		common.BUSINESS_TYPE_RECHARGE,
-		common.ACTION_TYPE_RECHARGE,
-		common.ASSET_TYPE_BALANCE,
-		float32(amount),
-		0,
-		"",
-	); err != nil {
-		r.logger.Debug("创建充值记录失败: " + err.Error())
-		tx.Rollback()
-		return err
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		r.logger.Debug("提交事务失败: " + err.Error())
-		return err
-	}
-
-	// 更新缓存
-	userAssetBytes, err := json.Marshal(userAsset)
-	if err != nil {
-		r.logger.Debug("序列化用户资产失败: " + err.Error())
-		return err
-	}
-	if err = config.Rdb.Set(
-		ctx, common.PREFFIX_USER_ASSET+userID, string(userAssetBytes), 24*time.Hour,
-	).Err(); err != nil {
-		r.logger.Debug("更新缓存失败: " + err.Error())
-		return err
-	}
-
-	return nil
-}
-
-func (r *userAssetRepository) WithdrawBalance(ctx context.Context, userID string, amount float64) error {
+func (r *userAssetRepository) WithdrawBalance(ctx context.Context, userID string, amount money.Money) error {
 	// 获取当前用户资产
 	userAsset, err := r.GetUserAsset(ctx, userID)
 	if err != nil {
@@ -253,3 +113,25 @@ func (r *userAssetRepository) WithdrawBalance(ctx context.Context, userID string
 
 	return nil
 }
+
+// AddConsumption 累加用户消费金额，支付时为正、退款时为负，扣减后不低于0；tx 为空时使用当前上下文的数据库连接
+func (r *userAssetRepository) AddConsumption(ctx context.Context, tx *gorm.DB, userID string, amount money.Money) error {
+	if amount == 0 {
+		return nil
+	}
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	if err := tx.Model(&model.UserAsset{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"consumption": gorm.Expr("GREATEST(consumption + ?, 0)", amount.Fen()),
+			"updated_at":  time.Now(),
+		}).Error; err != nil {
+		r.logger.Error("更新用户消费金额失败", zap.Error(err))
+		return err
+	}
+	if err := config.Rdb.Del(ctx, common.PREFFIX_USER_ASSET+userID).Err(); err != nil {
+		r.logger.Debug("删除用户资产缓存失败", zap.Error(err))
+	}
+	return nil
+}
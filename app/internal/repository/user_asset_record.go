@@ -84,8 +84,8 @@ func (r *userAssetRecordRepository) GetBalanceRecords(ctx context.Context, userI
 			BusinessType: record.BusinessType,
 			ActionType:   record.ActionType,
 			AssetType:    record.AssetType,
-			ActionNum:    record.ActionNum,
-			LeftNum:      record.LeftNum,
+			ActionNum:    record.DisplayNum(record.ActionNum),
+			LeftNum:      record.DisplayNum(record.LeftNum),
 			CreatedAt:    record.CreatedAt.Format("2006-01-02 15:04"),
 		})
 	}
@@ -139,8 +139,8 @@ func (r *userAssetRecordRepository) GetWithdrawRecords(ctx context.Context, user
 			BusinessType: record.BusinessType,
 			ActionType:   record.ActionType,
 			AssetType:    record.AssetType,
-			ActionNum:    record.ActionNum,
-			LeftNum:      record.LeftNum,
+			ActionNum:    record.DisplayNum(record.ActionNum),
+			LeftNum:      record.DisplayNum(record.LeftNum),
 			CreatedAt:    record.CreatedAt.Format("2006-01-02 15:04"),
 		})
 	}
@@ -217,10 +217,23 @@ func (r *userAssetRecordRepository) getActionTypeString(businessType int8, actio
 			return "购买(" + relationTitle + ")"
 		case common.ACTION_TYPE_REFUND:
 			return "退款(" + relationTitle + ")"
+		case common.ACTION_TYPE_REWARD:
+			return "购物奖励(" + relationTitle + ")"
 		}
+	case common.BUSINESS_TYPE_REVIEW:
+		return "晒图评价"
+	case common.BUSINESS_TYPE_CHECK_IN:
+		return "每日签到"
+	case common.BUSINESS_TYPE_FIRST_PURCHASE:
+		return "首单奖励"
+	case common.BUSINESS_TYPE_POINT_EXPIRE:
+		return "积分过期"
 	case common.BUSINESS_TYPE_RECHARGE:
 		return "充值"
 	case common.BUSINESS_TYPE_WITHDRAW:
+		if actionType == common.ACTION_TYPE_REFUND {
+			return "提现退回"
+		}
 		return "提现"
 	case common.BUSINESS_TYPE_EXCHANGE:
 		return "积分兑换"
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 
+	v1 "app/api/v1"
+	"app/internal/common"
 	"app/internal/model"
 
 	"gorm.io/gorm"
@@ -13,18 +15,22 @@ type UserCartRepository interface {
 	GetUserCartItems(ctx context.Context, userID string) (model.CartResponse, error)
 	DeleteCartItems(ctx context.Context, userID string, cartIDs []uint) error
 	GetCartList(ctx context.Context, userID string, productIds []uint64) ([]model.CartProductDTO, error)
+	GetCartsByIDs(ctx context.Context, userID string, cartIDs []uint64) ([]model.UserCart, error)
 }
 
 func NewUserCartRepository(
 	repository *Repository,
+	storeRepository StoreRepository,
 ) UserCartRepository {
 	return &userCartRepository{
-		Repository: repository,
+		Repository:      repository,
+		storeRepository: storeRepository,
 	}
 }
 
 type userCartRepository struct {
 	*Repository
+	storeRepository StoreRepository
 }
 
 func (r *userCartRepository) AddToCart(ctx context.Context, userID string, productID uint64, quantity int, couponID uint64) error {
@@ -42,6 +48,14 @@ func (r *userCartRepository) AddToCart(ctx context.Context, userID string, produ
 
 		// Try to find the existing cart item
 		cartResult := tx.Where("user_id = ? AND product_id = ? AND status = 0", userID, productID).First(&cart)
+		// 校验库存，购物车中已有的数量一并计算
+		cartQuantity := 0
+		if cartResult.Error == nil {
+			cartQuantity = cart.Quantity
+		}
+		if product.Stock != common.PRODUCT_STOCK_UNLIMITED && cartQuantity+quantity > product.Stock {
+			return v1.ErrStockNotEnough
+		}
 		if cartResult.Error != nil {
 			var coupon model.ProductCoupon
 			if cartResult.Error == gorm.ErrRecordNotFound {
@@ -64,7 +78,7 @@ func (r *userCartRepository) AddToCart(ctx context.Context, userID string, produ
 					StoreID:      product.StoreID,
 					StoreName:    product.StoreName,
 					CouponID:     couponID,
-					CouponPrice:  coupon.CouponPrice,
+					CouponPrice:  coupon.CouponPrice.Yuan(),
 				}
 				return tx.Create(&newCart).Error
 			}
@@ -91,6 +105,16 @@ func (r *userCartRepository) GetUserCartItems(ctx context.Context, userID string
 		return nil, err
 	}
 
+	// 查询购物车商品所属店铺的最新信息
+	storeIds := make([]uint64, 0, len(cartItems))
+	for _, item := range cartItems {
+		storeIds = append(storeIds, uint64(item.StoreID))
+	}
+	stores, err := r.storeRepository.GetStoresByIDs(ctx, storeIds)
+	if err != nil {
+		return nil, err
+	}
+
 	// Group cart items by store
 	storeMap := make(map[uint64]*model.CartStoreDTO)
 
@@ -110,6 +134,10 @@ func (r *userCartRepository) GetUserCartItems(ctx context.Context, userID string
 				StoreURL:  "", // You may need to get this from somewhere else
 				List:      []model.CartProductDTO{},
 			}
+			if info, ok := stores[uint64(item.StoreID)]; ok {
+				store.StoreName = info.Name
+				store.StoreLogo = info.Logo
+			}
 			storeMap[uint64(item.StoreID)] = store
 		}
 
@@ -180,3 +208,22 @@ func (r *userCartRepository) GetCartList(ctx context.Context, userID string, pro
 
 	return cartList, nil
 }
+
+// GetCartsByIDs 按购物车ID获取用户未结算的购物车商品，顺序与 cartIDs 一致，不属于该用户的忽略
+func (r *userCartRepository) GetCartsByIDs(ctx context.Context, userID string, cartIDs []uint64) ([]model.UserCart, error) {
+	var carts []model.UserCart
+	if err := r.DB(ctx).Where("id IN ? AND user_id = ? AND status = 0", cartIDs, userID).Find(&carts).Error; err != nil {
+		return nil, err
+	}
+	cartMap := make(map[uint64]model.UserCart, len(carts))
+	for _, cart := range carts {
+		cartMap[uint64(cart.ID)] = cart
+	}
+	result := make([]model.UserCart, 0, len(carts))
+	for _, cartID := range cartIDs {
+		if cart, ok := cartMap[cartID]; ok {
+			result = append(result, cart)
+		}
+	}
+	return result, nil
+}
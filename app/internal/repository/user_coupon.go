@@ -1,22 +1,44 @@
 package repository
 
 import (
+	v1 "app/api/v1"
+	"app/internal/common"
 	"app/internal/model"
+	"app/pkg/money"
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// couponRemindBefore 优惠券过期前多久提醒
+	couponRemindBefore = 24 * time.Hour
+	// couponRemindBatchSize 每次最多提醒的优惠券数量，剩余的下次任务继续处理
+	couponRemindBatchSize = 1000
 )
 
 // UserCouponRepository interface for user coupon operations
 type UserCouponRepository interface {
 	GetUserCoupons(ctx context.Context, userID string, productID uint64) ([]model.UserCoupon, error)
-	GetAllUserCoupons(ctx context.Context, userID string) (map[uint64][]model.ProductCouponDTO, error)
+	GetAllUserCoupons(ctx context.Context, userID string, status *uint8) (map[uint64][]model.ProductCouponDTO, error)
 	ClaimCoupon(ctx context.Context, userID string, couponID uint64) error
 	GetUserCouponCount(ctx context.Context, userID string) (int, error)
 	GetUserCouponByID(ctx context.Context, userID string, couponID uint64) (*model.CouponDetailResponse, error)
+	ValidateCoupon(ctx context.Context, userID string, couponID uint64, item *model.OrderItemQuote) (*model.CouponApplication, error)
+	LockCoupon(ctx context.Context, tx *gorm.DB, userCouponID uint64, orderItem *model.UserOrderItem) error
+	ReleaseCoupon(ctx context.Context, tx *gorm.DB, orderItem *model.UserOrderItem) error
+	ExpireCoupons(ctx context.Context) (int64, error)
+	RemindExpiringCoupons(ctx context.Context) ([]model.CouponExpiringNotice, error)
+	MarkCouponsReminded(ctx context.Context, ids []uint64) error
+	RecommendCoupons(ctx context.Context, userID string, items []model.OrderItemQuote) (*model.CouponRecommendResponse, error)
 }
 
 // Implementation
@@ -43,120 +65,184 @@ func (r *userCouponRepository) GetUserCoupons(ctx context.Context, userID string
 	return coupons, nil
 }
 
-// GetAllUserCoupons retrieves all coupons for a specific user
-func (r *userCouponRepository) GetAllUserCoupons(ctx context.Context, userID string) (map[uint64][]model.ProductCouponDTO, error) {
-	var userCoupons []*model.UserCoupon
-	userCouponMap := make(map[uint64]uint8)
-
-	// 构建查询条件
-	query := r.DB(ctx).Where("user_id = ?", userID)
-	// 执行查询
-	result := query.Model(&model.UserCoupon{}).Find(&userCoupons)
-	if result.Error != nil {
-		return nil, result.Error
+// GetAllUserCoupons 获取用户的优惠券并按状态分组，status 不为空时只返回该状态的优惠券
+// 已过截止时间但过期任务尚未处理的优惠券按已过期返回
+func (r *userCouponRepository) GetAllUserCoupons(ctx context.Context, userID string, status *uint8) (map[uint64][]model.ProductCouponDTO, error) {
+	var userCoupons []model.UserCoupon
+	if err := r.DB(ctx).Where("user_id = ?", userID).Order("deadline ASC, id DESC").Find(&userCoupons).Error; err != nil {
+		r.logger.Error("查询用户优惠券失败", zap.Error(err))
+		return nil, err
 	}
 
-	// 获取所有的券ID
-	couponIDs := []uint64{}
+	// 获取优惠券模板，兑换券没有模板
+	templateIds := make([]uint64, 0, len(userCoupons))
 	for _, userCoupon := range userCoupons {
-		couponIDs = append(couponIDs, userCoupon.CouponID)
-		userCouponMap[userCoupon.CouponID] = userCoupon.Status
-	}
-
-	// 如果没有优惠券，直接返回空数组
-	if len(couponIDs) == 0 {
-		return map[uint64][]model.ProductCouponDTO{}, nil
+		if userCoupon.Type == 1 {
+			templateIds = append(templateIds, userCoupon.CouponID)
+		}
 	}
-
-	// 获取所有的券
-	coupons := []model.ProductCoupon{}
-	result = r.DB(ctx).Where("id IN (?)", couponIDs).Find(&coupons)
-	if result.Error != nil {
-		return nil, result.Error
+	templateMap := make(map[uint64]model.ProductCoupon)
+	if len(templateIds) > 0 {
+		var templates []model.ProductCoupon
+		if err := r.DB(ctx).Unscoped().Where("id IN ?", templateIds).Find(&templates).Error; err != nil {
+			r.logger.Error("查询优惠券模板失败", zap.Error(err))
+			return nil, err
+		}
+		for _, template := range templates {
+			templateMap[uint64(template.ID)] = template
+		}
 	}
 
+	now := time.Now()
 	productCouponsDTOMap := make(map[uint64][]model.ProductCouponDTO)
-	for _, coupon := range coupons {
+	for _, userCoupon := range userCoupons {
+		couponStatus := userCoupon.Status
+		if couponStatus == common.USER_COUPON_STATUS_UNUSED && !userCoupon.Deadline.IsZero() && userCoupon.Deadline.Before(now) {
+			couponStatus = common.USER_COUPON_STATUS_EXPIRED
+		}
+		if status != nil && *status != couponStatus {
+			continue
+		}
 		deadlineStr := ""
-		if coupon.Deadline != nil {
-			deadlineStr = coupon.Deadline.Format("2006-01-02")
+		if !userCoupon.Deadline.IsZero() {
+			deadlineStr = userCoupon.Deadline.Format("2006-01-02")
 		}
-
 		couponDTO := model.ProductCouponDTO{
-			CouponID:          coupon.ID,
-			CouponName:        coupon.CouponName,
-			CouponPrice:       coupon.CouponPrice,
-			AvailableMinPrice: coupon.AvailableMinPrice,
+			UserCouponID:      userCoupon.ID,
+			CouponID:          uint(userCoupon.CouponID),
+			CouponName:        userCoupon.CouponName,
+			CouponPrice:       userCoupon.CouponPrice,
+			Kind:              common.COUPON_KIND_FIXED,
+			AvailableMinPrice: userCoupon.AvailableMinPrice,
+			ScopeType:         common.COUPON_SCOPE_ALL,
 			Deadline:          deadlineStr,
-			ProductID:         uint(coupon.ProductID),
-		}
-		couponStatus := 0
-		// 是否过期
-		if coupon.Deadline != nil && coupon.Deadline.Before(time.Now()) {
-			couponStatus = 2
-		} else if v, ok := userCouponMap[uint64(coupon.ID)]; ok {
-			couponStatus = int(v)
-		}
-		couponDTO.IsReceived = couponStatus
-		if _, ok := productCouponsDTOMap[uint64(couponStatus)]; !ok {
-			productCouponsDTOMap[uint64(couponStatus)] = []model.ProductCouponDTO{
-				couponDTO,
-			}
-		} else {
-			productCouponsDTOMap[uint64(coupon.Status)] = append(productCouponsDTOMap[uint64(coupon.Status)], couponDTO)
+			IsReceived:        int(couponStatus),
+			ProductID:         uint(userCoupon.ProductID),
+		}
+		if template, ok := templateMap[userCoupon.CouponID]; ok && userCoupon.Type == 1 {
+			couponDTO.Kind = template.Kind
+			couponDTO.DiscountRate = template.DiscountRate
+			couponDTO.MaxDiscount = template.MaxDiscount
+			couponDTO.ScopeType = template.ScopeType
+		} else if userCoupon.ProductID > 0 {
+			couponDTO.ScopeType = common.COUPON_SCOPE_PRODUCT
 		}
+		productCouponsDTOMap[uint64(couponStatus)] = append(productCouponsDTOMap[uint64(couponStatus)], couponDTO)
 	}
 
 	return productCouponsDTOMap, nil
 }
 
-// ClaimCoupon claims a coupon for a user
+// ClaimCoupon 领取优惠券，每次领取无论成功与否都写入领取记录
 func (r *userCouponRepository) ClaimCoupon(ctx context.Context, userID string, couponID uint64) error {
-	// Check if the user has already claimed this coupon
-	var count int64
-	if err := r.DB(ctx).Model(&model.UserCoupon{}).
-		Where("user_id = ? AND coupone_id = ?", userID, couponID).
-		Count(&count).Error; err == nil && count > 0 {
-		return fmt.Errorf("用户已经领取过该优惠券")
+	userCouponID, err := r.claimCoupon(ctx, userID, couponID)
+	claimLog := model.CouponClaimLog{
+		CouponID:     couponID,
+		UserID:       userID,
+		UserCouponID: userCouponID,
+		Result:       common.COUPON_CLAIM_RESULT_SUCCESS,
+	}
+	if err != nil {
+		claimLog.Result = common.COUPON_CLAIM_RESULT_FAILED
+		claimLog.Reason = err.Error()
+	}
+	if logErr := r.DB(ctx).Create(&claimLog).Error; logErr != nil {
+		r.logger.Error("记录优惠券领取结果失败", zap.Uint64("coupon_id", couponID), zap.String("user_id", userID), zap.Error(logErr))
 	}
+	return err
+}
 
+// claimCoupon 通过按条件更新扣减每人限领次数和发放总量，并发领取时不会超发
+func (r *userCouponRepository) claimCoupon(ctx context.Context, userID string, couponID uint64) (uint64, error) {
 	// 检查优惠券是否存在
 	var coupon model.ProductCoupon
 	if err := r.DB(ctx).Where("id = ?", couponID).First(&coupon).Error; err != nil {
-		return fmt.Errorf("优惠券不存在")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("优惠券不存在")
+		}
+		r.logger.Error("查询优惠券失败", zap.Error(err))
+		return 0, err
+	}
+
+	now := time.Now()
+	if (coupon.ClaimStartAt != nil && coupon.ClaimStartAt.After(now)) || (coupon.ClaimEndAt != nil && coupon.ClaimEndAt.Before(now)) {
+		return 0, fmt.Errorf("不在优惠券领取时间内")
+	}
+	// 有效期：配置了领取后有效天数的从领取时开始计算，但不超过模板的截止时间
+	if coupon.Deadline != nil && coupon.Deadline.Before(now) {
+		return 0, fmt.Errorf("优惠券已过期")
+	}
+	var deadline time.Time
+	if coupon.Deadline != nil {
+		deadline = *coupon.Deadline
+	}
+	if coupon.ValidDays > 0 {
+		validUntil := now.AddDate(0, 0, coupon.ValidDays)
+		if deadline.IsZero() || validUntil.Before(deadline) {
+			deadline = validUntil
+		}
 	}
 
-	// Create new user coupon record
 	userCoupon := model.UserCoupon{
 		UserID:            userID,
 		CouponID:          couponID,
 		ProductID:         coupon.ProductID,
 		Type:              1,
-		Status:            0,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
+		Status:            common.USER_COUPON_STATUS_UNUSED,
+		CreatedAt:         now,
+		UpdatedAt:         now,
 		CouponName:        coupon.CouponName,
 		CouponPrice:       coupon.CouponPrice,
 		AvailableMinPrice: coupon.AvailableMinPrice,
-		Deadline:          *coupon.Deadline,
+		Deadline:          deadline,
+		StartAt:           coupon.StartAt,
 	}
-
-	// Save to database
 	if err := r.Transaction(ctx, func(ctx context.Context) error {
-		if err := r.DB(ctx).Create(&userCoupon).Error; err != nil {
+		// 每人限领
+		if err := r.DB(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.UserCouponClaim{UserID: userID, CouponID: couponID}).Error; err != nil {
+			r.logger.Error("创建领取次数记录失败", zap.Error(err))
 			return err
 		}
-		// 更新优惠券状态
-		if err := r.DB(ctx).Model(&model.ProductCoupon{}).Where("id = ?", couponID).Update("status", 1).Error; err != nil {
+		claimQuery := r.DB(ctx).Model(&model.UserCouponClaim{}).Where("user_id = ? AND coupon_id = ?", userID, couponID)
+		if coupon.PerUserLimit > 0 {
+			claimQuery = claimQuery.Where("claim_count < ?", coupon.PerUserLimit)
+		}
+		result := claimQuery.Updates(map[string]interface{}{
+			"claim_count": gorm.Expr("claim_count + 1"),
+			"updated_at":  now,
+		})
+		if result.Error != nil {
+			r.logger.Error("更新领取次数失败", zap.Error(result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("已达到该优惠券的领取上限")
+		}
+
+		// 发放总量
+		stockQuery := r.DB(ctx).Model(&model.ProductCoupon{}).Where("id = ?", couponID)
+		if coupon.TotalQuantity > 0 {
+			stockQuery = stockQuery.Where("claimed_quantity < total_quantity")
+		}
+		result = stockQuery.Update("claimed_quantity", gorm.Expr("claimed_quantity + 1"))
+		if result.Error != nil {
+			r.logger.Error("更新优惠券领取数量失败", zap.Error(result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("优惠券已领完")
+		}
+
+		if err := r.DB(ctx).Create(&userCoupon).Error; err != nil {
+			r.logger.Error("创建用户优惠券失败", zap.Error(err))
 			return err
 		}
 		return nil
 	}); err != nil {
-		r.logger.Debug("Transaction error info: " + err.Error())
-		return err
+		return 0, err
 	}
-
-	return nil
+	return userCoupon.ID, nil
 }
 
 func (r *userCouponRepository) GetUserCouponCount(ctx context.Context, userID string) (int, error) {
@@ -178,15 +264,521 @@ func (r *userCouponRepository) GetUserCouponByID(ctx context.Context, userID str
 		return nil, err
 	}
 
-	return &model.CouponDetailResponse{
+	response := &model.CouponDetailResponse{
 		ID:                coupon.ID,
 		CouponName:        coupon.CouponName,
 		CouponPrice:       coupon.CouponPrice,
 		AvailableMinPrice: coupon.AvailableMinPrice,
 		Type:              coupon.Type,
+		Kind:              common.COUPON_KIND_FIXED,
 		Status:            coupon.Status,
 		ProductID:         coupon.ProductID,
+		StartAt:           coupon.StartAt,
 		Deadline:          coupon.Deadline,
+		OrderNo:           coupon.OrderNo,
 		CreatedAt:         coupon.CreatedAt,
-	}, nil
+	}
+	if coupon.Type == 1 {
+		var template model.ProductCoupon
+		if err := r.DB(ctx).Unscoped().Where("id = ?", coupon.CouponID).First(&template).Error; err == nil {
+			response.Kind = template.Kind
+			response.DiscountRate = template.DiscountRate
+			response.MaxDiscount = template.MaxDiscount
+		}
+	}
+	return response, nil
+}
+
+// ValidateCoupon 校验用户优惠券能否用于订单项并计算抵扣金额
+// 同一模板领取了多张时使用未过期中最早过期的一张，已过期但尚未被任务标记的不参与选择
+func (r *userCouponRepository) ValidateCoupon(ctx context.Context, userID string, couponID uint64, item *model.OrderItemQuote) (*model.CouponApplication, error) {
+	now := time.Now()
+	var coupon model.UserCoupon
+	if err := r.DB(ctx).Where("user_id = ? AND coupon_id = ? AND status = ?", userID, couponID, common.USER_COUPON_STATUS_UNUSED).
+		Where("deadline = ? OR deadline >= ?", time.Time{}, now).
+		Order("deadline ASC").
+		First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, v1.ErrCouponUnavailable
+		}
+		r.logger.Error("查询用户优惠券失败", zap.Error(err))
+		return nil, err
+	}
+	var template *model.ProductCoupon
+	if coupon.Type == 1 {
+		template = &model.ProductCoupon{}
+		if err := r.DB(ctx).Where("id = ?", coupon.CouponID).First(template).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, v1.ErrCouponUnavailable
+			}
+			r.logger.Error("查询优惠券模板失败", zap.Error(err))
+			return nil, err
+		}
+	}
+	application, reason := applyCoupon(&coupon, template, item, now)
+	if reason != "" {
+		return nil, fmt.Errorf("%w，%s", v1.ErrCouponUnavailable, reason)
+	}
+	return application, nil
+}
+
+// LockCoupon 下单时将优惠券锁定到订单项，订单取消或超时后释放
+func (r *userCouponRepository) LockCoupon(ctx context.Context, tx *gorm.DB, userCouponID uint64, orderItem *model.UserOrderItem) error {
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	result := tx.Model(&model.UserCoupon{}).
+		Where("id = ? AND user_id = ? AND status = ?", userCouponID, orderItem.UserId, common.USER_COUPON_STATUS_UNUSED).
+		Updates(map[string]interface{}{
+			"status":        common.USER_COUPON_STATUS_USED,
+			"order_item_id": orderItem.ID,
+			"order_no":      orderItem.OrderNo,
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		r.logger.Error("锁定优惠券失败", zap.Error(result.Error))
+		return result.Error
+	}
+	// 优惠券已被其他订单占用
+	if result.RowsAffected == 0 {
+		return v1.ErrCouponUnavailable
+	}
+	return nil
+}
+
+// ReleaseCoupon 释放订单项锁定的优惠券
+func (r *userCouponRepository) ReleaseCoupon(ctx context.Context, tx *gorm.DB, orderItem *model.UserOrderItem) error {
+	if orderItem.CouponID == 0 {
+		return nil
+	}
+	if tx == nil {
+		tx = r.DB(ctx)
+	}
+	release := map[string]interface{}{
+		"status":        common.USER_COUPON_STATUS_UNUSED,
+		"order_item_id": 0,
+		"order_no":      "",
+		"updated_at":    time.Now(),
+	}
+	result := tx.Model(&model.UserCoupon{}).
+		Where("order_item_id = ? AND status = ?", orderItem.ID, common.USER_COUPON_STATUS_USED).
+		Updates(release)
+	if result.Error != nil {
+		r.logger.Error("释放优惠券失败", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// 锁定到订单项之前下的订单，按优惠券ID释放最近使用的一张
+	var coupon model.UserCoupon
+	if err := tx.Where("user_id = ? AND coupon_id = ? AND status = ? AND order_item_id = ?",
+		orderItem.UserId, orderItem.CouponID, common.USER_COUPON_STATUS_USED, 0).
+		Order("updated_at DESC").
+		First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		r.logger.Error("查询订单优惠券失败", zap.Error(err))
+		return err
+	}
+	if err := tx.Model(&model.UserCoupon{}).Where("id = ? AND status = ?", coupon.ID, common.USER_COUPON_STATUS_USED).
+		Updates(release).Error; err != nil {
+		r.logger.Error("释放优惠券失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ExpireCoupons 将已过截止时间仍未使用的优惠券标记为已过期
+func (r *userCouponRepository) ExpireCoupons(ctx context.Context) (int64, error) {
+	result := r.DB(ctx).Model(&model.UserCoupon{}).
+		Where("status = ? AND deadline > ? AND deadline < ?", common.USER_COUPON_STATUS_UNUSED, time.Time{}, time.Now()).
+		Updates(map[string]interface{}{
+			"status":     common.USER_COUPON_STATUS_EXPIRED,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		r.logger.Error("标记优惠券过期失败", zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// RemindExpiringCoupons 汇总24小时内过期且未提醒过的优惠券，按用户生成提醒
+// 推送成功后由调用方通过 MarkCouponsReminded 标记已提醒，推送失败的优惠券下次任务仍会提醒
+func (r *userCouponRepository) RemindExpiringCoupons(ctx context.Context) ([]model.CouponExpiringNotice, error) {
+	now := time.Now()
+	var coupons []model.UserCoupon
+	if err := r.DB(ctx).Where("status = ? AND expire_reminded = 0 AND deadline > ? AND deadline <= ?",
+		common.USER_COUPON_STATUS_UNUSED, now, now.Add(couponRemindBefore)).
+		Order("deadline ASC").Limit(couponRemindBatchSize).Find(&coupons).Error; err != nil {
+		r.logger.Error("查询即将过期优惠券失败", zap.Error(err))
+		return nil, err
+	}
+	if len(coupons) == 0 {
+		return nil, nil
+	}
+
+	noticeIndex := make(map[string]int)
+	notices := make([]model.CouponExpiringNotice, 0)
+	for _, coupon := range coupons {
+		i, ok := noticeIndex[coupon.UserID]
+		if !ok {
+			// 按截止时间排序，第一张即最早过期时间
+			i = len(notices)
+			noticeIndex[coupon.UserID] = i
+			notices = append(notices, model.CouponExpiringNotice{
+				Module:   common.MODULE_TYPE_COUPON,
+				UserID:   coupon.UserID,
+				ExpireAt: coupon.Deadline,
+			})
+		}
+		notices[i].IDs = append(notices[i].IDs, coupon.ID)
+		notices[i].Count++
+	}
+	for i := range notices {
+		notices[i].Message = fmt.Sprintf("您有%d张优惠券将于%s过期，请尽快使用",
+			notices[i].Count, notices[i].ExpireAt.Format("2006-01-02 15:04"))
+	}
+	return notices, nil
+}
+
+// MarkCouponsReminded 将已推送提醒的用户优惠券标记为已提醒
+func (r *userCouponRepository) MarkCouponsReminded(ctx context.Context, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.DB(ctx).Model(&model.UserCoupon{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"expire_reminded": 1,
+		"updated_at":      time.Now(),
+	}).Error; err != nil {
+		r.logger.Error("标记优惠券过期提醒失败", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RecommendCoupons 在用户可用的优惠券中选出总优惠最大的组合
+// 规则与下单一致：每个商品最多使用一张优惠券，同一优惠券每单只能用一张，独占的优惠券不能与其他优惠券同单使用
+func (r *userCouponRepository) RecommendCoupons(ctx context.Context, userID string, items []model.OrderItemQuote) (*model.CouponRecommendResponse, error) {
+	now := time.Now()
+	var userCoupons []model.UserCoupon
+	if err := r.DB(ctx).Where("user_id = ? AND status = ?", userID, common.USER_COUPON_STATUS_UNUSED).
+		Where("deadline = ? OR deadline >= ?", time.Time{}, now).
+		Order("deadline ASC, id ASC").Find(&userCoupons).Error; err != nil {
+		r.logger.Error("查询用户优惠券失败", zap.Error(err))
+		return nil, err
+	}
+	// 同一优惠券领取了多张时只取未过期中最早过期的一张，与下单时的选择一致
+	coupons := make([]model.UserCoupon, 0, len(userCoupons))
+	seen := make(map[uint64]bool)
+	templateIds := make([]uint64, 0)
+	for _, coupon := range userCoupons {
+		if seen[coupon.CouponID] {
+			continue
+		}
+		seen[coupon.CouponID] = true
+		coupons = append(coupons, coupon)
+		if coupon.Type == 1 {
+			templateIds = append(templateIds, coupon.CouponID)
+		}
+	}
+	templateMap := make(map[uint64]model.ProductCoupon)
+	if len(templateIds) > 0 {
+		var templates []model.ProductCoupon
+		if err := r.DB(ctx).Where("id IN ?", templateIds).Find(&templates).Error; err != nil {
+			r.logger.Error("查询优惠券模板失败", zap.Error(err))
+			return nil, err
+		}
+		for _, template := range templates {
+			templateMap[uint64(template.ID)] = template
+		}
+	}
+
+	// 计算每张优惠券用在每个商品上比不用时多优惠的金额
+	gains := make([][]int64, len(items))
+	quotes := make([][]*model.OrderItemQuote, len(items))
+	for i := range items {
+		gains[i] = make([]int64, len(coupons))
+		quotes[i] = make([]*model.OrderItemQuote, len(coupons))
+	}
+	reasons := make([]string, len(coupons))
+	exclusive := make([]bool, len(coupons))
+	usable := make([]bool, len(coupons))
+	for j := range coupons {
+		var template *model.ProductCoupon
+		if coupons[j].Type == 1 {
+			t, ok := templateMap[coupons[j].CouponID]
+			if !ok {
+				reasons[j] = "优惠券已失效"
+				continue
+			}
+			template = &t
+		}
+		for i := range items {
+			application, reason := applyCoupon(&coupons[j], template, &items[i], now)
+			if reason != "" {
+				if reasons[j] == "" {
+					reasons[j] = reason
+				}
+				continue
+			}
+			quote := items[i]
+			applyCouponDiscount(&quote, application)
+			gain := quote.CouponPrice - (items[i].MemberDiscount - quote.MemberDiscount)
+			if gain <= 0 {
+				if reasons[j] == "" {
+					reasons[j] = "使用后优惠不如会员折扣"
+				}
+				continue
+			}
+			gains[i][j] = gain.Fen()
+			quotes[i][j] = &quote
+			exclusive[j] = application.Exclusive
+			usable[j] = true
+		}
+	}
+
+	assignment, total, exclusiveChosen := chooseCoupons(gains, exclusive)
+
+	response := &model.CouponRecommendResponse{
+		TotalDiscount: money.FromFen(total),
+		Allocations:   make([]model.CouponAllocation, 0, len(items)),
+		Unusable:      make([]model.UnusableCoupon, 0),
+	}
+	chosen := make([]bool, len(coupons))
+	for i, item := range items {
+		allocation := model.CouponAllocation{
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			MemberDiscount: item.MemberDiscount,
+			TotalFee:       item.GoodsFee + item.CourierFee - item.MemberDiscount,
+		}
+		if j := assignment[i]; j >= 0 {
+			quote := quotes[i][j]
+			chosen[j] = true
+			allocation.CouponID = coupons[j].CouponID
+			allocation.UserCouponID = coupons[j].ID
+			allocation.CouponName = coupons[j].CouponName
+			allocation.CouponPrice = quote.CouponPrice
+			allocation.MemberDiscount = quote.MemberDiscount
+			allocation.TotalFee = quote.GoodsFee + quote.CourierFee - quote.CouponPrice - quote.MemberDiscount
+		}
+		response.Allocations = append(response.Allocations, allocation)
+	}
+	for j, coupon := range coupons {
+		if chosen[j] {
+			continue
+		}
+		reason := reasons[j]
+		if usable[j] {
+			reason = "已选择更优惠的组合"
+			if exclusiveChosen || exclusive[j] {
+				reason = "不可与其他优惠券同时使用"
+			}
+		}
+		response.Unusable = append(response.Unusable, model.UnusableCoupon{
+			UserCouponID: coupon.ID,
+			CouponID:     coupon.CouponID,
+			CouponName:   coupon.CouponName,
+			Reason:       reason,
+		})
+	}
+	return response, nil
+}
+
+// chooseCoupons 按每张优惠券用在每个商品上的优惠金额选出总优惠最大的分配
+// 先求不含独占券的最优分配，单独使用一张独占券更优惠时改用独占券；返回每个商品分配的优惠券，未分配时为-1
+func chooseCoupons(gains [][]int64, exclusive []bool) ([]int, int64, bool) {
+	shared := make([][]int64, len(gains))
+	for i := range gains {
+		shared[i] = make([]int64, len(exclusive))
+		for j := range exclusive {
+			if !exclusive[j] {
+				shared[i][j] = gains[i][j]
+			}
+		}
+	}
+	assignment := maxWeightAssignment(shared, len(exclusive))
+	var total int64
+	for i, j := range assignment {
+		if j >= 0 {
+			total += shared[i][j]
+		}
+	}
+	exclusiveChosen := false
+	for j := range exclusive {
+		if !exclusive[j] {
+			continue
+		}
+		for i := range gains {
+			if gains[i][j] > total {
+				total = gains[i][j]
+				for k := range assignment {
+					assignment[k] = -1
+				}
+				assignment[i] = j
+				exclusiveChosen = true
+			}
+		}
+	}
+	return assignment, total, exclusiveChosen
+}
+
+// maxWeightAssignment 匈牙利算法求每行最多分配一列、每列最多分配一行时权重和最大的分配
+// 返回每行分配的列，未分配或权重为0时为-1
+func maxWeightAssignment(weights [][]int64, cols int) []int {
+	rows := len(weights)
+	n := rows
+	if cols > n {
+		n = cols
+	}
+	cost := func(i, j int) int64 {
+		if i <= rows && j <= cols {
+			return -weights[i-1][j-1]
+		}
+		return 0
+	}
+	const inf = math.MaxInt64 / 4
+	u := make([]int64, n+1)
+	v := make([]int64, n+1)
+	p := make([]int, n+1)
+	way := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]int64, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = inf
+		}
+		for {
+			used[j0] = true
+			i0, delta, j1 := p[j0], int64(inf), 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := cost(i0, j) - u[i0] - v[j]; cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	assignment := make([]int, rows)
+	for i := range assignment {
+		assignment[i] = -1
+	}
+	for j := 1; j <= cols; j++ {
+		if i := p[j]; i >= 1 && i <= rows && weights[i-1][j-1] > 0 {
+			assignment[i-1] = j - 1
+		}
+	}
+	return assignment
+}
+
+// applyCoupon 校验优惠券的有效期、使用门槛和适用范围并计算抵扣金额，不可用时返回原因
+// template 为空表示兑换券，兑换券按面额抵扣
+func applyCoupon(coupon *model.UserCoupon, template *model.ProductCoupon, item *model.OrderItemQuote, now time.Time) (*model.CouponApplication, string) {
+	if coupon.StartAt != nil && coupon.StartAt.After(now) {
+		return nil, "优惠券未到使用时间"
+	}
+	if !coupon.Deadline.IsZero() && coupon.Deadline.Before(now) {
+		return nil, "优惠券已过期"
+	}
+
+	application := &model.CouponApplication{
+		UserCouponID: coupon.ID,
+		CouponName:   coupon.CouponName,
+		StackMember:  true,
+	}
+	// 兑换券
+	if template == nil {
+		if coupon.ProductID > 0 && coupon.ProductID != item.ProductID {
+			return nil, "该商品不在优惠券适用范围内"
+		}
+		if item.GoodsFee < coupon.AvailableMinPrice {
+			return nil, "未达到优惠券使用门槛"
+		}
+		application.Discount = coupon.CouponPrice
+		return application, ""
+	}
+
+	if !couponInScope(template, item) {
+		return nil, "该商品不在优惠券适用范围内"
+	}
+	if item.GoodsFee < template.AvailableMinPrice {
+		return nil, "未达到优惠券使用门槛"
+	}
+	switch template.Kind {
+	case common.COUPON_KIND_FIXED, common.COUPON_KIND_THRESHOLD:
+		application.Discount = template.CouponPrice
+	case common.COUPON_KIND_PERCENT:
+		if template.DiscountRate <= 0 || template.DiscountRate >= 1 {
+			return nil, "优惠券配置有误"
+		}
+		application.Discount = item.GoodsFee.MulRate(1 - template.DiscountRate)
+	case common.COUPON_KIND_FREE_SHIPPING:
+		application.Discount = item.CourierFee
+		application.FreeShipping = true
+	default:
+		return nil, "优惠券配置有误"
+	}
+	if template.MaxDiscount > 0 {
+		application.Discount = money.Min(application.Discount, template.MaxDiscount)
+	}
+	application.StackMember = template.StackMember == 1
+	application.Exclusive = template.Exclusive == 1
+	return application, ""
+}
+
+// couponInScope 判断订单项商品是否在优惠券模板的适用范围内
+func couponInScope(coupon *model.ProductCoupon, item *model.OrderItemQuote) bool {
+	switch coupon.ScopeType {
+	case common.COUPON_SCOPE_ALL:
+		return true
+	case common.COUPON_SCOPE_CATEGORY:
+		return containsID(coupon.ScopeIDs, uint64(item.Category1ID)) || containsID(coupon.ScopeIDs, uint64(item.Category2ID))
+	case common.COUPON_SCOPE_PRODUCT:
+		return containsID(coupon.ScopeIDs, item.ProductID)
+	}
+	return false
+}
+
+// containsID 判断逗号分隔的ID列表中是否包含指定ID
+func containsID(ids string, id uint64) bool {
+	if id == 0 {
+		return false
+	}
+	for _, s := range strings.Split(ids, ",") {
+		if v, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64); err == nil && v == id {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaxWeightAssignment(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights [][]int64
+		cols    int
+		want    []int
+	}{
+		{
+			name:    "优惠券多于商品",
+			weights: [][]int64{{5, 9, 1}, {4, 8, 7}},
+			cols:    3,
+			want:    []int{1, 2},
+		},
+		{
+			name:    "商品多于优惠券",
+			weights: [][]int64{{3, 5}, {6, 4}, {2, 9}},
+			cols:    2,
+			want:    []int{-1, 0, 1},
+		},
+		{
+			name:    "贪心选择不是最优",
+			weights: [][]int64{{10, 9}, {9, 0}},
+			cols:    2,
+			want:    []int{1, 0},
+		},
+		{
+			name:    "优惠金额全部为0",
+			weights: [][]int64{{0, 0}, {0, 0}},
+			cols:    2,
+			want:    []int{-1, -1},
+		},
+		{
+			name:    "没有优惠券",
+			weights: [][]int64{{}, {}},
+			cols:    0,
+			want:    []int{-1, -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maxWeightAssignment(tt.weights, tt.cols); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("maxWeightAssignment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChooseCoupons(t *testing.T) {
+	tests := []struct {
+		name           string
+		gains          [][]int64
+		exclusive      []bool
+		wantAssignment []int
+		wantTotal      int64
+		wantExclusive  bool
+	}{
+		{
+			name:           "优惠券多于商品",
+			gains:          [][]int64{{5, 9, 1}, {4, 8, 7}},
+			exclusive:      []bool{false, false, false},
+			wantAssignment: []int{1, 2},
+			wantTotal:      16,
+		},
+		{
+			name:           "商品多于优惠券",
+			gains:          [][]int64{{3, 5}, {6, 4}, {2, 9}},
+			exclusive:      []bool{false, false},
+			wantAssignment: []int{-1, 0, 1},
+			wantTotal:      15,
+		},
+		{
+			name:           "独占券优于组合",
+			gains:          [][]int64{{4, 20}, {5, 0}},
+			exclusive:      []bool{false, true},
+			wantAssignment: []int{1, -1},
+			wantTotal:      20,
+			wantExclusive:  true,
+		},
+		{
+			name:           "组合优于独占券",
+			gains:          [][]int64{{8, 0, 10}, {0, 7, 0}},
+			exclusive:      []bool{false, false, true},
+			wantAssignment: []int{0, 1},
+			wantTotal:      15,
+		},
+		{
+			name:           "优惠金额全部为0",
+			gains:          [][]int64{{0, 0}, {0, 0}},
+			exclusive:      []bool{false, true},
+			wantAssignment: []int{-1, -1},
+			wantTotal:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment, total, exclusiveChosen := chooseCoupons(tt.gains, tt.exclusive)
+			if !reflect.DeepEqual(assignment, tt.wantAssignment) {
+				t.Errorf("assignment = %v, want %v", assignment, tt.wantAssignment)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", total, tt.wantTotal)
+			}
+			if exclusiveChosen != tt.wantExclusive {
+				t.Errorf("exclusiveChosen = %v, want %v", exclusiveChosen, tt.wantExclusive)
+			}
+		})
+	}
+}
@@ -2,21 +2,26 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
 	"time"
 
+	"app/internal/common"
 	"app/internal/model"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
 )
 
 type UserEarningRepository interface {
-	AddEarning(ctx context.Context, userID string, req model.AddEarningRequest) error
+	GenerateDailyEarnings(ctx context.Context, date time.Time) (int, error)
 	GetEarningList(ctx context.Context, userID string, req model.QueryEarningRequest) (*model.EarningListResponse, error)
 }
 
 type userEarningRepository struct {
 	*Repository
+	ledgerRepository LedgerRepository
 }
 
 var _earningTypeMap = map[model.EarningType]string{
@@ -31,39 +36,239 @@ var _earningTypeImageMap = map[model.EarningType]string{
 	model.EarningTypeBreeding: "/images/icons/tuoniao.png",
 }
 
-func NewUserEarningRepository(repository *Repository) UserEarningRepository {
+func NewUserEarningRepository(repository *Repository, ledgerRepository LedgerRepository) UserEarningRepository {
 	return &userEarningRepository{
-		Repository: repository,
+		Repository:       repository,
+		ledgerRepository: ledgerRepository,
 	}
 }
 
-// AddEarning 添加用户收益
-func (r *userEarningRepository) AddEarning(ctx context.Context, userID string, req model.AddEarningRequest) error {
-	// 处理日期，如果未提供则使用当前日期
-	now := time.Now()
-	earningDate := now.Format("2006-01-02")
+// GenerateDailyEarnings 按农场产蛋记录为认养用户生成指定日期的收益，产出的鸟蛋记入用户资产，可在自由市场挂单出售
+// 产蛋记录先按当时在养的鸵鸟分配并保存分配结果，之后栏舍或鸵鸟状态变动不会重新分配；再按鸵鸟汇总待入账的分配生成收益
+func (r *userEarningRepository) GenerateDailyEarnings(ctx context.Context, date time.Time) (int, error) {
+	if err := r.allocateProductions(ctx, date); err != nil {
+		return 0, err
+	}
+	return r.creditAllocations(ctx, date)
+}
 
-	// 创建收益记录
-	earning := model.UserEarning{
-		UserID:      userID,
-		EarningType: req.EarningType,
-		Amount:      req.Amount,
-		EarningDate: earningDate,
-		Year:        now.Year(),
-		Month:       int(now.Month()),
-		TypeName:    _earningTypeMap[req.EarningType],
-		Image:       _earningTypeImageMap[req.EarningType],
-	}
-
-	// 保存到数据库
-	if err := r.DB(ctx).Create(&earning).Error; err != nil {
-		r.logger.Error("添加用户收益失败", zap.Error(err))
+// allocateProductions 将未分配的产蛋记录分配到鸵鸟，按栏舍记录的由栏舍内在养的鸵鸟平均分配
+// 未匹配到鸵鸟的记录保持未分配，栏舍补录后回看期内重新执行时再分配
+func (r *userEarningRepository) allocateProductions(ctx context.Context, date time.Time) error {
+	var productions []model.FarmProduction
+	if err := r.DB(ctx).Where("production_date = ? AND egg_count > 0 AND allocated = 0", date.Format("2006-01-02")).
+		Order("id ASC").Find(&productions).Error; err != nil {
+		r.logger.Error("查询农场产蛋记录失败", zap.Error(err))
 		return err
 	}
 
+	// 只有当天结束前已登记且仍在养的鸵鸟产生收益
+	registeredBefore := startOfDay(date).AddDate(0, 0, 1)
+	raising := []uint8{common.OSTRICH_STATUS_CHICK, common.OSTRICH_STATUS_JUVENILE, common.OSTRICH_STATUS_ADULT}
+	for _, production := range productions {
+		query := r.DB(ctx).Where("status IN ? AND created_at < ?", raising, registeredBefore)
+		switch {
+		case production.OstrichID > 0:
+			query = query.Where("id = ?", production.OstrichID)
+		case production.Pen != "":
+			query = query.Where("farm_id = ? AND pen = ?", production.FarmID, production.Pen)
+		default:
+			r.logger.Warn("产蛋记录未指定鸵鸟或栏舍", zap.Uint64("production_id", production.ID))
+			continue
+		}
+		var flock []model.Ostrich
+		if err := query.Order("id ASC").Find(&flock).Error; err != nil {
+			r.logger.Error("查询产蛋鸵鸟失败", zap.Error(err))
+			return err
+		}
+		if len(flock) == 0 {
+			// 多为栏舍或鸵鸟未在后台补录，补录后回看期内重新执行会补发收益
+			r.logger.Warn("产蛋记录未匹配到在养鸵鸟",
+				zap.Uint64("production_id", production.ID),
+				zap.Uint64("farm_id", production.FarmID),
+				zap.String("pen", production.Pen),
+				zap.Uint64("ostrich_id", production.OstrichID),
+				zap.String("production_date", production.ProductionDate),
+				zap.Int64("egg_count", production.EggCount),
+			)
+			continue
+		}
+
+		// 平均分配，除不尽的部分依次分给登记较早的鸵鸟
+		now := time.Now()
+		share, rest := production.EggCount/int64(len(flock)), production.EggCount%int64(len(flock))
+		allocations := make([]model.FarmProductionAllocation, 0, len(flock))
+		for i, ostrich := range flock {
+			amount := share
+			if int64(i) < rest {
+				amount++
+			}
+			if amount == 0 {
+				continue
+			}
+			allocations = append(allocations, model.FarmProductionAllocation{
+				ProductionID:   production.ID,
+				OstrichID:      ostrich.ID,
+				ProductionDate: production.ProductionDate,
+				Amount:         amount,
+				Status:         common.PRODUCTION_ALLOCATION_PENDING,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			})
+		}
+		if err := r.Transaction(ctx, func(ctx context.Context) error {
+			result := r.DB(ctx).Model(&model.FarmProduction{}).
+				Where("id = ? AND allocated = 0", production.ID).
+				Updates(map[string]interface{}{
+					"allocated":  1,
+					"updated_at": now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+			return r.DB(ctx).Create(&allocations).Error
+		}); err != nil {
+			r.logger.Error("保存产蛋分配失败", zap.Uint64("production_id", production.ID), zap.Error(err))
+			return err
+		}
+	}
 	return nil
 }
 
+// creditAllocations 按鸵鸟汇总指定日期待入账的分配生成收益，返回生成的收益数
+func (r *userEarningRepository) creditAllocations(ctx context.Context, date time.Time) (int, error) {
+	var allocations []model.FarmProductionAllocation
+	if err := r.DB(ctx).Where("production_date = ? AND status = ?", date.Format("2006-01-02"), common.PRODUCTION_ALLOCATION_PENDING).
+		Order("id ASC").Find(&allocations).Error; err != nil {
+		r.logger.Error("查询待入账的产蛋分配失败", zap.Error(err))
+		return 0, err
+	}
+	if len(allocations) == 0 {
+		return 0, nil
+	}
+
+	grouped := make(map[uint64][]model.FarmProductionAllocation)
+	ids := make([]uint64, 0)
+	for _, allocation := range allocations {
+		if _, ok := grouped[allocation.OstrichID]; !ok {
+			ids = append(ids, allocation.OstrichID)
+		}
+		grouped[allocation.OstrichID] = append(grouped[allocation.OstrichID], allocation)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var ostriches []model.Ostrich
+	if err := r.DB(ctx).Where("id IN ?", ids).Find(&ostriches).Error; err != nil {
+		r.logger.Error("查询产蛋鸵鸟失败", zap.Error(err))
+		return 0, err
+	}
+	ostrichMap := make(map[uint64]model.Ostrich, len(ostriches))
+	for _, ostrich := range ostriches {
+		ostrichMap[ostrich.ID] = ostrich
+	}
+
+	generated := 0
+	for _, id := range ids {
+		ostrich, ok := ostrichMap[id]
+		if !ok {
+			r.logger.Error("产蛋分配的鸵鸟不存在", zap.Uint64("ostrich_id", id))
+			continue
+		}
+		created, err := r.createEarning(ctx, ostrich, date, grouped[id])
+		if err != nil {
+			r.logger.Error("生成鸵鸟收益失败", zap.Uint64("ostrich_id", id), zap.Error(err))
+			continue
+		}
+		if created {
+			generated++
+		}
+	}
+	return generated, nil
+}
+
+// createEarning 按鸵鸟当天的产蛋分配记录收益并将鸟蛋记入用户资产，同时标记分配已入账
+// 鸵鸟当天已有收益时不再入账，记录日志并将后补的分配标记为已跳过，返回 false
+func (r *userEarningRepository) createEarning(ctx context.Context, ostrich model.Ostrich, date time.Time, allocations []model.FarmProductionAllocation) (bool, error) {
+	var amount int64
+	allocationIds := make([]uint64, 0, len(allocations))
+	productionIds := make([]uint64, 0, len(allocations))
+	for _, allocation := range allocations {
+		amount += allocation.Amount
+		allocationIds = append(allocationIds, allocation.ID)
+		productionIds = append(productionIds, allocation.ProductionID)
+	}
+	earningType := model.EarningTypeBird
+	if ostrich.Type == common.OSTRICH_TYPE_BREEDING {
+		earningType = model.EarningTypeBreeding
+	}
+	ostrichID := ostrich.ID
+	earning := model.UserEarning{
+		UserID:      ostrich.UserID,
+		OstrichID:   &ostrichID,
+		EarningType: earningType,
+		TypeName:    _earningTypeMap[earningType],
+		Image:       _earningTypeImageMap[earningType],
+		Amount:      amount,
+		EarningDate: date.Format("2006-01-02"),
+		Year:        date.Year(),
+		Month:       int(date.Month()),
+	}
+
+	created := false
+	err := r.Transaction(ctx, func(ctx context.Context) error {
+		tx := r.DB(ctx)
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&earning)
+		if result.Error != nil {
+			r.logger.Error("添加用户收益失败", zap.Error(result.Error))
+			return result.Error
+		}
+		updates := map[string]interface{}{
+			"status":     common.PRODUCTION_ALLOCATION_CREDITED,
+			"earning_id": earning.ID,
+			"updated_at": time.Now(),
+		}
+		// 鸵鸟当天的收益已生成，后补的产蛋记录不重复入账
+		if result.RowsAffected == 0 {
+			r.logger.Warn("鸵鸟当天已有收益，跳过后补的产蛋记录",
+				zap.Uint64("ostrich_id", ostrich.ID),
+				zap.String("earning_date", earning.EarningDate),
+				zap.Uint64s("production_ids", productionIds),
+				zap.Int64("amount", amount),
+			)
+			updates = map[string]interface{}{
+				"status":     common.PRODUCTION_ALLOCATION_SKIPPED,
+				"updated_at": time.Now(),
+			}
+		}
+		marked := tx.Model(&model.FarmProductionAllocation{}).
+			Where("id IN ? AND status = ?", allocationIds, common.PRODUCTION_ALLOCATION_PENDING).
+			Updates(updates)
+		if marked.Error != nil {
+			r.logger.Error("标记产蛋分配失败", zap.Error(marked.Error))
+			return marked.Error
+		}
+		if marked.RowsAffected != int64(len(allocationIds)) {
+			return errors.New("产蛋分配状态已变更")
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		created = true
+		return r.ledgerRepository.Post(ctx, tx, model.LedgerEntry{
+			UserID:        earning.UserID,
+			BusinessType:  common.BUSINESS_TYPE_EARNING,
+			ActionType:    common.ACTION_TYPE_REWARD,
+			AssetType:     common.ASSET_TYPE_EGG,
+			Amount:        amount,
+			RelationID:    int(earning.ID),
+			RelationTitle: fmt.Sprintf("%s %s", earning.TypeName, ostrich.TagNo),
+		})
+	})
+	return created, err
+}
+
 // GetEarningList 获取用户收益列表
 func (r *userEarningRepository) GetEarningList(ctx context.Context, userID string, req model.QueryEarningRequest) (*model.EarningListResponse, error) {
 	// 设置默认分页参数
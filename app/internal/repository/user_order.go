@@ -2,15 +2,17 @@ package repository
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	v1 "app/api/v1"
 	"app/internal/cache"
 	"app/internal/common"
 	"app/internal/model"
+	"app/pkg/money"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -21,30 +23,64 @@ type UserOrderRepository interface {
 	CreateOrders(ctx *gin.Context, userID string, req model.CreateOrderRequest) error
 	GetProductList(ctx context.Context, prosductIds []uint64) ([]model.ProductListItemDTO, error)
 	GetOrderList(ctx context.Context, userID string, req model.OrderQueryRequest) (*model.OrderListResponse, error)
-	GetUserOrderTotalAmount(ctx context.Context, userID string) (float64, float64, error)
 	GetOrderProductDetails(ctx context.Context, orderID uint64, orderItemID uint64) ([]model.ProductListItemDTO, error)
 	VerifyOrderOwnership(ctx context.Context, userID string, orderID uint64) (bool, error)
 	UpdateOrderStatus(ctx context.Context, userID string, orderItemID uint64, status uint8) error
 	GetOrderDetail(ctx context.Context, userID string, orderItemID uint64) (*model.OrderDetailResponse, error)
+	GetPayTimeout(ctx context.Context) time.Duration
+	GetExpiredOrderItems(ctx context.Context, deadline time.Time, limit int) ([]model.UserOrderItem, error)
+	ExpireOrderItem(ctx context.Context, orderItem *model.UserOrderItem) error
+	GetPayableOrder(ctx context.Context, userID string, orderID uint64) (*model.UserOrder, error)
+	PayOrder(ctx context.Context, orderID uint64, paymentMethod int, remark string) error
 }
 
 func NewUserOrderRepository(
 	repository *Repository,
 	userCartRepository UserCartRepository,
 	userAssetRepository UserAssetRepository,
+	orderPricingRepository OrderPricingRepository,
+	orderStatusRepository OrderStatusRepository,
+	settingsRepository SettingsRepository,
+	productRepository ProductRepository,
+	memberTierRepository MemberTierRepository,
+	storeRepository StoreRepository,
+	ledgerRepository LedgerRepository,
+	pointRepository PointRepository,
+	userCouponRepository UserCouponRepository,
+	ostrichRepository OstrichRepository,
 ) UserOrderRepository {
 	return &userOrderRepository{
-		Repository:          repository,
-		userCartRepository:  userCartRepository,
-		userAssetRepository: userAssetRepository,
+		Repository:             repository,
+		userCartRepository:     userCartRepository,
+		userAssetRepository:    userAssetRepository,
+		orderPricingRepository: orderPricingRepository,
+		orderStatusRepository:  orderStatusRepository,
+		settingsRepository:     settingsRepository,
+		productRepository:      productRepository,
+		memberTierRepository:   memberTierRepository,
+		storeRepository:        storeRepository,
+		ledgerRepository:       ledgerRepository,
+		pointRepository:        pointRepository,
+		userCouponRepository:   userCouponRepository,
+		ostrichRepository:      ostrichRepository,
 	}
 }
 
 type userOrderRepository struct {
 	*Repository
 	*cache.Cache
-	userCartRepository  UserCartRepository
-	userAssetRepository UserAssetRepository
+	userCartRepository     UserCartRepository
+	userAssetRepository    UserAssetRepository
+	orderPricingRepository OrderPricingRepository
+	orderStatusRepository  OrderStatusRepository
+	settingsRepository     SettingsRepository
+	productRepository      ProductRepository
+	memberTierRepository   MemberTierRepository
+	storeRepository        StoreRepository
+	ledgerRepository       LedgerRepository
+	pointRepository        PointRepository
+	userCouponRepository   UserCouponRepository
+	ostrichRepository      OstrichRepository
 }
 
 func (r *userOrderRepository) GetCache(ctx context.Context, key string) *cache.Cache {
@@ -67,32 +103,37 @@ func (r *userOrderRepository) GetOrderItem(ctx context.Context, orderItemID uint
 }
 
 func (r *userOrderRepository) CreateOrders(ctx *gin.Context, userID string, req model.CreateOrderRequest) error {
+	if req.PaymentMethod != common.PAYMENT_METHOD_WECHAT && req.PaymentMethod != common.PAYMENT_METHOD_BALANCE {
+		return errors.New("不支持的支付方式")
+	}
 	isDefault := 0
 	if req.Address.IsDefault {
 		isDefault = 1
 	}
-	// 计算总金额
-	totalFee := float64(0)
+	// 服务端重新计算价格，不信任客户端提交的金额
+	region := model.FreightRegion{Province: req.Address.Province, City: req.Address.City, District: req.Address.District}
+	quote, err := r.orderPricingRepository.QuoteOrder(ctx, userID, req.Items, region)
+	if err != nil {
+		r.logger.Debug("计算订单价格失败", "error", err)
+		return err
+	}
+	// 客户端报价与服务端报价不一致，拒绝下单
+	if err := r.orderPricingRepository.VerifyClientQuote(quote, req.Items); err != nil {
+		return err
+	}
+	totalFee := quote.TotalFee
 	// 产品列表
-	productIds := make([]uint64, 0, len(req.Items))
-	for _, item := range req.Items {
-		totalFee += item.CurrentPrice * float64(item.Quantity)
-		if item.CouponID > 0 {
-			couponPrice := item.CouponPrice
-			totalFee -= couponPrice
-		}
-		if item.MemberDiscount > 0 {
-			totalFee -= item.MemberDiscount
-		}
-		// 运费
-		courierFeeMin := item.CourierFeeMin
-		if courierFeeMin > 0 {
-			totalFee += courierFeeMin
-		}
+	productIds := make([]uint64, 0, len(quote.Items))
+	for _, item := range quote.Items {
 		productIds = append(productIds, item.ProductID)
 	}
+	// 订单初始状态由服务端决定，余额支付在下单时完成扣款，直接进入待发货
+	status := uint8(common.ORDER_STATUS_PENDING)
+	if req.PaymentMethod == common.PAYMENT_METHOD_BALANCE {
+		status = common.ORDER_STATUS_SHIPPED
+	}
 	// 如果使用的余额账户，验证余额是否充足
-	if req.PaymentMethod == 3 {
+	if req.PaymentMethod == common.PAYMENT_METHOD_BALANCE {
 		balance, err := r.userAssetRepository.GetUserAsset(ctx, userID)
 		if err != nil {
 			return err
@@ -101,89 +142,124 @@ func (r *userOrderRepository) CreateOrders(ctx *gin.Context, userID string, req
 			return errors.New("余额不足")
 		}
 	}
-	// 获取产品列表
-	productList, err := r.GetProductList(ctx, productIds)
+	// 按店铺拆分订单，每个店铺生成一个订单
+	storeIds := make([]uint64, 0, len(quote.Items))
+	storeItems := make(map[uint64][]model.OrderItemQuote)
+	for _, item := range quote.Items {
+		if _, ok := storeItems[item.StoreID]; !ok {
+			storeIds = append(storeIds, item.StoreID)
+		}
+		storeItems[item.StoreID] = append(storeItems[item.StoreID], item)
+	}
+	storeMap, err := r.storeRepository.GetStoresByIDs(ctx, storeIds)
 	if err != nil {
-		r.logger.Debug("获取产品列表失败", "error", err)
 		return err
 	}
-	productInfoMap := make(map[uint64]model.ProductListItemDTO, len(productList))
-	for _, item := range productList {
-		productInfoMap[uint64(item.ProductID)] = item
+	for _, storeID := range storeIds {
+		store, ok := storeMap[storeID]
+		if !ok {
+			return errors.New("店铺不存在")
+		}
+		if store.Status != common.STORE_STATUS_OPEN {
+			return errors.New("店铺已关闭")
+		}
 	}
+
 	// 添加到user_order表
 	tx := r.DB(ctx).Begin()
 	var payTime *time.Time
-	if req.Status == 1 {
+	if status == common.ORDER_STATUS_SHIPPED {
 		now := time.Now()
 		payTime = &now
 	}
-	order := model.UserOrder{
-		UserID:        userID,
-		OrderNo:       common.GenerateOrderNo(),
-		PaymentMethod: req.PaymentMethod,
-		AddressID:     req.Address.ID,
-		Name:          req.Address.Name,
-		Phone:         req.Address.Phone,
-		Province:      req.Address.Province,
-		City:          req.Address.City,
-		District:      req.Address.District,
-		Detail:        req.Address.Detail,
-		IsDefault:     uint8(isDefault),
-		TotalFee:      totalFee,
-	}
-	if err := tx.Create(&order).Error; err != nil {
-		r.logger.Debug("创建订单失败", "error", err, "order", order)
-		tx.Rollback()
-		return err
-	}
 
 	// Create a slice to hold all the orders
-	orders := make([]model.UserOrderItem, 0, len(req.Items))
+	orders := make([]model.UserOrderItem, 0, len(quote.Items))
 	// Convert each item to an order
-	couponIds := make([]uint64, 0, len(req.Items))
-	for _, item := range req.Items {
-		itemTotalFee := item.CurrentPrice * float64(item.Quantity)
-		couponPrice := item.CouponPrice
-		memberDiscount := item.MemberDiscount
-		courierFeeMin := item.CourierFeeMin
-		totalFee := itemTotalFee + courierFeeMin - couponPrice - memberDiscount
-		order := model.UserOrderItem{
-			UserId:         userID,
-			Category1Id:    productInfoMap[uint64(item.ProductID)].Category1ID,
-			Category2Id:    productInfoMap[uint64(item.ProductID)].Category2ID,
-			OrderID:        order.ID,
-			OrderNo:        order.OrderNo,
-			ProductID:      item.ProductID,
-			Quantity:       item.Quantity,
-			ProductName:    item.ProductName,
-			HeaderImg:      item.Image,
-			StoreID:        common.STORE_ID,
-			StoreName:      common.STORE_NAME,
-			StoreLogo:      common.STORE_LOGO,
-			CurrentPrice:   item.CurrentPrice,
-			CourierFeeMin:  item.CourierFeeMin,
-			MemberDiscount: item.MemberDiscount,
-			Note:           item.Note,
-			CouponID:       item.CouponID,
-			CouponPrice:    item.CouponPrice,
-			TotalFee:       totalFee,
-			Status:         uint8(req.Status),
-			PayTime:        payTime,
-		}
-		orders = append(orders, order)
-		if item.CouponID > 0 {
-			couponIds = append(couponIds, item.CouponID)
-		}
-		if req.PaymentMethod == 3 {
-			// 更新用户资产
-			r.logger.Debug("更新用户资产", "totalFee", totalFee, "orderID", order.ID, "productName", item.ProductName)
-			if err := r.userAssetRepository.UpdateUserAsset(ctx, tx, userID, common.BUSINESS_TYPE_ORDER,
-				common.ACTION_TYPE_BUY, common.ASSET_TYPE_BALANCE, -float32(totalFee), int(order.ID), item.ProductName); err != nil {
-				r.logger.Debug("更新用户资产失败", "error", err)
+	// 订单项下标对应使用的用户优惠券记录ID
+	orderCoupons := make(map[int]uint64)
+	for _, storeID := range storeIds {
+		store := storeMap[storeID]
+		var storeTotalFee money.Money
+		for _, item := range storeItems[storeID] {
+			storeTotalFee += item.TotalFee
+		}
+		order := model.UserOrder{
+			UserID:        userID,
+			OrderNo:       common.GenerateOrderNo(),
+			PaymentMethod: req.PaymentMethod,
+			AddressID:     req.Address.ID,
+			Name:          req.Address.Name,
+			Phone:         req.Address.Phone,
+			Province:      req.Address.Province,
+			City:          req.Address.City,
+			District:      req.Address.District,
+			Detail:        req.Address.Detail,
+			IsDefault:     uint8(isDefault),
+			TotalFee:      storeTotalFee,
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			r.logger.Debug("创建订单失败", "error", err, "order", order)
+			tx.Rollback()
+			return err
+		}
+
+		for _, item := range storeItems[storeID] {
+			orderItem := model.UserOrderItem{
+				UserId:         userID,
+				Category1Id:    item.Category1ID,
+				Category2Id:    item.Category2ID,
+				OrderID:        order.ID,
+				OrderNo:        order.OrderNo,
+				ProductID:      item.ProductID,
+				Quantity:       item.Quantity,
+				ProductName:    item.ProductName,
+				HeaderImg:      item.HeaderImg,
+				StoreID:        store.ID,
+				StoreName:      store.Name,
+				StoreLogo:      store.Logo,
+				CurrentPrice:   item.UnitPrice,
+				CourierFeeMin:  item.CourierFee,
+				MemberDiscount: item.MemberDiscount,
+				Note:           item.Note,
+				CouponID:       item.CouponID,
+				CouponPrice:    item.CouponPrice,
+				TotalFee:       item.TotalFee,
+				Status:         status,
+				PayTime:        payTime,
+			}
+			// 锁定库存，余额支付的订单直接扣减
+			if err := r.productRepository.ReserveStock(ctx, tx, item.ProductID, item.Quantity); err != nil {
 				tx.Rollback()
 				return err
 			}
+			if status == common.ORDER_STATUS_SHIPPED {
+				if err := r.productRepository.CommitStock(ctx, tx, item.ProductID, item.Quantity); err != nil {
+					tx.Rollback()
+					return err
+				}
+			}
+			if item.CouponID > 0 {
+				orderCoupons[len(orders)] = item.UserCouponID
+			}
+			orders = append(orders, orderItem)
+			if req.PaymentMethod == common.PAYMENT_METHOD_BALANCE {
+				// 更新用户资产
+				r.logger.Debug("更新用户资产", "totalFee", item.TotalFee, "orderID", order.ID, "productName", item.ProductName)
+				if err := r.ledgerRepository.Post(ctx, tx, model.LedgerEntry{
+					UserID:        userID,
+					BusinessType:  common.BUSINESS_TYPE_ORDER,
+					ActionType:    common.ACTION_TYPE_BUY,
+					AssetType:     common.ASSET_TYPE_BALANCE,
+					Amount:        -item.TotalFee.Fen(),
+					RelationID:    int(order.ID),
+					RelationTitle: item.ProductName,
+				}); err != nil {
+					r.logger.Debug("更新用户资产失败", "error", err)
+					tx.Rollback()
+					return err
+				}
+			}
 		}
 	}
 	// Create all orders in a single transaction
@@ -193,6 +269,36 @@ func (r *userOrderRepository) CreateOrders(ctx *gin.Context, userID string, req
 		return err
 	}
 
+	// 记录订单状态
+	statusLogs := make([]model.OrderStatusLog, 0, len(orders)*2)
+	for _, item := range orders {
+		statusLogs = append(statusLogs, model.OrderStatusLog{
+			OrderID:     item.OrderID,
+			OrderItemID: item.ID,
+			FromStatus:  common.ORDER_STATUS_PENDING,
+			ToStatus:    common.ORDER_STATUS_PENDING,
+			ActorType:   common.ORDER_ACTOR_USER,
+			ActorID:     userID,
+			Remark:      "提交订单",
+			CreatedAt:   item.CreatedAt,
+		})
+		if status == common.ORDER_STATUS_SHIPPED {
+			statusLogs = append(statusLogs, model.OrderStatusLog{
+				OrderID:     item.OrderID,
+				OrderItemID: item.ID,
+				FromStatus:  common.ORDER_STATUS_PENDING,
+				ToStatus:    common.ORDER_STATUS_SHIPPED,
+				ActorType:   common.ORDER_ACTOR_SYSTEM,
+				Remark:      "余额支付",
+				CreatedAt:   item.CreatedAt,
+			})
+		}
+	}
+	if err := r.orderStatusRepository.CreateLogs(ctx, tx, statusLogs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// 查询用户购买的商品是否在购物车中
 	if r.userCartRepository != nil {
 		cartList, _ := r.userCartRepository.GetCartList(ctx, userID, productIds)
@@ -209,59 +315,28 @@ func (r *userOrderRepository) CreateOrders(ctx *gin.Context, userID string, req
 		}
 	}
 
-	if req.Status == 1 {
-		// 更新优惠券状态
-		if len(couponIds) > 0 {
-			if err := tx.Model(&model.UserCoupon{}).Where("user_id = ? AND coupon_id IN (?) AND status = ?", userID, couponIds, 0).Update("status", 1).Error; err != nil {
-				r.logger.Debug("更新优惠券状态失败", "error", err)
-				tx.Rollback()
-				return err
-			}
+	// 下单即将优惠券锁定到订单项，订单取消或超时后释放
+	for i, userCouponID := range orderCoupons {
+		if err := r.userCouponRepository.LockCoupon(ctx, tx, userCouponID, &orders[i]); err != nil {
+			tx.Rollback()
+			return err
 		}
+	}
 
-		// 是否需要更新身份,普通会员:注册后未消费，高级会员：注册后消费记录，初级农场主：消费金额超过3万 高级农场主超过6万 资深农场主超过24万 合伙人后台指定
-		// 获取用户购买消费（category1_id != 3）的总金额，以及认养鸵鸟（category1_id = 3）的总金额
-		totalBuyAmount, totalAdoptAmount, err := r.GetUserOrderTotalAmount(ctx, userID)
-		if err != nil {
-			r.logger.Debug("获取用户消费总金额失败", "error", err)
+	// 余额支付的订单已完成支付，累计消费并重新计算会员身份
+	if status == common.ORDER_STATUS_SHIPPED {
+		if err := r.userAssetRepository.AddConsumption(ctx, tx, userID, totalFee); err != nil {
+			tx.Rollback()
 			return err
 		}
-		updateRole := 0
-		// 获取用户身份
-		var role int
-		if err := tx.Model(&model.Account{}).Where("user_id = ?", userID).Select("role").Scan(&role).Error; err != nil {
-			r.logger.Debug("获取用户身份失败", "error", err)
+		if err := r.memberTierRepository.EvaluateRole(ctx, tx, userID, common.ORDER_ACTOR_SYSTEM, "", "订单支付"); err != nil {
+			tx.Rollback()
 			return err
 		}
-		// 如果用户消费总金额小于3万，则更新身份为高级会员
-		if role == common.ROLE_NORMAL && totalBuyAmount < 30000 {
-			updateRole = common.ROLE_VIP
-		}
-		// 如果用户消费总金额超过3万，则更新身份为初级农场主
-		if role == common.ROLE_VIP && totalBuyAmount >= 30000 {
-			updateRole = common.ROLE_FARMER_BEGIN
-		}
-		// 如果用户消费总金额超过5万，则更新身份为高级农场主
-		if role == common.ROLE_FARMER_BEGIN && totalBuyAmount >= 60000 {
-			updateRole = common.ROLE_FARMER_VIP
-		}
-		// 如果用户消费总金额超过10万，则更新身份为资深农场主
-		if role == common.ROLE_FARMER_VIP && totalBuyAmount >= 240000 {
-			updateRole = common.ROLE_FARMER_EXPERT
-		}
-		// 如果用户消费总金额超过20万，则更新身份为合伙人
-		if role == common.ROLE_FARMER_EXPERT && totalAdoptAmount >= 200000 {
-			updateRole = common.ROLE_FARMER_PARTNER
-		}
-		// 更新身份
-		if updateRole > 0 {
-			if err := tx.Model(&model.Account{}).Where("user_id = ?", userID).Update("role", updateRole).Error; err != nil {
-				r.logger.Debug("更新身份失败", "error", err)
-				tx.Rollback()
-				return err
-			}
-			// 删除用户缓存
-			r.GetCache(ctx, "account:").Del(userID)
+		// 认养订单支付后登记认养的鸵鸟
+		if err := r.ostrichRepository.Register(ctx, tx, orders); err != nil {
+			tx.Rollback()
+			return err
 		}
 	}
 	if err := tx.Commit().Error; err != nil {
@@ -293,6 +368,7 @@ func (r *userOrderRepository) GetProductList(ctx context.Context, productIds []u
 			ProductUnit:          "",
 			ProductSpec:          item.Specification,
 			ProductSales:         item.Sales,
+			ProductStock:         item.Stock,
 			ProductSpecification: item.Specification,
 			ProductIsSpecial:     item.IsSpecial,
 		})
@@ -301,29 +377,6 @@ func (r *userOrderRepository) GetProductList(ctx context.Context, productIds []u
 	return productListDTO, nil
 }
 
-// 获取用户购买消费（category1_id != 3）的总金额，以及认养鸵鸟（category1_id = 3）的总金额
-func (r *userOrderRepository) GetUserOrderTotalAmount(ctx context.Context, userID string) (float64, float64, error) {
-	if userID == "" {
-		return 0, 0, nil
-	}
-	// 获取用户购买消费（category1_id != 3）的总金额
-	var totalBuyAmount float64
-	var totalBuyAmountNullable sql.NullFloat64
-	if err := r.DB(ctx).Model(&model.UserOrderItem{}).Where("user_id = ? AND category1_id != ?", userID, 3).Select("COALESCE(SUM(total_fee), 0) as total_fee_all").Scan(&totalBuyAmountNullable).Error; err != nil {
-		return 0, 0, err
-	}
-	totalBuyAmount = totalBuyAmountNullable.Float64
-
-	// 获取用户认养鸵鸟（category1_id = 3）的总金额
-	var totalAdoptAmount float64
-	var totalAdoptAmountNullable sql.NullFloat64
-	if err := r.DB(ctx).Model(&model.UserOrderItem{}).Where("user_id = ? AND category1_id = ?", userID, 3).Select("COALESCE(SUM(total_fee), 0) as total_fee_all").Scan(&totalAdoptAmountNullable).Error; err != nil {
-		return 0, 0, err
-	}
-	totalAdoptAmount = totalAdoptAmountNullable.Float64
-	return totalBuyAmount, totalAdoptAmount, nil
-}
-
 // 实现订单列表查询方法
 func (r *userOrderRepository) GetOrderList(ctx context.Context, userID string, req model.OrderQueryRequest) (*model.OrderListResponse, error) {
 	var orders []model.UserOrderItem
@@ -384,16 +437,18 @@ func (r *userOrderRepository) GetOrderList(ctx context.Context, userID string, r
 		orderInfoMap[order.ID] = order
 	}
 
-	// 获取店铺信息
-	// 获取第一个订单项的店铺信息
-	var storeName string = "鸵小妥"
-	var storeIcon string = "https://txtimages.oss-cn-beijing.aliyuncs.com/icons/dianpu.png" // 默认店铺图标
-	if len(orders) > 0 {
-		storeName = orders[0].StoreName
-		storeIcon = orders[0].StoreLogo
+	// 获取店铺信息，店铺不存在时使用下单时记录的店铺信息
+	storeIds := make([]uint64, 0, len(orders))
+	for _, item := range orders {
+		storeIds = append(storeIds, item.StoreID)
+	}
+	storeMap, err := r.storeRepository.GetStoresByIDs(ctx, storeIds)
+	if err != nil {
+		return nil, err
 	}
 
 	// 转换为 DTO
+	payTimeout := r.GetPayTimeout(ctx)
 	orderDTOs := make([]model.OrderListDTO, 0, len(orders))
 	for _, item := range orders {
 		// 构建地址字符串
@@ -409,10 +464,10 @@ func (r *userOrderRepository) GetOrderList(ctx context.Context, userID string, r
 				orderInfo.OrderAddress.District,
 				orderInfo.OrderAddress.Street)
 		}
-		// 是否超过25分钟
+		// 是否超过支付时间，定时任务未处理前也展示为已失效
 		now := time.Now()
-		if item.Status == 0 && item.CreatedAt.Add(25*time.Minute).Before(now) {
-			item.Status = 6
+		if item.Status == common.ORDER_STATUS_PENDING && item.CreatedAt.Add(payTimeout).Before(now) {
+			item.Status = common.ORDER_STATUS_EXPIRED
 		}
 		// 构建状态文本
 		statusText := r.GetOrderStatusText(int(item.Status))
@@ -424,6 +479,11 @@ func (r *userOrderRepository) GetOrderList(ctx context.Context, userID string, r
 			payTime = &timeStr
 		}
 
+		storeName, storeIcon := item.StoreName, item.StoreLogo
+		if store, ok := storeMap[item.StoreID]; ok {
+			storeName, storeIcon = store.Name, store.Logo
+		}
+
 		// 构建订单商品
 		product := model.OrderProductDTO{
 			ItemID:      item.ID,
@@ -520,7 +580,7 @@ func (r *userOrderRepository) GetOrderProductDetails(ctx context.Context, orderI
 			ItemID:               uint(item.ID),
 			ProductID:            uint(item.ProductID),
 			ProductName:          item.ProductName,
-			ProductCurrentPrice:  item.CurrentPrice,
+			ProductCurrentPrice:  item.CurrentPrice.Yuan(),
 			ProductOriginalPrice: product.OriginPrice,
 			ProductUnit:          "", // You may need to add this field to the Product model
 			ProductSpec:          product.Specification,
@@ -535,7 +595,7 @@ func (r *userOrderRepository) GetOrderProductDetails(ctx context.Context, orderI
 			Category2ID:          item.Category2Id,
 			// 优惠券信息
 			CouponID:    int(item.CouponID),
-			CouponPrice: item.CouponPrice,
+			CouponPrice: item.CouponPrice.Yuan(),
 			// 订单特有信息
 			ProductQuantity: item.Quantity,
 		}
@@ -572,82 +632,127 @@ func (r *userOrderRepository) UpdateOrderStatus(ctx context.Context, userID stri
 		return err
 	}
 
-	// 验证状态转换是否合法
-	if !isValidStatusTransition(orderItem.Status, status) {
-		return errors.New("非法的状态转换")
-	}
-
-	// 更新订单状态
-	updateFields := map[string]interface{}{
-		"status":     status,
-		"updated_at": time.Now(),
-	}
-
-	// 如果已发货，则更新发货时间
-	if status == 2 && orderItem.Status == 1 {
-		updateFields["shipped_at"] = time.Now()
-	}
-
-	// 如果已收货，则更新收货时间
-	if status == 3 && orderItem.Status == 2 {
-		updateFields["completed_at"] = time.Now()
-		// 给用户添加积分，要求消费金额>10元，积分=（消费金额/10）取整
-		if orderItem.TotalFee > 10 {
-			integral := int(orderItem.TotalFee / 10)
-			if err := r.DB(ctx).Model(&model.UserAsset{}).Where("user_id = ?", userID).Update("points", gorm.Expr("points + ?", integral)).Error; err != nil {
-				r.logger.Debug("更新积分失败", "error", err)
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		fromStatus := orderItem.Status
+		// 按状态机变更订单状态，用户只能执行用户侧的操作
+		if err := r.orderStatusRepository.Transit(ctx, nil, &orderItem, status, common.ORDER_ACTOR_USER, userID, ""); err != nil {
+			return err
+		}
+		// 取消待付款订单，释放占用的优惠券
+		if fromStatus == common.ORDER_STATUS_PENDING && status == common.ORDER_STATUS_CLOSED {
+			if err := r.releaseOrderItem(ctx, &orderItem); err != nil {
 				return err
 			}
 		}
-	}
+		// 确认收货时按积分规则给用户添加订单积分
+		if fromStatus == common.ORDER_STATUS_RECEIVED && status == common.ORDER_STATUS_EVALUATE {
+			if err := r.pointRepository.AwardOrderPoints(ctx, r.DB(ctx), &orderItem); err != nil {
+				r.logger.Error("发放订单积分失败", zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-	// 如果状态变更为已支付，则更新支付时间
-	if status == 1 && orderItem.Status == 0 {
-		updateFields["pay_time"] = time.Now()
+// GetPayTimeout 获取订单支付超时时间，未配置时使用默认值
+func (r *userOrderRepository) GetPayTimeout(ctx context.Context) time.Duration {
+	minutes := common.ORDER_PAY_TIMEOUT_DEFAULT
+	value, err := r.settingsRepository.GetSettings(ctx, common.SETTINGS_ORDER_PAY_TIMEOUT)
+	if err == nil && value != "" {
+		if v, err := strconv.Atoi(value); err == nil && v > 0 {
+			minutes = v
+		}
 	}
+	return time.Duration(minutes) * time.Minute
+}
 
-	if err := r.DB(ctx).Model(&model.UserOrderItem{}).Where("id = ?", orderItemID).Updates(updateFields).Error; err != nil {
-		r.logger.Error("更新订单状态失败", zap.Error(err))
-		return err
+// GetExpiredOrderItems 获取创建时间早于 deadline 的待付款订单项
+func (r *userOrderRepository) GetExpiredOrderItems(ctx context.Context, deadline time.Time, limit int) ([]model.UserOrderItem, error) {
+	var orderItems []model.UserOrderItem
+	if err := r.DB(ctx).Where("status = ? AND created_at < ?", common.ORDER_STATUS_PENDING, deadline).
+		Order("id ASC").
+		Limit(limit).
+		Find(&orderItems).Error; err != nil {
+		r.logger.Error("查询超时订单失败", zap.Error(err))
+		return nil, err
 	}
-
-	return nil
+	return orderItems, nil
 }
 
-// isValidStatusTransition 验证状态转换是否合法
-func isValidStatusTransition(currentStatus, newStatus uint8) bool {
-	// 状态流转规则：
-	// 0(待支付) -> 1(待发货)
-	// 1(待发货) -> 2(待收货)
-	// 2(待收货) -> 3(待评价)
-	// 3(待评价) -> 4(已完成)
-	// 任何状态 -> 5(已取消)，假设5表示已取消
-	// 待发货 -> 6(已退款)
-
-	if newStatus == 5 { // 可以从任何状态取消
-		return true
-	}
+// ExpireOrderItem 将超时未支付的订单项置为已失效，并释放占用的资源
+func (r *userOrderRepository) ExpireOrderItem(ctx context.Context, orderItem *model.UserOrderItem) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.orderStatusRepository.Transit(ctx, nil, orderItem, common.ORDER_STATUS_EXPIRED, common.ORDER_ACTOR_SYSTEM, "", "超时未支付"); err != nil {
+			return err
+		}
+		return r.releaseOrderItem(ctx, orderItem)
+	})
+}
 
-	// 常规状态流转
-	validTransitions := map[uint8][]uint8{
-		0: {1},    // 待支付 -> 待发货
-		1: {2, 6}, // 待发货 -> 待收货, 已退款
-		2: {3},    // 待收货 -> 待评价
-		3: {4},    // 待评价 -> 已完成
+// GetPayableOrder 获取用户待付款的订单，订单项只包含待付款状态的记录
+func (r *userOrderRepository) GetPayableOrder(ctx context.Context, userID string, orderID uint64) (*model.UserOrder, error) {
+	var order model.UserOrder
+	if err := r.DB(ctx).Where("id = ? AND user_id = ?", orderID, userID).
+		Preload("OrderItems", "status = ?", common.ORDER_STATUS_PENDING).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("订单不存在或无权限")
+		}
+		r.logger.Error("查询订单失败", zap.Error(err))
+		return nil, err
 	}
-
-	allowedNextStatus, exists := validTransitions[currentStatus]
-	if !exists {
-		return false
+	if len(order.OrderItems) == 0 {
+		return nil, v1.ErrOrderNotPayable
 	}
+	return &order, nil
+}
 
-	for _, status := range allowedNextStatus {
-		if status == newStatus {
-			return true
+// PayOrder 支付成功后将订单下待付款的订单项置为待发货，并扣减锁定的库存
+func (r *userOrderRepository) PayOrder(ctx context.Context, orderID uint64, paymentMethod int, remark string) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		var orderItems []model.UserOrderItem
+		if err := r.DB(ctx).Where("order_id = ? AND status = ?", orderID, common.ORDER_STATUS_PENDING).Find(&orderItems).Error; err != nil {
+			r.logger.Error("查询订单项失败", zap.Error(err))
+			return err
 		}
-	}
+		// 订单已失效或已处理
+		if len(orderItems) == 0 {
+			return v1.ErrOrderNotPayable
+		}
+		for i := range orderItems {
+			if err := r.orderStatusRepository.Transit(ctx, nil, &orderItems[i], common.ORDER_STATUS_SHIPPED, common.ORDER_ACTOR_SYSTEM, "", remark); err != nil {
+				return err
+			}
+			if err := r.productRepository.CommitStock(ctx, nil, orderItems[i].ProductID, orderItems[i].Quantity); err != nil {
+				return err
+			}
+		}
+		if err := r.DB(ctx).Model(&model.UserOrder{}).Where("id = ?", orderID).Update("payment_method", paymentMethod).Error; err != nil {
+			r.logger.Error("更新订单支付方式失败", zap.Error(err))
+			return err
+		}
+		var paidFee money.Money
+		for _, orderItem := range orderItems {
+			paidFee += orderItem.TotalFee
+		}
+		if err := r.userAssetRepository.AddConsumption(ctx, nil, orderItems[0].UserId, paidFee); err != nil {
+			return err
+		}
+		if err := r.ostrichRepository.Register(ctx, nil, orderItems); err != nil {
+			return err
+		}
+		// 支付完成后重新计算会员身份
+		return r.memberTierRepository.EvaluateRole(ctx, nil, orderItems[0].UserId, common.ORDER_ACTOR_SYSTEM, "", "订单支付")
+	})
+}
 
-	return false
+// releaseOrderItem 释放未支付订单项占用的库存和优惠券
+func (r *userOrderRepository) releaseOrderItem(ctx context.Context, orderItem *model.UserOrderItem) error {
+	if err := r.productRepository.ReleaseStock(ctx, nil, orderItem.ProductID, orderItem.Quantity); err != nil {
+		return err
+	}
+	return r.userCouponRepository.ReleaseCoupon(ctx, nil, orderItem)
 }
 
 // GetOrderDetail 获取订单详情
@@ -695,7 +800,7 @@ func (r *userOrderRepository) GetOrderDetail(ctx context.Context, userID string,
 		DetailAddress: address.Street,
 	}
 
-	totalPrice := orderItem.CurrentPrice * float64(orderItem.Quantity)
+	totalPrice := orderItem.CurrentPrice.Mul(orderItem.Quantity)
 	couponDiscount := orderItem.CouponPrice
 	memberDiscount := orderItem.MemberDiscount
 	totalShippingFee := orderItem.CourierFeeMin
@@ -717,6 +822,28 @@ func (r *userOrderRepository) GetOrderDetail(ctx context.Context, userID string,
 	if orderItem.CompletedAt != nil {
 		completedAt = orderItem.CompletedAt.Format("2006-01-02 15:04:05")
 	}
+	// 状态时间线，发货和完成时间以实际变更记录为准
+	statusLogs, err := r.orderStatusRepository.GetTimeline(ctx, orderItemID)
+	if err != nil {
+		return nil, err
+	}
+	timeline := make([]model.OrderTimelineDTO, 0, len(statusLogs))
+	for _, log := range statusLogs {
+		createdAt := log.CreatedAt.Format("2006-01-02 15:04:05")
+		timeline = append(timeline, model.OrderTimelineDTO{
+			Status:     int(log.ToStatus),
+			StatusText: r.GetOrderStatusText(int(log.ToStatus)),
+			ActorType:  log.ActorType,
+			Remark:     log.Remark,
+			CreatedAt:  createdAt,
+		})
+		if log.FromStatus == common.ORDER_STATUS_SHIPPED && log.ToStatus == common.ORDER_STATUS_RECEIVED {
+			shippedAt = createdAt
+		}
+		if log.ToStatus == common.ORDER_STATUS_COMPLETE {
+			completedAt = createdAt
+		}
+	}
 	response := &model.OrderDetailResponse{
 		OrderID:        order.ID,
 		OrderNo:        order.OrderNo,
@@ -741,6 +868,7 @@ func (r *userOrderRepository) GetOrderDetail(ctx context.Context, userID string,
 		StoreID:        storeID,
 		StoreName:      storeName,
 		StoreLogo:      storeLogo,
+		Timeline:       timeline,
 	}
 
 	return response, nil
@@ -751,12 +879,10 @@ func getPayMethodText(payMethod int) string {
 	switch payMethod {
 	case 0:
 		return "未支付"
-	case 1:
-		return "余额支付"
-	case 2:
+	case common.PAYMENT_METHOD_WECHAT:
 		return "微信支付"
-	case 3:
-		return "支付宝支付"
+	case common.PAYMENT_METHOD_BALANCE:
+		return "余额支付"
 	default:
 		return "未知方式"
 	}
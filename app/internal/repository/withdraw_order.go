@@ -6,116 +6,205 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	v1 "app/api/v1"
 	"app/internal/common"
 	"app/internal/model"
-
-	"gorm.io/gorm"
+	"app/pkg/money"
 )
 
 type WithdrawOrderRepository interface {
+	GetWithdrawConfig(ctx context.Context) (*model.WithdrawConfig, error)
 	CreateWithdraw(ctx context.Context, userID string, req model.CreateWithdrawRequest) error
 	GetWithdrawList(ctx context.Context, userID string, status *uint8, page, pageSize int) (*model.WithdrawListResponse, error)
 	GetWithdrawDetail(ctx context.Context, userID string, withdrawID uint64) (*model.WithdrawDetailResponse, error)
+	SettleWithdrawals(ctx context.Context) (int, error)
 }
 
 type withdrawOrderRepository struct {
 	*Repository
+	ledgerRepository        LedgerRepository
+	settingsRepository      SettingsRepository
+	payoutAccountRepository PayoutAccountRepository
 }
 
-func NewWithdrawOrderRepository(repository *Repository) WithdrawOrderRepository {
+func NewWithdrawOrderRepository(
+	repository *Repository,
+	ledgerRepository LedgerRepository,
+	settingsRepository SettingsRepository,
+	payoutAccountRepository PayoutAccountRepository,
+) WithdrawOrderRepository {
 	return &withdrawOrderRepository{
-		Repository: repository,
+		Repository:              repository,
+		ledgerRepository:        ledgerRepository,
+		settingsRepository:      settingsRepository,
+		payoutAccountRepository: payoutAccountRepository,
+	}
+}
+
+// settleBatchSize 每次结算的提现单数
+const settleBatchSize = 100
+
+// GetWithdrawConfig 获取提现手续费与限额配置，未配置时按1%收取手续费且不限额
+func (r *withdrawOrderRepository) GetWithdrawConfig(ctx context.Context) (*model.WithdrawConfig, error) {
+	withdrawConfig := &model.WithdrawConfig{FeeRate: 0.01}
+	value, err := r.settingsRepository.GetSettings(ctx, common.SETTINGS_WITHDRAW_CONFIG)
+	if err != nil || value == "" {
+		return withdrawConfig, nil
+	}
+	if err := json.Unmarshal([]byte(value), withdrawConfig); err != nil {
+		r.logger.Error("解析提现配置失败", zap.Error(err))
+		return nil, err
 	}
+	return withdrawConfig, nil
 }
 
-// CreateWithdraw 创建提现单
+// CreateWithdraw 创建提现单，提现金额从余额转入冻结余额，打款完成或被拒绝后再结算
+// 校验限额前锁定用户资产，同一用户的提现申请串行处理，避免并发绕过每日、每月限额
 func (r *withdrawOrderRepository) CreateWithdraw(ctx context.Context, userID string, req model.CreateWithdrawRequest) error {
-	// 校验余额是否足够
-	var userAsset model.UserAsset
-	if err := r.DB(ctx).Where("user_id = ?", userID).First(&userAsset).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("用户资产不存在")
-		}
-		r.logger.Error("查询用户资产失败: " + err.Error())
+	withdrawConfig, err := r.GetWithdrawConfig(ctx)
+	if err != nil {
 		return err
 	}
-
-	if userAsset.Balance < req.Amount {
-		return errors.New("余额不足")
+	if withdrawConfig.MinAmount > 0 && req.Amount < withdrawConfig.MinAmount {
+		return fmt.Errorf("%w，单笔最低提现%s元", v1.ErrWithdrawLimit, withdrawConfig.MinAmount)
+	}
+	if withdrawConfig.MaxAmount > 0 && req.Amount > withdrawConfig.MaxAmount {
+		return fmt.Errorf("%w，单笔最高提现%s元", v1.ErrWithdrawLimit, withdrawConfig.MaxAmount)
+	}
+	fee := money.Max(req.Amount.MulRate(withdrawConfig.FeeRate), withdrawConfig.MinFee)
+	if fee >= req.Amount {
+		return fmt.Errorf("%w，提现金额不足以支付手续费%s元", v1.ErrWithdrawLimit, fee)
 	}
 
-	// 计算手续费和实际到账金额（这里以1%手续费为例）
-	fee := req.Amount * 0.01
-	actualAmount := req.Amount - fee
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		tx := r.DB(ctx)
+		var userAsset model.UserAsset
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).First(&userAsset).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("余额不足")
+			}
+			r.logger.Error("查询用户资产失败", zap.Error(err))
+			return err
+		}
+		if userAsset.Balance < req.Amount {
+			return errors.New("余额不足")
+		}
 
-	// 开始事务
-	tx := r.DB(ctx).Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+		account, err := r.payoutAccountRepository.GetPayoutAccount(ctx, userID, req.PayoutAccountID)
+		if err != nil {
+			return err
 		}
-	}()
-
-	// 生成提现单号
-	withdrawNo := fmt.Sprintf("W%s%s", time.Now().Format("20060102"), common.GenerateOrderNo())
-
-	// 创建提现单
-	now := time.Now()
-	withdrawOrder := model.WithdrawOrder{
-		WithdrawNo:   withdrawNo,
-		UserID:       userID,
-		Amount:       req.Amount,
-		Fee:          fee,
-		ActualAmount: actualAmount,
-		Status:       0, // 待审核
-		// BankName:     req.BankName,
-		// AccountName:  req.AccountName,
-		// AccountNo:    req.AccountNo,
-		// Remark:       req.Remark,
-		CreatedAt: &now,
-		UpdatedAt: &now,
-	}
-
-	if err := tx.Create(&withdrawOrder).Error; err != nil {
-		tx.Rollback()
-		r.logger.Error("创建提现单失败: " + err.Error())
-		return err
-	}
 
-	// 扣减用户余额
-	if err := tx.Model(&model.UserAsset{}).Where("user_id = ?", userID).
-		Update("balance", gorm.Expr("balance - ?", req.Amount)).Error; err != nil {
-		tx.Rollback()
-		r.logger.Error("扣减用户余额失败: " + err.Error())
-		return err
-	}
+		now := time.Now()
+		dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		limits := []struct {
+			limit money.Money
+			since time.Time
+			name  string
+		}{
+			{withdrawConfig.DailyLimit, dayStart, "每日"},
+			{withdrawConfig.MonthlyLimit, monthStart, "每月"},
+		}
+		for _, l := range limits {
+			if l.limit <= 0 {
+				continue
+			}
+			var withdrawn money.Money
+			if err := tx.Model(&model.WithdrawOrder{}).
+				Select("COALESCE(SUM(amount), 0)").
+				Where("user_id = ? AND status <> ? AND created_at >= ?", userID, common.WITHDRAW_STATUS_REJECTED, l.since).
+				Scan(&withdrawn).Error; err != nil {
+				r.logger.Error("统计已提现金额失败", zap.Error(err))
+				return err
+			}
+			if withdrawn+req.Amount > l.limit {
+				return fmt.Errorf("%w，%s最多提现%s元，还可提现%s元", v1.ErrWithdrawLimit, l.name, l.limit,
+					money.Max(l.limit-withdrawn, 0))
+			}
+		}
 
-	// 记录资产变动日志
-	assetRecord := model.UserAssetRecord{
-		UserId:        userID,
-		BusinessType:  common.BUSINESS_TYPE_WITHDRAW,
-		ActionType:    common.ACTION_TYPE_WITHDRAW,
-		AssetType:     common.ASSET_TYPE_BALANCE,
-		ActionNum:     float32(-req.Amount), // 负数表示支出
-		LeftNum:       float32(userAsset.Balance - req.Amount),
-		RelationId:    int(withdrawOrder.ID),
-		RelationTitle: "提现",
-		CreatedAt:     now,
-	}
+		// 收款信息保存快照，删除收款账户不影响处理中的提现单
+		withdrawOrder := model.WithdrawOrder{
+			WithdrawNo:      fmt.Sprintf("W%s%s", now.Format("20060102"), common.GenerateOrderNo()),
+			UserID:          userID,
+			Amount:          req.Amount,
+			Fee:             fee,
+			ActualAmount:    req.Amount - fee,
+			Status:          common.WITHDRAW_STATUS_PENDING,
+			PayoutType:      account.Type,
+			PayoutAccountID: account.ID,
+			BankName:        account.BankName,
+			AccountName:     account.AccountName,
+			AccountNo:       account.AccountNo,
+			Remark:          req.Remark,
+			CreatedAt:       &now,
+			UpdatedAt:       &now,
+		}
+		if err := tx.Create(&withdrawOrder).Error; err != nil {
+			r.logger.Error("创建提现单失败", zap.Error(err))
+			return err
+		}
 
-	if err := tx.Create(&assetRecord).Error; err != nil {
-		tx.Rollback()
-		r.logger.Error("创建资产变动记录失败: " + err.Error())
-		return err
-	}
+		return r.ledgerRepository.Hold(ctx, tx, model.LedgerEntry{
+			UserID:        userID,
+			BusinessType:  common.BUSINESS_TYPE_WITHDRAW,
+			ActionType:    common.ACTION_TYPE_WITHDRAW,
+			Amount:        req.Amount.Fen(),
+			RelationID:    int(withdrawOrder.ID),
+			RelationTitle: "提现",
+		})
+	})
+}
 
-	// 提交事务
-	if err := tx.Commit().Error; err != nil {
-		r.logger.Error("提交事务失败: " + err.Error())
-		return err
+// SettleWithdrawals 结算已打款或已拒绝的提现单：已打款的扣除冻结金额，已拒绝的解冻退回可用余额
+// 管理后台只修改提现单状态，由定时任务统一结算；返回本次结算的提现单数
+func (r *withdrawOrderRepository) SettleWithdrawals(ctx context.Context) (int, error) {
+	var withdrawOrders []model.WithdrawOrder
+	if err := r.DB(ctx).Where("status IN ? AND settled = 0",
+		[]uint8{common.WITHDRAW_STATUS_COMPLETE, common.WITHDRAW_STATUS_REJECTED}).
+		Order("id ASC").Limit(settleBatchSize).Find(&withdrawOrders).Error; err != nil {
+		r.logger.Error("查询待结算提现单失败", zap.Error(err))
+		return 0, err
 	}
+	settled := 0
+	for _, withdrawOrder := range withdrawOrders {
+		if err := r.settleWithdraw(ctx, withdrawOrder); err != nil {
+			r.logger.Error("结算提现单失败", zap.Uint64("withdraw_id", withdrawOrder.ID), zap.Error(err))
+			continue
+		}
+		settled++
+	}
+	return settled, nil
+}
 
-	return nil
+func (r *withdrawOrderRepository) settleWithdraw(ctx context.Context, withdrawOrder model.WithdrawOrder) error {
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		tx := r.DB(ctx)
+		result := tx.Model(&model.WithdrawOrder{}).
+			Where("id = ? AND status = ? AND settled = 0", withdrawOrder.ID, withdrawOrder.Status).
+			Updates(map[string]interface{}{
+				"settled":    1,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if withdrawOrder.Status == common.WITHDRAW_STATUS_REJECTED {
+			return r.ledgerRepository.Release(ctx, tx, common.BUSINESS_TYPE_WITHDRAW, int(withdrawOrder.ID))
+		}
+		return r.ledgerRepository.Capture(ctx, tx, common.BUSINESS_TYPE_WITHDRAW, int(withdrawOrder.ID))
+	})
 }
 
 // GetWithdrawList 获取提现单列表
@@ -163,9 +252,10 @@ func (r *withdrawOrderRepository) GetWithdrawList(ctx context.Context, userID st
 			ActualAmount: order.ActualAmount,
 			Status:       order.Status,
 			StatusText:   getWithdrawStatusText(order.Status),
+			PayoutType:   order.PayoutType,
 			BankName:     order.BankName,
-			AccountName:  order.AccountName,
-			AccountNo:    order.AccountNo,
+			AccountName:  maskAccountName(decryptAccountField(order.AccountName)),
+			AccountNo:    maskAccountNo(decryptAccountField(order.AccountNo)),
 			CreatedAt:    order.CreatedAt,
 		})
 	}
@@ -200,8 +290,8 @@ func (r *withdrawOrderRepository) GetWithdrawDetail(ctx context.Context, userID
 		StatusText:   getWithdrawStatusText(withdrawOrder.Status),
 		RejectReason: withdrawOrder.RejectReason,
 		BankName:     withdrawOrder.BankName,
-		AccountName:  withdrawOrder.AccountName,
-		AccountNo:    withdrawOrder.AccountNo,
+		AccountName:  maskAccountName(decryptAccountField(withdrawOrder.AccountName)),
+		AccountNo:    maskAccountNo(decryptAccountField(withdrawOrder.AccountNo)),
 		Remark:       withdrawOrder.Remark,
 		AuditTime:    withdrawOrder.AuditTime,
 		CompleteTime: withdrawOrder.CompleteTime,
@@ -213,13 +303,13 @@ func (r *withdrawOrderRepository) GetWithdrawDetail(ctx context.Context, userID
 // getWithdrawStatusText 获取提现状态文本
 func getWithdrawStatusText(status uint8) string {
 	switch status {
-	case 0:
+	case common.WITHDRAW_STATUS_PENDING:
 		return "审核中"
-	case 1:
+	case common.WITHDRAW_STATUS_PROCESSING:
 		return "处理中"
-	case 2:
+	case common.WITHDRAW_STATUS_COMPLETE:
 		return "已打款"
-	case 3:
+	case common.WITHDRAW_STATUS_REJECTED:
 		return "已拒绝"
 	default:
 		return "未知状态"
@@ -38,6 +38,11 @@ func NewHTTPServer(
 	productReviewHandler *handler.ProductReviewHandler,
 	productEvaluateHandler *handler.ProductEvaluateHandler,
 	userEarningHandler *handler.UserEarningHandler,
+	paymentHandler *handler.PaymentHandler,
+	storeHandler *handler.StoreHandler,
+	pointHandler *handler.PointHandler,
+	ostrichHandler *handler.OstrichHandler,
+	reconciliationHandler *handler.ReconciliationHandler,
 ) *http.Server {
 	gin.SetMode(gin.DebugMode)
 	s := http.NewServer(
@@ -105,11 +110,27 @@ func NewHTTPServer(
 			productRouter.GET("/detail", productHandler.GetProductByID)
 			productRouter.GET("/details", productHandler.GetProductDetailsByCartIDs)
 		}
+		// 运营管理接口，使用管理令牌鉴权
+		adminRouter := v1.Group("/admin").Use(middleware.AdminMiddleware(logger, conf))
+		{
+			adminRouter.POST("/product/stock", productHandler.UpdateStock)
+			adminRouter.POST("/account/role", userHandler.AssignRole)
+			adminRouter.POST("/ostrich/update", ostrichHandler.UpdateOstrich)
+			adminRouter.POST("/ostrich/status", ostrichHandler.ChangeOstrichStatus)
+			adminRouter.GET("/reconcile/runs", reconciliationHandler.GetRuns)
+			adminRouter.GET("/reconcile/discrepancies", reconciliationHandler.GetDiscrepancies)
+			adminRouter.POST("/refund/approve", refundOrderHandler.ApproveRefund)
+			adminRouter.POST("/refund/reject", refundOrderHandler.RejectRefund)
+		}
 		// 自由市场
 		freeMarketRouter := v1.Group("/market").Use(middleware.SignMiddleware(logger, conf))
 		{
 			freeMarketRouter.GET("/mine", freeMarketMineHandler.GetUserEggsSummary)
 			freeMarketRouter.POST("/update-price", freeMarketMineHandler.UpdateEggPrice)
+			freeMarketRouter.POST("/publish", middleware.IdempotencyMiddleware(logger), freeMarketMineHandler.PublishOrder)
+			freeMarketRouter.POST("/cancel", freeMarketMineHandler.CancelOrder)
+			freeMarketRouter.GET("/book", freeMarketMineHandler.GetOrderBook)
+			freeMarketRouter.GET("/kline", freeMarketMineHandler.GetCandles)
 		}
 		// 购物车
 		cartRouter := v1.Group("/cart").Use(middleware.SignMiddleware(logger, conf))
@@ -121,12 +142,20 @@ func NewHTTPServer(
 		// 订单
 		orderRouter := v1.Group("/order").Use(middleware.SignMiddleware(logger, conf))
 		{
-			orderRouter.POST("/create", userOrderHandler.CreateOrders)
+			orderRouter.POST("/create", middleware.IdempotencyMiddleware(logger), userOrderHandler.CreateOrders)
+			orderRouter.POST("/freight", userOrderHandler.QuoteFreight)
 			orderRouter.GET("/list", userOrderHandler.GetOrderList)
 			orderRouter.GET("/products", userOrderHandler.GetOrderProductDetails)
 			orderRouter.POST("/status", userOrderHandler.UpdateOrderStatus)
 			orderRouter.GET("/detail/:order_item_id", userOrderHandler.GetOrderDetail)
 		}
+		// 支付
+		payRouter := v1.Group("/pay")
+		{
+			payRouter.POST("/prepay", middleware.SignMiddleware(logger, conf), paymentHandler.Prepay)
+			// 支付渠道回调，通过签名校验来源
+			payRouter.POST("/notify", paymentHandler.Notify)
+		}
 		// 用户地址
 		addressRouter := v1.Group("/address").Use(middleware.SignMiddleware(logger, conf))
 		{
@@ -139,7 +168,9 @@ func NewHTTPServer(
 		assetRouter := v1.Group("/asset").Use(middleware.SignMiddleware(logger, conf))
 		{
 			assetRouter.GET("/info", userAssetHandler.GetUserAsset)
-			assetRouter.POST("/recharge", userAssetHandler.RechargeBalance)
+			assetRouter.POST("/recharge", middleware.IdempotencyMiddleware(logger), userAssetHandler.RechargeBalance)
+			assetRouter.GET("/recharge/tiers", userAssetHandler.GetRechargeBonusTiers)
+			assetRouter.GET("/recharge/list", userAssetHandler.GetRechargeList)
 			assetRouter.GET("/balance/records", userAssetHandler.GetBalanceRecords)
 			assetRouter.GET("/withdraw/records", userAssetHandler.GetWithdrawRecords)
 			assetRouter.GET("/exchange/records", userAssetHandler.GetExchangeRecords)
@@ -151,17 +182,30 @@ func NewHTTPServer(
 			couponRouter.GET("/list", userCouponHandler.GetAllUserCoupons)
 			couponRouter.POST("/claim", userCouponHandler.ClaimCoupon)
 			couponRouter.GET("/detail", userCouponHandler.GetUserCouponDetail)
+			couponRouter.POST("/recommend", userCouponHandler.RecommendCoupons)
+		}
+		// 积分签到与批次
+		pointRouter := v1.Group("/point").Use(middleware.SignMiddleware(logger, conf))
+		{
+			pointRouter.POST("/check-in", middleware.IdempotencyMiddleware(logger), pointHandler.CheckIn)
+			pointRouter.GET("/lots", pointHandler.GetPointLots)
+		}
+		// 认养鸵鸟
+		ostrichRouter := v1.Group("/ostrich").Use(middleware.SignMiddleware(logger, conf))
+		{
+			ostrichRouter.GET("/list", ostrichHandler.GetUserOstriches)
+			ostrichRouter.GET("/detail", ostrichHandler.GetUserOstrichDetail)
 		}
 		// 积分兑换配置
 		pointExchangeRouter := v1.Group("/point/exchange").Use(middleware.SignMiddleware(logger, conf))
 		{
 			pointExchangeRouter.GET("/list", pointExchangeConfigHandler.GetPointExchangeConfigList)
-			pointExchangeRouter.POST("/exchange", pointExchangeConfigHandler.ExchangePoints)
+			pointExchangeRouter.POST("/exchange", middleware.IdempotencyMiddleware(logger), pointExchangeConfigHandler.ExchangePoints)
 		}
 		// 退款相关路由
 		refundRouter := v1.Group("/refund").Use(middleware.SignMiddleware(logger, conf))
 		{
-			refundRouter.POST("/create", refundOrderHandler.CreateRefund)
+			refundRouter.POST("/create", middleware.IdempotencyMiddleware(logger), refundOrderHandler.CreateRefund)
 			refundRouter.GET("/list", refundOrderHandler.GetRefundList)
 			refundRouter.GET("/detail/:refund_id", refundOrderHandler.GetRefundDetail)
 			refundRouter.POST("/cancel", refundOrderHandler.CancelRefund)
@@ -170,9 +214,12 @@ func NewHTTPServer(
 		// 提现相关路由
 		withdrawRouter := v1.Group("/withdraw").Use(middleware.SignMiddleware(logger, conf))
 		{
-			withdrawRouter.POST("/create", withdrawOrderHandler.CreateWithdraw)
+			withdrawRouter.POST("/create", middleware.IdempotencyMiddleware(logger), withdrawOrderHandler.CreateWithdraw)
 			withdrawRouter.GET("/list", withdrawOrderHandler.GetWithdrawList)
 			withdrawRouter.GET("/detail/:withdraw_id", withdrawOrderHandler.GetWithdrawDetail)
+			withdrawRouter.POST("/account/bind", withdrawOrderHandler.BindPayoutAccount)
+			withdrawRouter.GET("/account/list", withdrawOrderHandler.GetPayoutAccountList)
+			withdrawRouter.POST("/account/delete", withdrawOrderHandler.DeletePayoutAccount)
 		}
 		// 评价相关路由
 		reviewRouter := v1.Group("/review").Use(middleware.SignMiddleware(logger, conf))
@@ -191,9 +238,13 @@ func NewHTTPServer(
 		// 用户收益相关路由
 		earningRouter := v1.Group("/earning").Use(middleware.SignMiddleware(logger, conf))
 		{
-			earningRouter.POST("/add", userEarningHandler.AddEarning)
 			earningRouter.GET("/list", userEarningHandler.GetEarningList)
 		}
+		// 店铺相关路由
+		storeRouter := v1.Group("/store").Use(middleware.SignMiddleware(logger, conf))
+		{
+			storeRouter.GET("/:store_id", storeHandler.GetStorePage)
+		}
 	}
 
 	return s
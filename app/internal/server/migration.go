@@ -2,9 +2,15 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"strings"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"app/internal/common"
+	"app/internal/model"
 	"app/pkg/log"
 )
 
@@ -13,22 +19,458 @@ type Migrate struct {
 	log *log.Logger
 }
 
-func NewMigrate(db *gorm.DB, log *log.Logger) *Migrate {
+func NewMigrate(db []*gorm.DB, log *log.Logger) *Migrate {
 	return &Migrate{
-		db:  db,
+		db:  db[0],
 		log: log,
 	}
 }
 func (m *Migrate) Start(ctx context.Context) error {
-	//if err := m.db.AutoMigrate(&model.User{}); err != nil {
-	//	m.log.Error("user migrate error", zap.Error(err))
-	//	return err
-	//}
-	//m.log.Info("AutoMigrate success")
-	//os.Exit(0)
+	// 新增的表
+	if err := m.db.AutoMigrate(
+		&model.OrderStatusLog{},
+		&model.PaymentTransaction{},
+		&model.SchemaMigration{},
+		&model.AccountRoleLog{},
+		&model.FreightTemplate{},
+		&model.FreightRule{},
+		&model.Store{},
+		&model.ReconciliationRun{},
+		&model.ReconciliationDiscrepancy{},
+		&model.RechargeOrder{},
+		&model.PayoutAccount{},
+		&model.UserAssetHold{},
+		&model.PointLot{},
+		&model.PointCheckIn{},
+		&model.UserCouponClaim{},
+		&model.CouponClaimLog{},
+		&model.MarketOrder{},
+		&model.MarketTrade{},
+		&model.MarketPriceLog{},
+		&model.MarketCandle{},
+		&model.Ostrich{},
+		&model.OstrichStatusLog{},
+		&model.FarmProduction{},
+		&model.FarmProductionAllocation{},
+	); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 已有表只补充新增字段，不改动原有字段
+	if err := m.addColumns(&model.Product{}, "Stock", "LockedStock", "FreightTemplateID", "Weight"); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 未维护库存的商品改为不限库存
+	if err := m.migrateProductStock(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.addColumns(&model.UserAssetRecord{}, "JournalNo", "AccountType"); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.addColumns(&model.UserAsset{}, "FrozenBalance", "Eggs", "FrozenEggs"); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.addColumns(&model.UserAssetHold{}, "CapturedAmount"); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.addColumns(&model.UserCoupon{}, "StartAt", "OrderItemID", "OrderNo", "ExpireReminded"); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.migrateProductCoupon(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.addColumns(&model.ProductCoupon{}, "TotalQuantity", "ClaimedQuantity", "PerUserLimit", "ClaimStartAt", "ClaimEndAt"); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 按已领取的用户优惠券回填领取数量和每人领取次数
+	if err := m.backfillCouponClaims(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 收益记录关联产出的鸵鸟，用于按用户、鸵鸟、日期去重
+	if err := m.migrateUserEarning(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	if err := m.migrateWithdrawOrder(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 根据商品上的店铺信息初始化店铺表
+	if err := m.seedStores(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 金额字段由元转换为整数分
+	if err := m.migrateMoneyColumns(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 按已支付订单和已完成退款回填用户累计消费
+	if err := m.backfillConsumption(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 已有积分生成不过期的初始批次
+	if err := m.seedPointLots(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	// 自由市场未出售的鸟蛋转为出售挂单
+	if err := m.seedMarketOrders(); err != nil {
+		m.log.Error("migrate error", zap.Error(err))
+		return err
+	}
+	m.log.Info("AutoMigrate success")
+	os.Exit(0)
 	return nil
 }
 func (m *Migrate) Stop(ctx context.Context) error {
 	m.log.Info("AutoMigrate stop")
 	return nil
 }
+
+// addColumns 为已有表添加缺失的字段
+func (m *Migrate) addColumns(value interface{}, fields ...string) error {
+	migrator := m.db.Migrator()
+	for _, field := range fields {
+		if migrator.HasColumn(value, field) {
+			continue
+		}
+		if err := migrator.AddColumn(value, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateWithdrawOrder 提现单增加收款方式和结算字段，收款信息改为加密存储需放宽字段长度
+// 历史提现单申请时已直接扣减余额，没有冻结金额，新增结算字段时全部标记为已结算
+func (m *Migrate) migrateWithdrawOrder() error {
+	migrator := m.db.Migrator()
+	if err := m.addColumns(&model.WithdrawOrder{}, "PayoutType", "PayoutAccountID"); err != nil {
+		return err
+	}
+	if !migrator.HasColumn(&model.WithdrawOrder{}, "Settled") {
+		if err := migrator.AddColumn(&model.WithdrawOrder{}, "Settled"); err != nil {
+			return err
+		}
+		if err := m.db.Model(&model.WithdrawOrder{}).Where("1 = 1").Update("settled", 1).Error; err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"AccountName", "AccountNo"} {
+		if err := migrator.AlterColumn(&model.WithdrawOrder{}, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateProductStock 库存字段默认值改为不限库存，之前以默认值0添加字段时，从未维护过库存的商品全部改为不限库存
+func (m *Migrate) migrateProductStock() error {
+	version := "product_stock_unlimited"
+	var count int64
+	if err := m.db.Model(&model.SchemaMigration{}).Where("version = ?", version).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := m.db.Migrator().AlterColumn(&model.Product{}, "Stock"); err != nil {
+		return err
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Product{}).Where("stock = 0 AND locked_stock = 0").
+			Update("stock", common.PRODUCT_STOCK_UNLIMITED).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.SchemaMigration{Version: version}).Error
+	})
+}
+
+// migrateUserEarning 收益记录增加鸵鸟ID和唯一索引，历史收益的鸵鸟ID为空，不受唯一索引限制
+func (m *Migrate) migrateUserEarning() error {
+	if err := m.addColumns(&model.UserEarning{}, "OstrichID"); err != nil {
+		return err
+	}
+	migrator := m.db.Migrator()
+	if !migrator.HasIndex(&model.UserEarning{}, "uk_user_ostrich_date") {
+		if err := migrator.CreateIndex(&model.UserEarning{}, "uk_user_ostrich_date"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateProductCoupon 优惠券模板增加类型、适用范围、有效期和叠加规则
+// 原有优惠券绑定单个商品，有使用门槛的视为满减券，其余为立减券
+func (m *Migrate) migrateProductCoupon() error {
+	migrator := m.db.Migrator()
+	if err := m.addColumns(&model.ProductCoupon{}, "DiscountRate", "MaxDiscount", "StartAt", "ValidDays", "StackMember", "Exclusive"); err != nil {
+		return err
+	}
+	if !migrator.HasColumn(&model.ProductCoupon{}, "Kind") {
+		if err := migrator.AddColumn(&model.ProductCoupon{}, "Kind"); err != nil {
+			return err
+		}
+		if err := m.db.Model(&model.ProductCoupon{}).Unscoped().Where("available_min_price > 0").
+			Update("kind", common.COUPON_KIND_THRESHOLD).Error; err != nil {
+			return err
+		}
+	}
+	if !migrator.HasColumn(&model.ProductCoupon{}, "ScopeType") {
+		if err := m.addColumns(&model.ProductCoupon{}, "ScopeType", "ScopeIDs"); err != nil {
+			return err
+		}
+		if err := m.db.Model(&model.ProductCoupon{}).Unscoped().Where("product_id > 0").
+			Updates(map[string]interface{}{
+				"scope_type": common.COUPON_SCOPE_PRODUCT,
+				"scope_ids":  gorm.Expr("CAST(`product_id` AS CHAR)"),
+			}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillCouponClaims 启用发放总量和每人限领前已领取的优惠券没有计数，回填一次
+func (m *Migrate) backfillCouponClaims() error {
+	version := "backfill:coupon_claim"
+	var count int64
+	if err := m.db.Model(&model.SchemaMigration{}).Where("version = ?", version).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("UPDATE `product_coupon` AS pc " +
+			"JOIN (SELECT `coupon_id`, COUNT(*) AS total FROM `user_coupon` WHERE `type` = 1 GROUP BY `coupon_id`) AS uc ON uc.`coupon_id` = pc.`id` " +
+			"SET pc.`claimed_quantity` = uc.total").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO `user_coupon_claim` (`user_id`, `coupon_id`, `claim_count`, `created_at`, `updated_at`) " +
+			"SELECT `user_id`, `coupon_id`, COUNT(*), NOW(), NOW() FROM `user_coupon` WHERE `type` = 1 GROUP BY `user_id`, `coupon_id`").Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.SchemaMigration{Version: version}).Error
+	})
+}
+
+// backfillConsumption 用户累计消费此前未维护，按已支付的订单项减去已完成的退款回填一次
+// 已取消的订单项只有退款完成的才算已支付
+func (m *Migrate) backfillConsumption() error {
+	version := "backfill:user_asset.consumption"
+	var count int64
+	if err := m.db.Model(&model.SchemaMigration{}).Where("version = ?", version).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		paidStatus := []uint8{common.ORDER_STATUS_SHIPPED, common.ORDER_STATUS_RECEIVED, common.ORDER_STATUS_EVALUATE,
+			common.ORDER_STATUS_COMPLETE, common.ORDER_STATUS_REFUNDED}
+		if err := tx.Exec("UPDATE `user_asset` AS ua "+
+			"LEFT JOIN (SELECT i.`user_id`, SUM(i.`total_fee`) AS total FROM `user_order_item` AS i "+
+			"WHERE i.`status` IN ? OR (i.`status` = ? AND EXISTS (SELECT 1 FROM `refund_order` AS ro WHERE ro.`order_item_id` = i.`id` AND ro.`status` = ?)) "+
+			"GROUP BY i.`user_id`) AS p ON p.`user_id` = ua.`user_id` "+
+			"LEFT JOIN (SELECT `user_id`, SUM(`refund_amount`) AS total FROM `refund_order` WHERE `status` = ? GROUP BY `user_id`) AS r ON r.`user_id` = ua.`user_id` "+
+			"SET ua.`consumption` = GREATEST(COALESCE(p.total, 0) - COALESCE(r.total, 0), 0)",
+			paidStatus, common.ORDER_STATUS_CLOSED, common.REFUND_STATUS_SUCCESS, common.REFUND_STATUS_SUCCESS).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.SchemaMigration{Version: version}).Error
+	})
+}
+
+// seedPointLots 启用积分批次前的积分没有批次记录，为每个有积分的用户生成一个不过期的初始批次
+func (m *Migrate) seedPointLots() error {
+	version := "seed:point_lot"
+	var count int64
+	if err := m.db.Model(&model.SchemaMigration{}).Where("version = ?", version).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO `point_lot` (`user_id`, `business_type`, `relation_id`, `points`, `remaining`, `reminded`, `created_at`, `updated_at`) " +
+			"SELECT `user_id`, 0, 0, `points`, `points`, 0, NOW(), NOW() FROM `user_asset` WHERE `points` > 0").Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.SchemaMigration{Version: version}).Error
+	})
+}
+
+// seedMarketOrders 自由市场此前只记录用户的鸟蛋和价格，未出售的记录转为出售挂单，鸟蛋计入用户的冻结鸟蛋
+// 原记录保留，已出售的记录仍作为历史出售记录展示
+func (m *Migrate) seedMarketOrders() error {
+	version := "seed:market_order"
+	var count int64
+	if err := m.db.Model(&model.SchemaMigration{}).Where("version = ?", version).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	where := "`status` = 0 AND `deleted_at` IS NULL AND `egg_num` > 0 AND `egg_price` > 0"
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO `market_order` (`user_id`, `publish_type`, `price`, `quantity`, `filled_quantity`, `status`, `created_at`, `updated_at`) "+
+			"SELECT `user_id`, ?, ROUND(`egg_price` * 100), `egg_num`, 0, ?, COALESCE(`created_at`, NOW()), NOW() FROM `free_market_mine` WHERE "+where+" ORDER BY `id`",
+			common.PUBLISH_TYPE_SELL, common.PUBLISH_PRODUCT_STATUS_NORMAL).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO `user_asset` (`user_id`, `frozen_eggs`, `created_at`, `updated_at`) " +
+			"SELECT `user_id`, SUM(`egg_num`), NOW(), NOW() FROM `free_market_mine` WHERE " + where + " GROUP BY `user_id` " +
+			"ON DUPLICATE KEY UPDATE `frozen_eggs` = `frozen_eggs` + VALUES(`frozen_eggs`)").Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.SchemaMigration{Version: version}).Error
+	})
+}
+
+// seedStores 将商品上冗余的店铺信息写入店铺表，已存在的店铺不覆盖
+func (m *Migrate) seedStores() error {
+	return m.db.Exec("INSERT IGNORE INTO `store` (`id`, `name`, `logo`, `status`, `created_at`, `updated_at`) "+
+		"SELECT `store_id`, MAX(`store_name`), MAX(`store_icon`), ?, NOW(), NOW() FROM `product` "+
+		"WHERE `store_id` > 0 AND `deleted_at` IS NULL GROUP BY `store_id`", common.STORE_STATUS_OPEN).Error
+}
+
+// moneyColumn 需要由元转换为分的金额字段
+type moneyColumn struct {
+	value interface{}
+	field string
+	where string // 只转换满足条件的行，为空时转换全部
+}
+
+// migrateMoneyColumns 将以元存储的金额字段转换为整数分
+// 每个字段先放宽为 DECIMAL 乘以 100，再改为 BIGINT；转换结果记录在 schema_migration 中，重复执行时跳过
+func (m *Migrate) migrateMoneyColumns() error {
+	columns := []moneyColumn{
+		{value: &model.UserOrder{}, field: "TotalFee"},
+		{value: &model.UserOrderItem{}, field: "CurrentPrice"},
+		{value: &model.UserOrderItem{}, field: "CourierFeeMin"},
+		{value: &model.UserOrderItem{}, field: "MemberDiscount"},
+		{value: &model.UserOrderItem{}, field: "CouponPrice"},
+		{value: &model.UserOrderItem{}, field: "TotalFee"},
+		{value: &model.UserAsset{}, field: "Balance"},
+		{value: &model.UserAsset{}, field: "Consumption"},
+		// 积分记录的数量本身就是整数，只转换余额记录
+		{value: &model.UserAssetRecord{}, field: "ActionNum", where: fmt.Sprintf("asset_type = %d", common.ASSET_TYPE_BALANCE)},
+		{value: &model.UserAssetRecord{}, field: "LeftNum", where: fmt.Sprintf("asset_type = %d", common.ASSET_TYPE_BALANCE)},
+		{value: &model.UserCoupon{}, field: "CouponPrice"},
+		{value: &model.UserCoupon{}, field: "AvailableMinPrice"},
+		{value: &model.ProductCoupon{}, field: "CouponPrice"},
+		{value: &model.ProductCoupon{}, field: "AvailableMinPrice"},
+		{value: &model.RefundOrder{}, field: "RefundAmount"},
+		{value: &model.RefundOrder{}, field: "Price"},
+		{value: &model.RefundItem{}, field: "Price"},
+		{value: &model.RefundItem{}, field: "RefundAmount"},
+		{value: &model.WithdrawOrder{}, field: "Amount"},
+		{value: &model.WithdrawOrder{}, field: "Fee"},
+		{value: &model.WithdrawOrder{}, field: "ActualAmount"},
+		{value: &model.PointExchangeConfig{}, field: "MinAmount"},
+		{value: &model.PointExchangeConfig{}, field: "ExchangeAmount"},
+		{value: &model.PointExchangeConfig{}, field: "Required"},
+		{value: &model.PointExchangeRecord{}, field: "MinAmount"},
+		{value: &model.PointExchangeRecord{}, field: "ExchangeAmount"},
+		{value: &model.PointExchangeRecord{}, field: "Required"},
+	}
+	for _, column := range columns {
+		if err := m.migrateMoneyColumn(column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrate) migrateMoneyColumn(column moneyColumn) error {
+	migrator := m.db.Migrator()
+	if !migrator.HasTable(column.value) {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: m.db}
+	if err := stmt.Parse(column.value); err != nil {
+		return err
+	}
+	field := stmt.Schema.LookUpField(column.field)
+	if field == nil {
+		return fmt.Errorf("字段 %s.%s 不存在", stmt.Schema.Table, column.field)
+	}
+	table, name := stmt.Schema.Table, field.DBName
+	version := "money_fen:" + table + "." + name
+
+	var count int64
+	if err := m.db.Model(&model.SchemaMigration{}).Where("version = ?", version).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		columnType, err := m.columnType(column.value, name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.Contains(columnType, "BIGINT"):
+			// 新建的表字段已是整数分，只记录版本
+			if err := m.db.Create(&model.SchemaMigration{Version: version}).Error; err != nil {
+				return err
+			}
+		case strings.Contains(columnType, "CHAR") || strings.Contains(columnType, "TEXT"):
+			// 字符串字段直接写入换算后的分，下一步再改为 BIGINT
+			expr := fmt.Sprintf("ROUND(COALESCE(CAST(NULLIF(TRIM(%s), '') AS DECIMAL(20,4)), 0) * 100)", name)
+			if err := m.convertMoneyColumn(table, name, expr, column.where, version); err != nil {
+				return err
+			}
+		default:
+			// 整数或浮点字段先放宽为 DECIMAL，避免乘以 100 后溢出或丢失精度
+			if err := m.db.Exec(fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN `%s` DECIMAL(20,4) NOT NULL DEFAULT 0", table, name)).Error; err != nil {
+				return err
+			}
+			expr := fmt.Sprintf("ROUND(%s * 100)", name)
+			if err := m.convertMoneyColumn(table, name, expr, column.where, version); err != nil {
+				return err
+			}
+		}
+		m.log.Info("金额字段已转换为分", zap.String("column", table+"."+name))
+	}
+	// 按模型定义改为 BIGINT
+	return migrator.AlterColumn(column.value, column.field)
+}
+
+// convertMoneyColumn 在同一个事务中换算金额并记录迁移版本
+func (m *Migrate) convertMoneyColumn(table, name, expr, where, version string) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		sql := fmt.Sprintf("UPDATE `%s` SET `%s` = %s", table, name, expr)
+		if where != "" {
+			sql += " WHERE " + where
+		}
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.SchemaMigration{Version: version}).Error
+	})
+}
+
+// columnType 获取字段的数据库类型，如 VARCHAR、INT、DOUBLE
+func (m *Migrate) columnType(value interface{}, name string) (string, error) {
+	columnTypes, err := m.db.Migrator().ColumnTypes(value)
+	if err != nil {
+		return "", err
+	}
+	for _, columnType := range columnTypes {
+		if columnType.Name() == name {
+			return strings.ToUpper(columnType.DatabaseTypeName()), nil
+		}
+	}
+	return "", fmt.Errorf("字段 %s 不存在", name)
+}
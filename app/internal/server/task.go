@@ -2,23 +2,30 @@ package server
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-co-op/gocron"
 	"go.uber.org/zap"
 
+	"app/internal/cache"
+	"app/internal/common"
+	"app/internal/handler"
 	"app/pkg/log"
 )
 
+// dailyTaskLockTTL 每日任务锁的保留时间，覆盖当天剩余时间
+const dailyTaskLockTTL = 24 * time.Hour
+
 type Task struct {
-	log       *log.Logger
-	scheduler *gocron.Scheduler
-	//activityHandler *handler.ActivityHandler
+	log         *log.Logger
+	scheduler   *gocron.Scheduler
+	taskHandler *handler.TaskHandler
 }
 
-func NewTask(log *log.Logger) *Task {
+func NewTask(log *log.Logger, taskHandler *handler.TaskHandler) *Task {
 	return &Task{
-		log: log,
-		//activityHandler: activityHandler,
+		log:         log,
+		taskHandler: taskHandler,
 	}
 }
 func (t *Task) Start(ctx context.Context) error {
@@ -28,44 +35,153 @@ func (t *Task) Start(ctx context.Context) error {
 		},
 	)
 
-	// eg: crontab task
-	//t.scheduler = gocron.NewScheduler(time.UTC)
-	//_, err := t.scheduler.Every("5s").Do(
-	//	func() {
-	//		t.log.Info("Task1 start...")
-	//		t.activityHandler.CheckActivity(ctx)
-	//		t.log.Info("Task1 done.")
-	//	},
-	//)
-	//if err != nil {
-	//	t.log.Error("Task1 error", zap.Error(err))
-	//}
-	//
-	//_, err = t.scheduler.Every("45s").Do(
-	//	func() {
-	//		t.log.Info("Task2 start...")
-	//		_ = t.activityHandler.UpdateActivityCache(ctx)
-	//		t.log.Info("Task2 done.")
-	//	},
-	//)
-	//if err != nil {
-	//	t.log.Error("Task2 error", zap.Error(err))
-	//}
-	//
-	//// 定时每天凌晨0点执行
-	//_, err = t.scheduler.Every(1).Day().At("00:00").Do(
-	//	func() {
-	//		t.log.Info("Task3 start...")
-	//		_ = t.activityHandler.CollectEarnings(ctx)
-	//		t.log.Info("Task3 done.")
-	//	},
-	//)
+	t.scheduler = gocron.NewScheduler(time.Local)
+	t.scheduler.SingletonModeAll()
+
+	// 每分钟关闭超时未支付的订单
+	_, err := t.scheduler.Every(1).Minute().Name("expire_orders").Do(
+		func() {
+			t.runExclusive(ctx, "expire_orders", 50*time.Second, t.taskHandler.ExpireUnpaidOrders)
+		},
+	)
+	if err != nil {
+		t.log.Error("expire_orders error", zap.Error(err))
+		return err
+	}
+
+	// 每分钟结算已打款或已拒绝的提现单
+	_, err = t.scheduler.Every(1).Minute().Name("settle_withdrawals").Do(
+		func() {
+			t.runExclusive(ctx, "settle_withdrawals", 50*time.Second, t.taskHandler.SettleWithdrawals)
+		},
+	)
+	if err != nil {
+		t.log.Error("settle_withdrawals error", zap.Error(err))
+		return err
+	}
+
+	// 每10分钟将已过期的优惠券标记为已过期
+	_, err = t.scheduler.Every(10).Minutes().Name("expire_coupons").Do(
+		func() {
+			t.runExclusive(ctx, "expire_coupons", 5*time.Minute, t.taskHandler.ExpireCoupons)
+		},
+	)
+	if err != nil {
+		t.log.Error("expire_coupons error", zap.Error(err))
+		return err
+	}
+
+	// 每小时提醒用户24小时内过期的优惠券
+	_, err = t.scheduler.Every(1).Hour().Name("remind_expiring_coupons").Do(
+		func() {
+			t.runExclusive(ctx, "remind_expiring_coupons", 50*time.Minute, t.taskHandler.RemindExpiringCoupons)
+		},
+	)
+	if err != nil {
+		t.log.Error("remind_expiring_coupons error", zap.Error(err))
+		return err
+	}
+
+	// 每天凌晨扣减已过期的积分，在对账之前完成
+	_, err = t.scheduler.Every(1).Day().At("01:00").Name("expire_points").Do(
+		func() {
+			t.runDaily(ctx, "expire_points", t.taskHandler.ExpirePoints)
+		},
+	)
+	if err != nil {
+		t.log.Error("expire_points error", zap.Error(err))
+		return err
+	}
+
+	// 每天上午提醒用户即将过期的积分
+	_, err = t.scheduler.Every(1).Day().At("10:00").Name("remind_expiring_points").Do(
+		func() {
+			t.runDaily(ctx, "remind_expiring_points", t.taskHandler.RemindExpiringPoints)
+		},
+	)
+	if err != nil {
+		t.log.Error("remind_expiring_points error", zap.Error(err))
+		return err
+	}
+
+	// 每天凌晨按日龄推进认养鸵鸟的生长阶段
+	_, err = t.scheduler.Every(1).Day().At("02:00").Name("advance_ostrich_stages").Do(
+		func() {
+			t.runDaily(ctx, "advance_ostrich_stages", t.taskHandler.AdvanceOstrichStages)
+		},
+	)
+	if err != nil {
+		t.log.Error("advance_ostrich_stages error", zap.Error(err))
+		return err
+	}
+
+	// 每天凌晨按农场产蛋记录生成认养收益，在对账之前完成
+	_, err = t.scheduler.Every(1).Day().At("02:30").Name("generate_daily_earnings").Do(
+		func() {
+			t.runDaily(ctx, "generate_daily_earnings", t.taskHandler.GenerateDailyEarnings)
+		},
+	)
+	if err != nil {
+		t.log.Error("generate_daily_earnings error", zap.Error(err))
+		return err
+	}
+
+	// 每天凌晨对账用户资产
+	_, err = t.scheduler.Every(1).Day().At("03:00").Name("reconcile_assets").Do(
+		func() {
+			t.runDaily(ctx, "reconcile_assets", t.taskHandler.ReconcileAssets)
+		},
+	)
+	if err != nil {
+		t.log.Error("reconcile_assets error", zap.Error(err))
+		return err
+	}
+
 	t.scheduler.StartBlocking()
 	return nil
 }
 
 func (t *Task) Stop(ctx context.Context) error {
-	t.scheduler.Stop()
+	if t.scheduler != nil {
+		t.scheduler.Stop()
+	}
 	t.log.Info("Task stop...")
 	return nil
 }
+
+// runExclusive 通过分布式锁保证多个任务实例中同一时间只有一个执行该任务，执行完成后释放锁
+func (t *Task) runExclusive(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context) error) {
+	t.runLocked(ctx, name, name, ttl, true, fn)
+}
+
+// runDaily 每天只执行一次的任务按日期加锁，执行后不释放，避免各实例时钟偏差导致同一天重复执行
+func (t *Task) runDaily(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	t.runLocked(ctx, name, name+":"+time.Now().Format("2006-01-02"), dailyTaskLockTTL, false, fn)
+}
+
+// runLocked 获取任务锁后执行任务，release 为 false 时锁到期后自动释放
+func (t *Task) runLocked(ctx context.Context, name string, key string, ttl time.Duration, release bool, fn func(ctx context.Context) error) {
+	lock := cache.NewCache(ctx, common.PREFFIX_TASK_LOCK)
+	token, ok, err := lock.Lock(key, ttl)
+	if err != nil {
+		t.log.Error("获取任务锁失败", zap.String("job", name), zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	if release {
+		defer func() {
+			if err := lock.Unlock(key, token); err != nil {
+				t.log.Error("释放任务锁失败", zap.String("job", name), zap.Error(err))
+			}
+		}()
+	}
+
+	t.log.Info("Task start...", zap.String("job", name))
+	if err := fn(ctx); err != nil {
+		t.log.Error("Task error", zap.String("job", name), zap.Error(err))
+		return
+	}
+	t.log.Info("Task done.", zap.String("job", name))
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	v1 "app/api/v1"
 	"app/internal/common"
@@ -30,6 +31,7 @@ type AccountService interface {
 	GetWeChatUserInfo(accessToken, openID string) (*model.WeChatUserInfo, error)
 	WeChatMiniLogin(ctx *gin.Context, req *model.WeChatLoginRequest) (string, string, error)
 	DecryptWeChatData(ctx *gin.Context, req *model.WeChatEncryptedDataRequest) (*model.WeChatDecryptResponse, error)
+	AssignRole(ctx *gin.Context, req model.AssignRoleRequest) error
 }
 
 func NewAccountService(
@@ -38,6 +40,7 @@ func NewAccountService(
 	userAssetRepository repository.UserAssetRepository,
 	userCouponRepository repository.UserCouponRepository,
 	settingsRepository repository.SettingsRepository,
+	memberTierRepository repository.MemberTierRepository,
 ) AccountService {
 	return &accountService{
 		Service:              service,
@@ -45,6 +48,7 @@ func NewAccountService(
 		userAssetRepository:  userAssetRepository,
 		userCouponRepository: userCouponRepository,
 		settingsRepository:   settingsRepository,
+		memberTierRepository: memberTierRepository,
 	}
 }
 
@@ -62,6 +66,7 @@ type accountService struct {
 	userAssetRepository  repository.UserAssetRepository
 	userCouponRepository repository.UserCouponRepository
 	settingsRepository   repository.SettingsRepository
+	memberTierRepository repository.MemberTierRepository
 }
 
 func (s *accountService) UpdateAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
@@ -169,6 +174,20 @@ func (s *accountService) GetProfile(ctx *gin.Context) (*v1.GetProfileResponseDat
 	}, nil
 }
 
+// AssignRole 后台手工指定用户身份，变更记录和缓存清理由仓储层处理
+func (s *accountService) AssignRole(ctx *gin.Context, req model.AssignRoleRequest) error {
+	operator := GetAdminOperatorFromCtx(ctx)
+	if err := s.memberTierRepository.AssignRole(ctx, req.UserID, req.Role, operator, req.Reason); err != nil {
+		return err
+	}
+	s.logger.Info("指定用户身份",
+		zap.String("operator", operator),
+		zap.String("user_id", req.UserID),
+		zap.Int("role", req.Role),
+	)
+	return nil
+}
+
 func (s *accountService) UpdateProfile(ctx *gin.Context, req *model.NicknameAvatar) error {
 	userId := GetUserIdFromCtx(ctx)
 	user, err := s.accountRepository.GetByID(ctx, userId)
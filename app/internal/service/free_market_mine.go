@@ -3,13 +3,18 @@ package service
 import (
 	"app/internal/model"
 	"app/internal/repository"
+	"app/pkg/money"
 
 	"github.com/gin-gonic/gin"
 )
 
 type FreeMarketMineService interface {
 	GetUserEggsSummary(ctx *gin.Context) (*model.FreeMarketMineResponse, error)
-	UpdateEggPrice(ctx *gin.Context, price float64, id int) error
+	UpdateEggPrice(ctx *gin.Context, price float64, id int) (*model.MarketOrderItem, error)
+	PublishOrder(ctx *gin.Context, req model.PublishMarketOrderRequest) (*model.MarketOrderItem, error)
+	CancelOrder(ctx *gin.Context, req model.CancelMarketOrderRequest) error
+	GetOrderBook(ctx *gin.Context, req model.MarketOrderBookRequest) (*model.MarketOrderBookResponse, error)
+	GetCandles(ctx *gin.Context, req model.MarketCandleRequest) (*model.MarketCandleResponse, error)
 }
 
 func NewFreeMarketMineService(
@@ -32,6 +37,31 @@ func (s *freeMarketMineService) GetUserEggsSummary(ctx *gin.Context) (*model.Fre
 	return s.freeMarketMineRepository.GetUserEggsSummary(ctx, userID)
 }
 
-func (s *freeMarketMineService) UpdateEggPrice(ctx *gin.Context, price float64, id int) error {
-	return s.freeMarketMineRepository.UpdateEggPrice(ctx, price, id)
+// UpdateEggPrice 修改自己出售挂单的价格，价格单位为元
+func (s *freeMarketMineService) UpdateEggPrice(ctx *gin.Context, price float64, id int) (*model.MarketOrderItem, error) {
+	userID := GetUserIdFromCtx(ctx)
+	return s.freeMarketMineRepository.UpdateEggPrice(ctx, userID, uint64(id), money.FromYuan(price))
+}
+
+// PublishOrder 发布出售或求购挂单
+func (s *freeMarketMineService) PublishOrder(ctx *gin.Context, req model.PublishMarketOrderRequest) (*model.MarketOrderItem, error) {
+	userID := GetUserIdFromCtx(ctx)
+	return s.freeMarketMineRepository.PublishOrder(ctx, userID, req)
+}
+
+// CancelOrder 撤销自己的挂单
+func (s *freeMarketMineService) CancelOrder(ctx *gin.Context, req model.CancelMarketOrderRequest) error {
+	userID := GetUserIdFromCtx(ctx)
+	return s.freeMarketMineRepository.CancelOrder(ctx, userID, req.ID)
+}
+
+// GetOrderBook 分页获取挂单簿
+func (s *freeMarketMineService) GetOrderBook(ctx *gin.Context, req model.MarketOrderBookRequest) (*model.MarketOrderBookResponse, error) {
+	userID := GetUserIdFromCtx(ctx)
+	return s.freeMarketMineRepository.GetOrderBook(ctx, userID, req)
+}
+
+// GetCandles 获取成交价K线
+func (s *freeMarketMineService) GetCandles(ctx *gin.Context, req model.MarketCandleRequest) (*model.MarketCandleResponse, error) {
+	return s.freeMarketMineRepository.GetCandles(ctx, req)
 }
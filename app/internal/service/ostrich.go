@@ -0,0 +1,86 @@
+package service
+
+import (
+	"app/internal/model"
+	"app/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type OstrichService interface {
+	GetUserOstriches(ctx *gin.Context, req model.OstrichListRequest) (*model.OstrichListResponse, error)
+	GetUserOstrichDetail(ctx *gin.Context, id uint64) (*model.OstrichDetailResponse, error)
+	AdvanceStages(ctx context.Context) error
+	UpdateInfo(ctx *gin.Context, req model.UpdateOstrichRequest) error
+	ChangeStatus(ctx *gin.Context, req model.ChangeOstrichStatusRequest) error
+}
+
+func NewOstrichService(
+	service *Service,
+	ostrichRepository repository.OstrichRepository,
+) OstrichService {
+	return &ostrichService{
+		Service:           service,
+		ostrichRepository: ostrichRepository,
+	}
+}
+
+type ostrichService struct {
+	*Service
+	ostrichRepository repository.OstrichRepository
+}
+
+// GetUserOstriches 获取我认养的鸵鸟
+func (s *ostrichService) GetUserOstriches(ctx *gin.Context, req model.OstrichListRequest) (*model.OstrichListResponse, error) {
+	userID := GetUserIdFromCtx(ctx)
+	return s.ostrichRepository.GetUserOstriches(ctx, userID, req)
+}
+
+// GetUserOstrichDetail 获取认养鸵鸟详情
+func (s *ostrichService) GetUserOstrichDetail(ctx *gin.Context, id uint64) (*model.OstrichDetailResponse, error) {
+	userID := GetUserIdFromCtx(ctx)
+	return s.ostrichRepository.GetUserOstrichDetail(ctx, userID, id)
+}
+
+// AdvanceStages 按日龄推进鸵鸟的生长阶段
+func (s *ostrichService) AdvanceStages(ctx context.Context) error {
+	advanced, err := s.ostrichRepository.AdvanceStages(ctx)
+	if err != nil {
+		return err
+	}
+	if advanced > 0 {
+		s.logger.Info("鸵鸟生长阶段更新完成", zap.Int("count", advanced))
+	}
+	return nil
+}
+
+// UpdateInfo 后台补录鸵鸟栏舍和出生日期
+func (s *ostrichService) UpdateInfo(ctx *gin.Context, req model.UpdateOstrichRequest) error {
+	var birthDate *time.Time
+	if req.BirthDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.BirthDate, time.Local)
+		if err != nil {
+			return errors.New("出生日期不合法")
+		}
+		birthDate = &date
+	}
+	if err := s.ostrichRepository.UpdateInfo(ctx, req.IDs, req.Pen, birthDate); err != nil {
+		return err
+	}
+	s.logger.Info("补录鸵鸟信息",
+		zap.String("operator", GetAdminOperatorFromCtx(ctx)),
+		zap.Uint64s("ids", req.IDs),
+		zap.Stringp("pen", req.Pen),
+		zap.String("birth_date", req.BirthDate),
+	)
+	return nil
+}
+
+// ChangeStatus 后台变更鸵鸟状态，变更人记录在状态日志中
+func (s *ostrichService) ChangeStatus(ctx *gin.Context, req model.ChangeOstrichStatusRequest) error {
+	return s.ostrichRepository.ChangeStatus(ctx, req.ID, req.Status, GetAdminOperatorFromCtx(ctx), req.Remark)
+}
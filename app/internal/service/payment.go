@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	v1 "app/api/v1"
+	"app/internal/common"
+	"app/internal/model"
+	"app/internal/repository"
+	"app/pkg/money"
+	"app/pkg/payment"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type PaymentService interface {
+	Prepay(ctx *gin.Context, req model.PrepayRequest) (*payment.PrepayResponse, error)
+	HandleNotify(ctx context.Context, header http.Header, body []byte) error
+}
+
+func NewPaymentService(
+	service *Service,
+	provider payment.Provider,
+	paymentRepository repository.PaymentRepository,
+	userOrderRepository repository.UserOrderRepository,
+	refundOrderRepository repository.RefundOrderRepository,
+	accountRepository repository.AccountRepository,
+	rechargeOrderRepository repository.RechargeOrderRepository,
+) PaymentService {
+	return &paymentService{
+		Service:                 service,
+		provider:                provider,
+		paymentRepository:       paymentRepository,
+		userOrderRepository:     userOrderRepository,
+		refundOrderRepository:   refundOrderRepository,
+		accountRepository:       accountRepository,
+		rechargeOrderRepository: rechargeOrderRepository,
+	}
+}
+
+type paymentService struct {
+	*Service
+	provider                payment.Provider
+	paymentRepository       repository.PaymentRepository
+	userOrderRepository     repository.UserOrderRepository
+	refundOrderRepository   repository.RefundOrderRepository
+	accountRepository       repository.AccountRepository
+	rechargeOrderRepository repository.RechargeOrderRepository
+}
+
+// Prepay 为待付款订单发起支付，以订单号作为商户订单号
+func (s *paymentService) Prepay(ctx *gin.Context, req model.PrepayRequest) (*payment.PrepayResponse, error) {
+	userID := GetUserIdFromCtx(ctx)
+	order, err := s.userOrderRepository.GetPayableOrder(ctx, userID, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := s.paymentRepository.GetTransactionByOutTradeNo(ctx, order.OrderNo)
+	if err != nil {
+		return nil, err
+	}
+	if transaction != nil && transaction.Status == common.PAYMENT_STATUS_PAID {
+		return nil, v1.ErrOrderAlreadyPaid
+	}
+
+	// 支付截止时间与订单超时关闭时间一致
+	var createdAt time.Time
+	var amount money.Money
+	description := ""
+	for _, item := range order.OrderItems {
+		amount += item.TotalFee
+		if createdAt.IsZero() || item.CreatedAt.Before(createdAt) {
+			createdAt = item.CreatedAt
+		}
+		if description == "" {
+			description = item.ProductName
+		}
+	}
+	expireAt := createdAt.Add(s.userOrderRepository.GetPayTimeout(ctx))
+	if !expireAt.After(time.Now()) {
+		return nil, v1.ErrOrderNotPayable
+	}
+	if len(order.OrderItems) > 1 {
+		description += "等商品"
+	}
+
+	account, err := s.accountRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prepayReq := &payment.PrepayRequest{
+		OutTradeNo:  order.OrderNo,
+		Description: description,
+		Amount:      amount.Fen(),
+		OpenID:      account.OpenID,
+		ExpireAt:    expireAt,
+	}
+	resp, err := s.provider.Prepay(ctx, prepayReq)
+	if err != nil {
+		s.logger.Error("发起支付失败", zap.String("out_trade_no", order.OrderNo), zap.Error(err))
+		return nil, err
+	}
+	if err := s.paymentRepository.SaveTransaction(ctx, &model.PaymentTransaction{
+		OutTradeNo:   order.OrderNo,
+		BusinessType: common.BUSINESS_TYPE_ORDER,
+		BusinessID:   order.ID,
+		UserID:       userID,
+		Provider:     s.provider.Name(),
+		Amount:       prepayReq.Amount,
+		Status:       common.PAYMENT_STATUS_PENDING,
+		PrepayID:     resp.PrepayID,
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// HandleNotify 处理支付渠道回调，验签通过后才变更订单状态
+func (s *paymentService) HandleNotify(ctx context.Context, header http.Header, body []byte) error {
+	notify, err := s.provider.VerifyNotify(ctx, header, body)
+	if err != nil {
+		s.logger.Error("支付回调验签失败", zap.Error(err))
+		return err
+	}
+	switch {
+	case notify.EventType == payment.EventTransactionSuccess && notify.Transaction != nil:
+		return s.handlePaid(ctx, notify.Transaction)
+	case notify.EventType == payment.EventRefundSuccess && notify.Refund != nil:
+		// 订单失效后原路退回的支付，没有对应的退款单
+		if ok, err := s.paymentRepository.MarkRefunded(ctx, notify.Refund.OutRefundNo); err != nil || ok {
+			return err
+		}
+		refundID, err := s.paymentRepository.GetRefundIDByRefundNo(ctx, notify.Refund.OutRefundNo)
+		if err != nil {
+			return err
+		}
+		return s.refundOrderRepository.CompleteRefund(ctx, refundID, common.ORDER_ACTOR_SYSTEM, "")
+	default:
+		s.logger.Info("忽略支付回调", zap.String("event_type", notify.EventType))
+		return nil
+	}
+}
+
+// handlePaid 处理支付成功，重复回调只处理一次
+func (s *paymentService) handlePaid(ctx context.Context, trade *payment.Transaction) error {
+	if trade.TradeState != payment.TradeStateSuccess {
+		return nil
+	}
+	transaction, err := s.paymentRepository.GetTransactionByOutTradeNo(ctx, trade.OutTradeNo)
+	if err != nil {
+		return err
+	}
+	if transaction == nil {
+		s.logger.Error("支付流水不存在", zap.String("out_trade_no", trade.OutTradeNo))
+		return errors.New("支付流水不存在")
+	}
+	if transaction.Amount != trade.Amount {
+		s.logger.Error("支付金额不一致",
+			zap.String("out_trade_no", trade.OutTradeNo),
+			zap.Int64("expected", transaction.Amount),
+			zap.Int64("actual", trade.Amount),
+		)
+		return errors.New("支付金额不一致")
+	}
+	// 之前原路退回失败时，渠道重复回调会再次发起退款
+	if transaction.Status == common.PAYMENT_STATUS_REFUNDING {
+		return s.refundOrphan(ctx, transaction)
+	}
+	paidAt := trade.PaidAt
+	if paidAt.IsZero() {
+		paidAt = time.Now()
+	}
+
+	orphan := false
+	err = s.tm.Transaction(ctx, func(ctx context.Context) error {
+		ok, err := s.paymentRepository.MarkPaid(ctx, trade.OutTradeNo, trade.TransactionID, paidAt)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if transaction.BusinessType == common.BUSINESS_TYPE_RECHARGE {
+			return s.rechargeOrderRepository.CompleteRecharge(ctx, transaction.BusinessID, paidAt)
+		}
+		err = s.userOrderRepository.PayOrder(ctx, transaction.BusinessID, common.PAYMENT_METHOD_WECHAT, "微信支付")
+		if errors.Is(err, v1.ErrOrderNotPayable) {
+			// 订单已超时关闭但用户完成了支付，标记为待退回，事务提交后原路退款
+			s.logger.Error("订单已失效但收到支付",
+				zap.String("out_trade_no", trade.OutTradeNo),
+				zap.Int64("amount", trade.Amount),
+			)
+			transaction.RefundNo = "RF" + trade.OutTradeNo
+			orphan = true
+			return s.paymentRepository.MarkRefunding(ctx, trade.OutTradeNo, transaction.RefundNo)
+		}
+		return err
+	})
+	if err != nil || !orphan {
+		return err
+	}
+	return s.refundOrphan(ctx, transaction)
+}
+
+// refundOrphan 将失效订单收到的支付全额原路退回；退款失败时返回错误，由渠道重复回调重试，流水保持待退回状态
+func (s *paymentService) refundOrphan(ctx context.Context, transaction *model.PaymentTransaction) error {
+	result, err := s.provider.Refund(ctx, &payment.RefundRequest{
+		OutTradeNo:  transaction.OutTradeNo,
+		OutRefundNo: transaction.RefundNo,
+		Reason:      "订单已失效，支付原路退回",
+		Refund:      transaction.Amount,
+		Total:       transaction.Amount,
+	})
+	if err != nil {
+		s.logger.Error("失效订单支付退回失败", zap.String("out_trade_no", transaction.OutTradeNo), zap.Error(err))
+		return err
+	}
+	if result.Status == payment.RefundStatusSuccess {
+		_, err = s.paymentRepository.MarkRefunded(ctx, transaction.RefundNo)
+		return err
+	}
+	return nil
+}
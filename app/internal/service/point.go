@@ -0,0 +1,67 @@
+package service
+
+import (
+	"app/internal/model"
+	"app/internal/repository"
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type PointService interface {
+	CheckIn(ctx *gin.Context) (*model.CheckInResponse, error)
+	GetPointLots(ctx *gin.Context) (*model.PointLotListResponse, error)
+	ExpirePoints(ctx context.Context) error
+	RemindExpiringPoints(ctx context.Context) ([]model.PointExpiringNotice, error)
+	MarkPointsReminded(ctx context.Context, lotIDs []uint64) error
+}
+
+func NewPointService(
+	service *Service,
+	pointRepository repository.PointRepository,
+) PointService {
+	return &pointService{
+		Service:         service,
+		pointRepository: pointRepository,
+	}
+}
+
+type pointService struct {
+	*Service
+	pointRepository repository.PointRepository
+}
+
+// CheckIn 每日签到领取积分
+func (s *pointService) CheckIn(ctx *gin.Context) (*model.CheckInResponse, error) {
+	userID := GetUserIdFromCtx(ctx)
+	return s.pointRepository.CheckIn(ctx, userID)
+}
+
+// GetPointLots 获取积分批次及即将过期的积分
+func (s *pointService) GetPointLots(ctx *gin.Context) (*model.PointLotListResponse, error) {
+	userID := GetUserIdFromCtx(ctx)
+	return s.pointRepository.GetPointLots(ctx, userID)
+}
+
+// ExpirePoints 扣减已过期积分批次的剩余积分
+func (s *pointService) ExpirePoints(ctx context.Context) error {
+	expired, err := s.pointRepository.ExpirePoints(ctx)
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		s.logger.Info("积分过期处理完成", zap.Int("count", expired))
+	}
+	return nil
+}
+
+// RemindExpiringPoints 汇总即将过期的积分，返回需要推送的提醒
+func (s *pointService) RemindExpiringPoints(ctx context.Context) ([]model.PointExpiringNotice, error) {
+	return s.pointRepository.RemindExpiringPoints(ctx)
+}
+
+// MarkPointsReminded 标记已推送提醒的积分批次
+func (s *pointService) MarkPointsReminded(ctx context.Context, lotIDs []uint64) error {
+	return s.pointRepository.MarkPointsReminded(ctx, lotIDs)
+}
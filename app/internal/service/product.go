@@ -9,6 +9,7 @@ import (
 	"app/internal/repository"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type ProductService interface {
@@ -16,6 +17,7 @@ type ProductService interface {
 	GetRecommendProductList(ctx context.Context) ([]*model.ProductListItemDTO, error)
 	GetProductByID(ctx *gin.Context, id int) (*model.ProductListItemDTO, error)
 	GetProductDetailsByCartIDs(ctx *gin.Context, cart_ids string) (*model.ProductDetailsResponse, error)
+	UpdateStock(ctx *gin.Context, req model.UpdateProductStockRequest) error
 }
 
 func NewProductService(
@@ -67,3 +69,16 @@ func (s *productService) GetProductDetailsByCartIDs(ctx *gin.Context, cart_ids s
 		Products: products,
 	}, nil
 }
+
+// UpdateStock 运营设置商品库存
+func (s *productService) UpdateStock(ctx *gin.Context, req model.UpdateProductStockRequest) error {
+	if err := s.productRepository.UpdateStock(ctx, req.ProductID, *req.Stock); err != nil {
+		return err
+	}
+	s.logger.Info("设置商品库存",
+		zap.String("operator", GetAdminOperatorFromCtx(ctx)),
+		zap.Uint64("product_id", req.ProductID),
+		zap.Int("stock", *req.Stock),
+	)
+	return nil
+}
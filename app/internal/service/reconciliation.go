@@ -0,0 +1,106 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"app/internal/common"
+	"app/internal/model"
+	"app/internal/repository"
+)
+
+type ReconciliationService interface {
+	ReconcileAssets(ctx context.Context) (*model.ReconciliationRun, error)
+	GetRuns(ctx context.Context, req model.ReconciliationRunListRequest) (*model.ReconciliationRunListResponse, error)
+	GetDiscrepancies(ctx context.Context, runID uint64) ([]model.ReconciliationDiscrepancy, error)
+}
+
+func NewReconciliationService(
+	service *Service,
+	reconciliationRepository repository.ReconciliationRepository,
+	settingsRepository repository.SettingsRepository,
+) ReconciliationService {
+	return &reconciliationService{
+		Service:                  service,
+		reconciliationRepository: reconciliationRepository,
+		settingsRepository:       settingsRepository,
+	}
+}
+
+type reconciliationService struct {
+	*Service
+	reconciliationRepository repository.ReconciliationRepository
+	settingsRepository       repository.SettingsRepository
+}
+
+// ReconcileAssets 执行资产对账，发现差异或对账失败时告警
+func (s *reconciliationService) ReconcileAssets(ctx context.Context) (*model.ReconciliationRun, error) {
+	autoCorrect, _ := s.settingsRepository.GetSettings(ctx, common.SETTINGS_RECONCILE_AUTO_CORRECT)
+	run, err := s.reconciliationRepository.Reconcile(ctx, autoCorrect == "1")
+	if run == nil {
+		return nil, err
+	}
+	if err != nil {
+		s.alert(ctx, fmt.Sprintf("资产对账失败，任务ID：%d，原因：%s", run.ID, run.Error))
+		return run, err
+	}
+	if run.DiscrepancyCount > 0 {
+		s.logger.Error("资产对账发现差异",
+			zap.Uint64("run_id", run.ID),
+			zap.Int("discrepancy_count", run.DiscrepancyCount),
+			zap.Int("corrected_count", run.CorrectedCount),
+		)
+		s.alert(ctx, fmt.Sprintf("资产对账发现 %d 条差异，已自动调整 %d 条，任务ID：%d，请在管理后台查看",
+			run.DiscrepancyCount, run.CorrectedCount, run.ID))
+	}
+	return run, nil
+}
+
+// GetRuns 获取对账任务列表
+func (s *reconciliationService) GetRuns(ctx context.Context, req model.ReconciliationRunListRequest) (*model.ReconciliationRunListResponse, error) {
+	return s.reconciliationRepository.GetRuns(ctx, req)
+}
+
+// GetDiscrepancies 获取对账任务发现的差异
+func (s *reconciliationService) GetDiscrepancies(ctx context.Context, runID uint64) ([]model.ReconciliationDiscrepancy, error) {
+	return s.reconciliationRepository.GetDiscrepancies(ctx, runID)
+}
+
+// alert 通过 sys_params 中配置的机器人 Webhook 发送告警，未配置时只记录日志
+func (s *reconciliationService) alert(ctx context.Context, content string) {
+	webhook, _ := s.settingsRepository.GetSettings(ctx, common.SETTINGS_RECONCILE_ALERT_WEBHOOK)
+	if webhook == "" {
+		s.logger.Warn("未配置对账告警地址", zap.String("content", content))
+		return
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": content},
+	})
+	if err != nil {
+		s.logger.Error("对账告警序列化失败", zap.Error(err))
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(payload))
+	if err != nil {
+		s.logger.Error("创建对账告警请求失败", zap.Error(err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		s.logger.Error("发送对账告警失败", zap.Error(err))
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		s.logger.Error("发送对账告警失败", zap.Int("status", resp.StatusCode))
+	}
+}
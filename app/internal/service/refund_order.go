@@ -1,10 +1,15 @@
 package service
 
 import (
+	"errors"
+
+	"app/internal/common"
 	"app/internal/model"
 	"app/internal/repository"
+	"app/pkg/payment"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type RefundOrderService interface {
@@ -13,21 +18,29 @@ type RefundOrderService interface {
 	GetRefundDetail(ctx *gin.Context, refundID uint64) (*model.RefundDetailResponse, error)
 	CancelRefund(ctx *gin.Context, refundID uint64) error
 	DeleteRefund(ctx *gin.Context, refundID uint64) error
+	ApproveRefund(ctx *gin.Context, req model.ApproveRefundRequest) error
+	RejectRefund(ctx *gin.Context, req model.RejectRefundRequest) error
 }
 
 func NewRefundOrderService(
 	service *Service,
 	refundOrderRepository repository.RefundOrderRepository,
+	paymentRepository repository.PaymentRepository,
+	provider payment.Provider,
 ) RefundOrderService {
 	return &refundOrderService{
 		Service:               service,
 		refundOrderRepository: refundOrderRepository,
+		paymentRepository:     paymentRepository,
+		provider:              provider,
 	}
 }
 
 type refundOrderService struct {
 	*Service
 	refundOrderRepository repository.RefundOrderRepository
+	paymentRepository     repository.PaymentRepository
+	provider              payment.Provider
 }
 
 // CreateRefund 创建退款单
@@ -77,3 +90,51 @@ func (s *refundOrderService) DeleteRefund(ctx *gin.Context, refundID uint64) err
 	// 调用仓储层删除退款记录
 	return s.refundOrderRepository.DeleteRefund(ctx, userID, refundID)
 }
+
+// ApproveRefund 后台审核通过退款：余额支付的退回余额并完成退款，微信支付的原路退回，渠道受理后由退款回调完成
+func (s *refundOrderService) ApproveRefund(ctx *gin.Context, req model.ApproveRefundRequest) error {
+	operator := GetAdminOperatorFromCtx(ctx)
+	refundOrder, order, err := s.refundOrderRepository.GetPendingRefund(ctx, req.RefundID)
+	if err != nil {
+		return err
+	}
+
+	switch order.PaymentMethod {
+	case common.PAYMENT_METHOD_BALANCE:
+		return s.refundOrderRepository.ApproveBalanceRefund(ctx, refundOrder.ID, operator)
+	case common.PAYMENT_METHOD_WECHAT:
+		transaction, err := s.paymentRepository.GetTransactionByOutTradeNo(ctx, order.OrderNo)
+		if err != nil {
+			return err
+		}
+		if transaction == nil {
+			return errors.New("支付流水不存在")
+		}
+		if err := s.refundOrderRepository.MarkRefundProcessing(ctx, refundOrder.ID); err != nil {
+			return err
+		}
+		// 以退款单号作为商户退款单号，重复审核时渠道按单号去重
+		result, err := s.provider.Refund(ctx, &payment.RefundRequest{
+			OutTradeNo:  transaction.OutTradeNo,
+			OutRefundNo: refundOrder.RefundNo,
+			Reason:      refundOrder.RefundReason,
+			Refund:      refundOrder.RefundAmount.Fen(),
+			Total:       transaction.Amount,
+		})
+		if err != nil {
+			s.logger.Error("发起微信退款失败", zap.String("refund_no", refundOrder.RefundNo), zap.Error(err))
+			return err
+		}
+		if result.Status == payment.RefundStatusSuccess {
+			return s.refundOrderRepository.CompleteRefund(ctx, refundOrder.ID, common.ORDER_ACTOR_ADMIN, operator)
+		}
+		return nil
+	default:
+		return errors.New("订单支付方式不支持退款")
+	}
+}
+
+// RejectRefund 后台审核拒绝退款
+func (s *refundOrderService) RejectRefund(ctx *gin.Context, req model.RejectRefundRequest) error {
+	return s.refundOrderRepository.RejectRefund(ctx, req.RefundID, GetAdminOperatorFromCtx(ctx), req.Reason)
+}
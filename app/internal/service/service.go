@@ -47,6 +47,11 @@ func GetUserIdFromCtx(ctx *gin.Context) string {
 	return v.(*jwt.MyCustomClaims).UserId
 }
 
+// GetAdminOperatorFromCtx 获取管理接口的操作人
+func GetAdminOperatorFromCtx(ctx *gin.Context) string {
+	return ctx.GetString("admin_operator")
+}
+
 func GetclaimsFromCtx(ctx *gin.Context) *jwt.MyCustomClaims {
 	v, exists := ctx.Get("claims")
 	if !exists {
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"app/internal/model"
+	"app/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StoreService interface {
+	GetStorePage(ctx *gin.Context, storeID uint64, req model.StorePageRequest) (*model.StorePageResponse, error)
+}
+
+func NewStoreService(
+	service *Service,
+	storeRepository repository.StoreRepository,
+) StoreService {
+	return &storeService{
+		Service:         service,
+		storeRepository: storeRepository,
+	}
+}
+
+type storeService struct {
+	*Service
+	storeRepository repository.StoreRepository
+}
+
+// GetStorePage 获取店铺主页，包含店铺信息、评分和商品列表
+func (s *storeService) GetStorePage(ctx *gin.Context, storeID uint64, req model.StorePageRequest) (*model.StorePageResponse, error) {
+	// 设置默认值
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+
+	store, err := s.storeRepository.GetStore(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	rating, err := s.storeRepository.GetStoreRating(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	products, total, err := s.storeRepository.GetStoreProducts(ctx, storeID, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StorePageResponse{
+		StoreID:     store.ID,
+		StoreName:   store.Name,
+		StoreLogo:   store.Logo,
+		Description: store.Description,
+		Rating:      *rating,
+		Total:       total,
+		Products:    products,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+	}, nil
+}
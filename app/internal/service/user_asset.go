@@ -1,15 +1,22 @@
 package service
 
 import (
+	"time"
+
+	"app/internal/common"
 	"app/internal/model"
 	"app/internal/repository"
+	"app/pkg/payment"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type UserAssetService interface {
 	GetUserAsset(ctx *gin.Context) (*model.UserAssetResponse, error)
-	RechargeBalance(ctx *gin.Context, req model.RechargeRequest) error
+	RechargeBalance(ctx *gin.Context, req model.RechargeRequest) (*model.RechargeResponse, error)
+	GetRechargeBonusTiers(ctx *gin.Context) ([]model.RechargeBonusTier, error)
+	GetRechargeList(ctx *gin.Context, req model.RechargeQueryRequest) (*model.RechargeListResponse, error)
 	WithdrawBalance(ctx *gin.Context, req model.WithdrawRequest) error
 	GetBalanceRecords(ctx *gin.Context, req model.BalanceRecordQueryRequest) (*model.BalanceRecordResponse, error)
 	GetWithdrawRecords(ctx *gin.Context, req model.BalanceRecordQueryRequest) (*model.BalanceRecordResponse, error)
@@ -22,6 +29,9 @@ func NewUserAssetService(
 	userCouponRepository repository.UserCouponRepository,
 	userAssetRecordRepository repository.UserAssetRecordRepository,
 	userRepository repository.AccountRepository,
+	rechargeOrderRepository repository.RechargeOrderRepository,
+	paymentRepository repository.PaymentRepository,
+	provider payment.Provider,
 ) UserAssetService {
 	return &userAssetService{
 		Service:                   service,
@@ -29,6 +39,9 @@ func NewUserAssetService(
 		userCouponRepository:      userCouponRepository,
 		userAssetRecordRepository: userAssetRecordRepository,
 		userRepository:            userRepository,
+		rechargeOrderRepository:   rechargeOrderRepository,
+		paymentRepository:         paymentRepository,
+		provider:                  provider,
 	}
 }
 
@@ -38,6 +51,9 @@ type userAssetService struct {
 	userCouponRepository      repository.UserCouponRepository
 	userAssetRecordRepository repository.UserAssetRecordRepository
 	userRepository            repository.AccountRepository
+	rechargeOrderRepository   repository.RechargeOrderRepository
+	paymentRepository         repository.PaymentRepository
+	provider                  payment.Provider
 }
 
 func (s *userAssetService) GetUserAsset(ctx *gin.Context) (*model.UserAssetResponse, error) {
@@ -58,18 +74,72 @@ func (s *userAssetService) GetUserAsset(ctx *gin.Context) (*model.UserAssetRespo
 	}
 
 	return &model.UserAssetResponse{
-		UserID:      userAsset.UserID,
-		Points:      userAsset.Points,
-		CouponCount: couponCount,
-		Balance:     userAsset.Balance,
-		Nickname:    user.Nickname,
-		Avatar:      user.Avatar,
+		UserID:           userAsset.UserID,
+		Points:           userAsset.Points,
+		CouponCount:      couponCount,
+		Balance:          userAsset.Balance,
+		AvailableBalance: userAsset.Balance,
+		FrozenBalance:    userAsset.FrozenBalance,
+		Consumption:      userAsset.Consumption,
+		Eggs:             userAsset.Eggs,
+		FrozenEggs:       userAsset.FrozenEggs,
+		Nickname:         user.Nickname,
+		Avatar:           user.Avatar,
 	}, nil
 }
 
-func (s *userAssetService) RechargeBalance(ctx *gin.Context, req model.RechargeRequest) error {
+// RechargeBalance 创建充值单并发起支付，支付回调验签通过后才入账
+func (s *userAssetService) RechargeBalance(ctx *gin.Context, req model.RechargeRequest) (*model.RechargeResponse, error) {
+	userID := GetUserIdFromCtx(ctx)
+	account, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	rechargeOrder, err := s.rechargeOrderRepository.CreateRecharge(ctx, userID, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	prepayReq := &payment.PrepayRequest{
+		OutTradeNo:  rechargeOrder.RechargeNo,
+		Description: "余额充值",
+		Amount:      rechargeOrder.Amount.Fen(),
+		OpenID:      account.OpenID,
+		ExpireAt:    rechargeOrder.CreatedAt.Add(common.RECHARGE_PAY_TIMEOUT * time.Minute),
+	}
+	resp, err := s.provider.Prepay(ctx, prepayReq)
+	if err != nil {
+		s.logger.Error("发起充值支付失败", zap.String("out_trade_no", rechargeOrder.RechargeNo), zap.Error(err))
+		return nil, err
+	}
+	if err := s.paymentRepository.SaveTransaction(ctx, &model.PaymentTransaction{
+		OutTradeNo:   rechargeOrder.RechargeNo,
+		BusinessType: common.BUSINESS_TYPE_RECHARGE,
+		BusinessID:   rechargeOrder.ID,
+		UserID:       userID,
+		Provider:     s.provider.Name(),
+		Amount:       prepayReq.Amount,
+		Status:       common.PAYMENT_STATUS_PENDING,
+		PrepayID:     resp.PrepayID,
+	}); err != nil {
+		return nil, err
+	}
+	return &model.RechargeResponse{
+		RechargeNo:  rechargeOrder.RechargeNo,
+		Amount:      rechargeOrder.Amount,
+		BonusAmount: rechargeOrder.BonusAmount,
+		Payment:     resp,
+	}, nil
+}
+
+// GetRechargeBonusTiers 获取充值赠送档位
+func (s *userAssetService) GetRechargeBonusTiers(ctx *gin.Context) ([]model.RechargeBonusTier, error) {
+	return s.rechargeOrderRepository.GetBonusTiers(ctx)
+}
+
+// GetRechargeList 获取充值记录
+func (s *userAssetService) GetRechargeList(ctx *gin.Context, req model.RechargeQueryRequest) (*model.RechargeListResponse, error) {
 	userID := GetUserIdFromCtx(ctx)
-	return s.userAssetRepository.RechargeBalance(ctx, userID, req.Amount)
+	return s.rechargeOrderRepository.GetRechargeList(ctx, userID, req.Status, req.Page, req.PageSize)
 }
 
 func (s *userAssetService) WithdrawBalance(ctx *gin.Context, req model.WithdrawRequest) error {
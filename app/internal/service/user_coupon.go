@@ -3,31 +3,44 @@ package service
 import (
 	"app/internal/model"
 	"app/internal/repository"
+	"context"
+	"errors"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type UserCouponService interface {
 	GetUserCoupons(ctx *gin.Context, productID uint64) ([]model.UserCoupon, error)
-	GetAllUserCoupons(ctx *gin.Context) (map[uint64][]model.ProductCouponDTO, error)
+	GetAllUserCoupons(ctx *gin.Context, req model.UserCouponQueryRequest) (map[uint64][]model.ProductCouponDTO, error)
 	ClaimCoupon(ctx *gin.Context, req model.ClaimCouponRequest) error
 	GetUserCouponCount(ctx *gin.Context) (int, error)
 	GetUserCouponByID(ctx *gin.Context, couponID uint64) (*model.CouponDetailResponse, error)
+	ExpireCoupons(ctx context.Context) error
+	RemindExpiringCoupons(ctx context.Context) ([]model.CouponExpiringNotice, error)
+	MarkCouponsReminded(ctx context.Context, ids []uint64) error
+	RecommendCoupons(ctx *gin.Context, req model.CouponRecommendRequest) (*model.CouponRecommendResponse, error)
 }
 
 func NewUserCouponService(
 	service *Service,
 	userCouponRepository repository.UserCouponRepository,
+	userCartRepository repository.UserCartRepository,
+	orderPricingRepository repository.OrderPricingRepository,
 ) UserCouponService {
 	return &userCouponService{
-		Service:              service,
-		userCouponRepository: userCouponRepository,
+		Service:                service,
+		userCouponRepository:   userCouponRepository,
+		userCartRepository:     userCartRepository,
+		orderPricingRepository: orderPricingRepository,
 	}
 }
 
 type userCouponService struct {
 	*Service
-	userCouponRepository repository.UserCouponRepository
+	userCouponRepository   repository.UserCouponRepository
+	userCartRepository     repository.UserCartRepository
+	orderPricingRepository repository.OrderPricingRepository
 }
 
 func (s *userCouponService) GetUserCoupons(ctx *gin.Context, productID uint64) ([]model.UserCoupon, error) {
@@ -35,9 +48,9 @@ func (s *userCouponService) GetUserCoupons(ctx *gin.Context, productID uint64) (
 	return s.userCouponRepository.GetUserCoupons(ctx, userID, productID)
 }
 
-func (s *userCouponService) GetAllUserCoupons(ctx *gin.Context) (map[uint64][]model.ProductCouponDTO, error) {
+func (s *userCouponService) GetAllUserCoupons(ctx *gin.Context, req model.UserCouponQueryRequest) (map[uint64][]model.ProductCouponDTO, error) {
 	userID := GetUserIdFromCtx(ctx)
-	return s.userCouponRepository.GetAllUserCoupons(ctx, userID)
+	return s.userCouponRepository.GetAllUserCoupons(ctx, userID, req.Status)
 }
 
 func (s *userCouponService) ClaimCoupon(ctx *gin.Context, req model.ClaimCouponRequest) error {
@@ -54,3 +67,60 @@ func (s *userCouponService) GetUserCouponByID(ctx *gin.Context, couponID uint64)
 	userID := GetUserIdFromCtx(ctx)
 	return s.userCouponRepository.GetUserCouponByID(ctx, userID, couponID)
 }
+
+// ExpireCoupons 将已过截止时间的未使用优惠券标记为已过期
+func (s *userCouponService) ExpireCoupons(ctx context.Context) error {
+	expired, err := s.userCouponRepository.ExpireCoupons(ctx)
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		s.logger.Info("优惠券过期处理完成", zap.Int64("count", expired))
+	}
+	return nil
+}
+
+// RemindExpiringCoupons 汇总即将过期的优惠券，返回需要推送的提醒
+func (s *userCouponService) RemindExpiringCoupons(ctx context.Context) ([]model.CouponExpiringNotice, error) {
+	return s.userCouponRepository.RemindExpiringCoupons(ctx)
+}
+
+// MarkCouponsReminded 标记已推送提醒的优惠券
+func (s *userCouponService) MarkCouponsReminded(ctx context.Context, ids []uint64) error {
+	return s.userCouponRepository.MarkCouponsReminded(ctx, ids)
+}
+
+// RecommendCoupons 按购物车商品的服务端报价计算总优惠最大的优惠券组合
+func (s *userCouponService) RecommendCoupons(ctx *gin.Context, req model.CouponRecommendRequest) (*model.CouponRecommendResponse, error) {
+	userID := GetUserIdFromCtx(ctx)
+	carts, err := s.userCartRepository.GetCartsByIDs(ctx, userID, req.CartIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(carts) != len(req.CartIDs) {
+		return nil, errors.New("购物车商品不存在")
+	}
+
+	items := make([]model.OrderItemRequest, 0, len(carts))
+	for _, cart := range carts {
+		items = append(items, model.OrderItemRequest{
+			CartID:    cart.ID,
+			ProductID: cart.ProductID,
+			Quantity:  cart.Quantity,
+		})
+	}
+	region := model.FreightRegion{Province: req.Province, City: req.City, District: req.District}
+	quote, err := s.orderPricingRepository.QuoteOrder(ctx, userID, items, region)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.userCouponRepository.RecommendCoupons(ctx, userID, quote.Items)
+	if err != nil {
+		return nil, err
+	}
+	for i := range response.Allocations {
+		response.Allocations[i].CartID = uint64(carts[i].ID)
+	}
+	return response, nil
+}
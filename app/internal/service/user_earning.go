@@ -3,14 +3,19 @@ package service
 import (
 	"app/internal/model"
 	"app/internal/repository"
-	"errors"
+	"context"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
+// earningLookbackDays 每次生成收益时回看的天数，农场补录的产蛋记录在之后几天内仍会生成收益
+const earningLookbackDays = 3
+
 type UserEarningService interface {
-	AddEarning(ctx *gin.Context, req model.AddEarningRequest) error
 	GetEarningList(ctx *gin.Context, req model.QueryEarningRequest) (*model.EarningListResponse, error)
+	GenerateDailyEarnings(ctx context.Context) error
 }
 
 type userEarningService struct {
@@ -28,19 +33,6 @@ func NewUserEarningService(
 	}
 }
 
-// AddEarning 添加用户收益
-func (s *userEarningService) AddEarning(ctx *gin.Context, req model.AddEarningRequest) error {
-	userID := GetUserIdFromCtx(ctx)
-
-	// 验证收益类型
-	if req.EarningType < 1 || req.EarningType > 3 {
-		return errors.New("无效的收益类型")
-	}
-
-	// 调用仓储层添加收益
-	return s.userEarningRepository.AddEarning(ctx, userID, req)
-}
-
 // GetEarningList 获取用户收益列表
 func (s *userEarningService) GetEarningList(ctx *gin.Context, req model.QueryEarningRequest) (*model.EarningListResponse, error) {
 	userID := GetUserIdFromCtx(ctx)
@@ -48,3 +40,20 @@ func (s *userEarningService) GetEarningList(ctx *gin.Context, req model.QueryEar
 	// 调用仓储层获取收益列表
 	return s.userEarningRepository.GetEarningList(ctx, userID, req)
 }
+
+// GenerateDailyEarnings 按农场产蛋记录生成最近几天的认养收益，不含当天
+func (s *userEarningService) GenerateDailyEarnings(ctx context.Context) error {
+	now := time.Now()
+	generated := 0
+	for i := earningLookbackDays; i >= 1; i-- {
+		count, err := s.userEarningRepository.GenerateDailyEarnings(ctx, now.AddDate(0, 0, -i))
+		if err != nil {
+			return err
+		}
+		generated += count
+	}
+	if generated > 0 {
+		s.logger.Info("生成认养收益完成", zap.Int("count", generated))
+	}
+	return nil
+}
@@ -1,35 +1,51 @@
 package service
 
 import (
+	v1 "app/api/v1"
 	"app/internal/model"
 	"app/internal/repository"
 
+	"context"
 	"errors"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// 每次处理的超时订单数量
+const _expireOrderBatchSize = 200
+
 type UserOrderService interface {
 	CreateOrders(ctx *gin.Context, req model.CreateOrderRequest) error
 	GetOrderList(ctx *gin.Context, req model.OrderQueryRequest) (*model.OrderListResponse, error)
 	GetOrderProductDetails(ctx *gin.Context, req model.OrderProductsRequest) (*model.OrderProductsResponse, error)
 	UpdateOrderStatus(ctx *gin.Context, req model.UpdateOrderStatusRequest) error
 	GetOrderDetail(ctx *gin.Context, orderItemID uint64) (*model.OrderDetailResponse, error)
+	ExpireUnpaidOrders(ctx context.Context) ([]model.UserOrderItem, error)
+	QuoteFreight(ctx *gin.Context, req model.FreightQuoteRequest) (*model.FreightQuote, error)
 }
 
 func NewUserOrderService(
 	service *Service,
 	userOrderRepository repository.UserOrderRepository,
+	userAddressRepository repository.UserAddressRepository,
+	freightRepository repository.FreightRepository,
 ) UserOrderService {
 	return &userOrderService{
-		Service:             service,
-		userOrderRepository: userOrderRepository,
+		Service:               service,
+		userOrderRepository:   userOrderRepository,
+		userAddressRepository: userAddressRepository,
+		freightRepository:     freightRepository,
 	}
 }
 
 type userOrderService struct {
 	*Service
-	userOrderRepository repository.UserOrderRepository
+	userOrderRepository   repository.UserOrderRepository
+	userAddressRepository repository.UserAddressRepository
+	freightRepository     repository.FreightRepository
 }
 
 func (s *userOrderService) CreateOrders(ctx *gin.Context, req model.CreateOrderRequest) error {
@@ -40,6 +56,20 @@ func (s *userOrderService) CreateOrders(ctx *gin.Context, req model.CreateOrderR
 	return s.userOrderRepository.CreateOrders(ctx, userID, req)
 }
 
+// QuoteFreight 按收货地址计算购物车商品的运费
+func (s *userOrderService) QuoteFreight(ctx *gin.Context, req model.FreightQuoteRequest) (*model.FreightQuote, error) {
+	userID := GetUserIdFromCtx(ctx)
+	address, err := s.userAddressRepository.GetUserAddress(ctx, userID, req.AddressID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("收货地址不存在")
+		}
+		return nil, err
+	}
+	region := model.FreightRegion{Province: address.Province, City: address.City, District: address.District}
+	return s.freightRepository.QuoteFreight(ctx, region, req.Items)
+}
+
 func (s *userOrderService) GetOrderList(ctx *gin.Context, req model.OrderQueryRequest) (*model.OrderListResponse, error) {
 	userID := GetUserIdFromCtx(ctx)
 	return s.userOrderRepository.GetOrderList(ctx, userID, req)
@@ -77,3 +107,25 @@ func (s *userOrderService) GetOrderDetail(ctx *gin.Context, orderItemID uint64)
 	// 调用仓储层获取订单详情
 	return s.userOrderRepository.GetOrderDetail(ctx, userID, orderItemID)
 }
+
+// ExpireUnpaidOrders 关闭超时未支付的订单，返回本次失效的订单项
+func (s *userOrderService) ExpireUnpaidOrders(ctx context.Context) ([]model.UserOrderItem, error) {
+	deadline := time.Now().Add(-s.userOrderRepository.GetPayTimeout(ctx))
+	orderItems, err := s.userOrderRepository.GetExpiredOrderItems(ctx, deadline, _expireOrderBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	expired := make([]model.UserOrderItem, 0, len(orderItems))
+	for i := range orderItems {
+		if err := s.userOrderRepository.ExpireOrderItem(ctx, &orderItems[i]); err != nil {
+			// 订单已被支付或取消
+			if errors.Is(err, v1.ErrOrderStatusChanged) {
+				continue
+			}
+			s.logger.Error("关闭超时订单失败", zap.Uint64("order_item_id", orderItems[i].ID), zap.Error(err))
+			continue
+		}
+		expired = append(expired, orderItems[i])
+	}
+	return expired, nil
+}
@@ -3,30 +3,39 @@ package service
 import (
 	"app/internal/model"
 	"app/internal/repository"
+	"context"
 	"errors"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type WithdrawOrderService interface {
 	CreateWithdraw(ctx *gin.Context, req model.CreateWithdrawRequest) error
 	GetWithdrawList(ctx *gin.Context, req model.WithdrawQueryRequest) (*model.WithdrawListResponse, error)
 	GetWithdrawDetail(ctx *gin.Context, withdrawID uint64) (*model.WithdrawDetailResponse, error)
+	BindPayoutAccount(ctx *gin.Context, req model.BindPayoutAccountRequest) error
+	GetPayoutAccountList(ctx *gin.Context) ([]model.PayoutAccountItem, error)
+	DeletePayoutAccount(ctx *gin.Context, accountID uint64) error
+	SettleWithdrawals(ctx context.Context) error
 }
 
 func NewWithdrawOrderService(
 	service *Service,
 	withdrawOrderRepository repository.WithdrawOrderRepository,
+	payoutAccountRepository repository.PayoutAccountRepository,
 ) WithdrawOrderService {
 	return &withdrawOrderService{
 		Service:                 service,
 		withdrawOrderRepository: withdrawOrderRepository,
+		payoutAccountRepository: payoutAccountRepository,
 	}
 }
 
 type withdrawOrderService struct {
 	*Service
 	withdrawOrderRepository repository.WithdrawOrderRepository
+	payoutAccountRepository repository.PayoutAccountRepository
 }
 
 // CreateWithdraw 创建提现单
@@ -65,3 +74,33 @@ func (s *withdrawOrderService) GetWithdrawDetail(ctx *gin.Context, withdrawID ui
 	// 调用仓储层获取提现单详情
 	return s.withdrawOrderRepository.GetWithdrawDetail(ctx, userID, withdrawID)
 }
+
+// BindPayoutAccount 绑定提现收款账户
+func (s *withdrawOrderService) BindPayoutAccount(ctx *gin.Context, req model.BindPayoutAccountRequest) error {
+	userID := GetUserIdFromCtx(ctx)
+	return s.payoutAccountRepository.BindPayoutAccount(ctx, userID, req)
+}
+
+// GetPayoutAccountList 获取提现收款账户列表
+func (s *withdrawOrderService) GetPayoutAccountList(ctx *gin.Context) ([]model.PayoutAccountItem, error) {
+	userID := GetUserIdFromCtx(ctx)
+	return s.payoutAccountRepository.GetPayoutAccountList(ctx, userID)
+}
+
+// DeletePayoutAccount 删除提现收款账户
+func (s *withdrawOrderService) DeletePayoutAccount(ctx *gin.Context, accountID uint64) error {
+	userID := GetUserIdFromCtx(ctx)
+	return s.payoutAccountRepository.DeletePayoutAccount(ctx, userID, accountID)
+}
+
+// SettleWithdrawals 结算已打款或已拒绝提现单的冻结余额
+func (s *withdrawOrderService) SettleWithdrawals(ctx context.Context) error {
+	settled, err := s.withdrawOrderRepository.SettleWithdrawals(ctx)
+	if err != nil {
+		return err
+	}
+	if settled > 0 {
+		s.logger.Info("提现单结算完成", zap.Int("count", settled))
+	}
+	return nil
+}
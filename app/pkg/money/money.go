@@ -0,0 +1,179 @@
+// Package money 金额类型，以分为单位的整数存储，避免浮点误差
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money 金额（分）
+// 数据库中存储为整数分，JSON 中以元为单位的字符串输出，如 "12.30"
+type Money int64
+
+// Zero 零元
+const Zero Money = 0
+
+var ErrInvalidAmount = errors.New("金额格式不正确")
+
+// FromFen 由分创建金额
+func FromFen(fen int64) Money {
+	return Money(fen)
+}
+
+// FromYuan 由元创建金额，四舍五入到分
+func FromYuan(yuan float64) Money {
+	return Money(math.Round(yuan * 100))
+}
+
+// ParseYuan 解析以元为单位的字符串，最多两位小数
+func ParseYuan(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, nil
+	}
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if len(fracPart) > 2 {
+		// 超出分的部分必须为 0
+		if strings.Trim(fracPart[2:], "0") != "" {
+			return Zero, ErrInvalidAmount
+		}
+		fracPart = fracPart[:2]
+	}
+	fracPart += strings.Repeat("0", 2-len(fracPart))
+	yuan, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return Zero, ErrInvalidAmount
+	}
+	fen, err := strconv.ParseInt(fracPart, 10, 64)
+	if err != nil || fen < 0 {
+		return Zero, ErrInvalidAmount
+	}
+	m := Money(yuan*100 + fen)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// Fen 金额（分）
+func (m Money) Fen() int64 {
+	return int64(m)
+}
+
+// Yuan 金额（元），仅用于展示或与外部浮点接口交互
+func (m Money) Yuan() float64 {
+	return float64(m) / 100
+}
+
+// String 以元为单位格式化，保留两位小数
+func (m Money) String() string {
+	sign := ""
+	fen := int64(m)
+	if fen < 0 {
+		sign = "-"
+		fen = -fen
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, fen/100, fen%100)
+}
+
+// Mul 乘以数量
+func (m Money) Mul(n int) Money {
+	return m * Money(n)
+}
+
+// MulRate 乘以比例，四舍五入到分
+func (m Money) MulRate(rate float64) Money {
+	return Money(math.Round(float64(m) * rate))
+}
+
+// Min 取较小的金额
+func Min(a, b Money) Money {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Max 取较大的金额
+func Max(a, b Money) Money {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Value 实现 driver.Valuer，存储为整数分
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// Scan 实现 sql.Scanner，读取整数分
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = Zero
+	case int64:
+		*m = Money(v)
+	case float64:
+		*m = Money(math.Round(v))
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("money: 无法转换 %T", src)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	if s == "" {
+		*m = Zero
+		return nil
+	}
+	if fen, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*m = Money(fen)
+		return nil
+	}
+	fen, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("money: 无法解析 %q", s)
+	}
+	*m = Money(math.Round(fen))
+	return nil
+}
+
+// MarshalJSON 输出以元为单位的字符串
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + m.String() + `"`), nil
+}
+
+// UnmarshalJSON 兼容以元为单位的字符串和数字
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		*m = Zero
+		return nil
+	}
+	v, err := ParseYuan(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
@@ -0,0 +1,134 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MockSignatureHeader 模拟渠道回调签名头
+const MockSignatureHeader = "Mock-Signature"
+
+// MockProvider 本地联调使用的模拟支付渠道，结果确定、不访问外部服务
+type MockProvider struct {
+	secret string
+	mu     sync.Mutex
+	trades map[string]*Transaction
+}
+
+// mockNotify 模拟渠道的回调内容，明文 JSON
+type mockNotify struct {
+	EventType   string        `json:"event_type"`
+	Transaction *Transaction  `json:"transaction,omitempty"`
+	Refund      *RefundResult `json:"refund,omitempty"`
+}
+
+func NewMockProvider(secret string) *MockProvider {
+	return &MockProvider{
+		secret: secret,
+		trades: make(map[string]*Transaction),
+	}
+}
+
+func (p *MockProvider) Name() string {
+	return ProviderMock
+}
+
+// Prepay 预支付ID由商户订单号确定生成
+func (p *MockProvider) Prepay(ctx context.Context, req *PrepayRequest) (*PrepayResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.trades[req.OutTradeNo]; !ok {
+		p.trades[req.OutTradeNo] = &Transaction{
+			OutTradeNo: req.OutTradeNo,
+			TradeState: TradeStateNotPay,
+			Amount:     req.Amount,
+			Attach:     req.Attach,
+		}
+	}
+	prepayID := "mock_prepay_" + req.OutTradeNo
+	return &PrepayResponse{
+		PrepayID: prepayID,
+		Params: map[string]string{
+			"package":  "prepay_id=" + prepayID,
+			"signType": "MOCK",
+		},
+	}, nil
+}
+
+// VerifyNotify 使用 HMAC-SHA256 校验 Mock-Signature 头
+func (p *MockProvider) VerifyNotify(ctx context.Context, header http.Header, body []byte) (*Notify, error) {
+	signature := header.Get(MockSignatureHeader)
+	if signature == "" || !hmac.Equal([]byte(signature), []byte(MockSign(p.secret, body))) {
+		return nil, ErrInvalidSignature
+	}
+	var notify mockNotify
+	if err := json.Unmarshal(body, &notify); err != nil {
+		return nil, err
+	}
+	if notify.Transaction != nil && notify.Transaction.TradeState == TradeStateSuccess {
+		p.mu.Lock()
+		p.trades[notify.Transaction.OutTradeNo] = notify.Transaction
+		p.mu.Unlock()
+	}
+	return &Notify{
+		EventType:   notify.EventType,
+		Transaction: notify.Transaction,
+		Refund:      notify.Refund,
+	}, nil
+}
+
+func (p *MockProvider) Query(ctx context.Context, outTradeNo string) (*Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	trade, ok := p.trades[outTradeNo]
+	if !ok {
+		return &Transaction{OutTradeNo: outTradeNo, TradeState: TradeStateNotPay}, nil
+	}
+	result := *trade
+	return &result, nil
+}
+
+// Refund 模拟退款直接成功
+func (p *MockProvider) Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error) {
+	if req.Refund <= 0 || req.Refund > req.Total {
+		return nil, errors.New("退款金额不正确")
+	}
+	return &RefundResult{
+		OutTradeNo:  req.OutTradeNo,
+		OutRefundNo: req.OutRefundNo,
+		RefundID:    "mock_refund_" + req.OutRefundNo,
+		Status:      RefundStatusSuccess,
+		Refund:      req.Refund,
+		SuccessAt:   time.Now(),
+	}, nil
+}
+
+// MockSign 计算模拟渠道回调签名，供联调工具构造回调请求
+func MockSign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// MockPaidNotify 构造模拟支付成功回调内容
+func MockPaidNotify(outTradeNo string, amount int64) []byte {
+	body, _ := json.Marshal(mockNotify{
+		EventType: EventTransactionSuccess,
+		Transaction: &Transaction{
+			OutTradeNo:    outTradeNo,
+			TransactionID: "mock_" + outTradeNo + "_" + strconv.FormatInt(amount, 10),
+			TradeState:    TradeStateSuccess,
+			Amount:        amount,
+			PaidAt:        time.Now(),
+		},
+	})
+	return body
+}
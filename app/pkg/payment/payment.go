@@ -0,0 +1,129 @@
+// Package payment 支付渠道抽象，统一下单、回调验签、查单和退款
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	ProviderWechat = "wechat"
+	ProviderMock   = "mock"
+
+	// 交易状态
+	TradeStateSuccess  = "SUCCESS"  // 支付成功
+	TradeStateNotPay   = "NOTPAY"   // 未支付
+	TradeStateClosed   = "CLOSED"   // 已关闭
+	TradeStateRefund   = "REFUND"   // 转入退款
+	TradeStatePayError = "PAYERROR" // 支付失败
+
+	// 退款状态
+	RefundStatusSuccess    = "SUCCESS"    // 退款成功
+	RefundStatusProcessing = "PROCESSING" // 退款处理中
+	RefundStatusClosed     = "CLOSED"     // 退款关闭
+	RefundStatusAbnormal   = "ABNORMAL"   // 退款异常
+
+	// 回调事件类型
+	EventTransactionSuccess = "TRANSACTION.SUCCESS"
+	EventRefundSuccess      = "REFUND.SUCCESS"
+	EventRefundAbnormal     = "REFUND.ABNORMAL"
+	EventRefundClosed       = "REFUND.CLOSED"
+)
+
+var (
+	ErrInvalidSignature = errors.New("支付回调签名校验失败")
+	ErrUnknownProvider  = errors.New("未知的支付渠道")
+	ErrMockNotAllowed   = errors.New("模拟支付渠道只能在本地环境使用")
+)
+
+// Provider 支付渠道，金额单位均为分
+type Provider interface {
+	// Name 渠道名称
+	Name() string
+	// Prepay 预下单，返回客户端调起支付所需的参数
+	Prepay(ctx context.Context, req *PrepayRequest) (*PrepayResponse, error)
+	// VerifyNotify 校验回调签名并解析回调内容，签名不正确时返回 ErrInvalidSignature
+	VerifyNotify(ctx context.Context, header http.Header, body []byte) (*Notify, error)
+	// Query 按商户订单号查询交易
+	Query(ctx context.Context, outTradeNo string) (*Transaction, error)
+	// Refund 申请退款
+	Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error)
+}
+
+// PrepayRequest 预下单请求
+type PrepayRequest struct {
+	OutTradeNo  string    // 商户订单号
+	Description string    // 商品描述
+	Amount      int64     // 金额（分）
+	OpenID      string    // 付款用户OpenID
+	ExpireAt    time.Time // 支付截止时间
+	Attach      string    // 附加数据，回调时原样返回
+}
+
+// PrepayResponse 预下单结果
+type PrepayResponse struct {
+	PrepayID string            `json:"prepay_id"` // 预支付交易会话标识
+	Params   map[string]string `json:"params"`    // 客户端调起支付的参数
+}
+
+// Transaction 交易信息
+type Transaction struct {
+	OutTradeNo    string    `json:"out_trade_no"`   // 商户订单号
+	TransactionID string    `json:"transaction_id"` // 渠道交易号
+	TradeState    string    `json:"trade_state"`    // 交易状态
+	Amount        int64     `json:"amount"`         // 实付金额（分）
+	Attach        string    `json:"attach"`         // 附加数据
+	PaidAt        time.Time `json:"paid_at"`        // 支付完成时间
+}
+
+// RefundRequest 退款请求
+type RefundRequest struct {
+	OutTradeNo  string // 原商户订单号
+	OutRefundNo string // 商户退款单号
+	Reason      string // 退款原因
+	Refund      int64  // 退款金额（分）
+	Total       int64  // 原订单金额（分）
+}
+
+// RefundResult 退款结果
+type RefundResult struct {
+	OutTradeNo  string    `json:"out_trade_no"`  // 原商户订单号
+	OutRefundNo string    `json:"out_refund_no"` // 商户退款单号
+	RefundID    string    `json:"refund_id"`     // 渠道退款单号
+	Status      string    `json:"status"`        // 退款状态
+	Refund      int64     `json:"refund"`        // 退款金额（分）
+	SuccessAt   time.Time `json:"success_at"`    // 退款成功时间
+}
+
+// Notify 已验签的回调内容
+type Notify struct {
+	EventType   string       // 事件类型
+	Transaction *Transaction // 支付结果，支付事件时有值
+	Refund      *RefundResult
+}
+
+// NewProvider 根据配置 payment.provider 创建支付渠道
+// 模拟渠道的回调只校验配置的密钥，只允许在本地环境（env: local）使用，未配置渠道时本地环境默认使用模拟渠道
+func NewProvider(conf *viper.Viper) (Provider, error) {
+	provider := conf.GetString("payment.provider")
+	local := conf.GetString("env") == "local"
+	if provider == "" && local {
+		provider = ProviderMock
+	}
+	switch provider {
+	case ProviderWechat:
+		return NewWechatProvider(conf)
+	case ProviderMock:
+		secret := conf.GetString("payment.mock.secret")
+		if !local || secret == "" {
+			return nil, ErrMockNotAllowed
+		}
+		return NewMockProvider(secret), nil
+	default:
+		return nil, ErrUnknownProvider
+	}
+}
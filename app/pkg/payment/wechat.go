@@ -0,0 +1,421 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	wechatBaseURL = "https://api.mch.weixin.qq.com"
+	// 回调时间戳允许的偏差
+	wechatNotifyTolerance = 5 * time.Minute
+)
+
+// WechatConfig 微信支付 v3 配置
+type WechatConfig struct {
+	AppID           string // 小程序AppID
+	MchID           string // 商户号
+	SerialNo        string // 商户API证书序列号
+	PrivateKey      *rsa.PrivateKey
+	APIv3Key        string // APIv3密钥
+	PublicKeyID     string // 微信支付公钥ID
+	PublicKey       *rsa.PublicKey
+	NotifyURL       string // 支付回调地址
+	RefundNotifyURL string // 退款回调地址
+}
+
+// WechatProvider 微信支付 v3 JSAPI 支付
+type WechatProvider struct {
+	config *WechatConfig
+	client *http.Client
+}
+
+// NewWechatProvider 读取 payment.wechat 配置创建微信支付渠道
+func NewWechatProvider(conf *viper.Viper) (*WechatProvider, error) {
+	privateKey, err := loadPrivateKey(conf.GetString("payment.wechat.private_key_path"))
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := loadPublicKey(conf.GetString("payment.wechat.public_key_path"))
+	if err != nil {
+		return nil, err
+	}
+	return &WechatProvider{
+		config: &WechatConfig{
+			AppID:           conf.GetString("payment.wechat.app_id"),
+			MchID:           conf.GetString("payment.wechat.mch_id"),
+			SerialNo:        conf.GetString("payment.wechat.serial_no"),
+			PrivateKey:      privateKey,
+			APIv3Key:        conf.GetString("payment.wechat.api_v3_key"),
+			PublicKeyID:     conf.GetString("payment.wechat.public_key_id"),
+			PublicKey:       publicKey,
+			NotifyURL:       conf.GetString("payment.notify_url"),
+			RefundNotifyURL: conf.GetString("payment.refund_notify_url"),
+		},
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *WechatProvider) Name() string {
+	return ProviderWechat
+}
+
+// Prepay JSAPI 下单，并生成小程序 wx.requestPayment 所需参数
+func (p *WechatProvider) Prepay(ctx context.Context, req *PrepayRequest) (*PrepayResponse, error) {
+	body := map[string]interface{}{
+		"appid":        p.config.AppID,
+		"mchid":        p.config.MchID,
+		"description":  req.Description,
+		"out_trade_no": req.OutTradeNo,
+		"notify_url":   p.config.NotifyURL,
+		"amount": map[string]interface{}{
+			"total":    req.Amount,
+			"currency": "CNY",
+		},
+		"payer": map[string]interface{}{
+			"openid": req.OpenID,
+		},
+	}
+	if !req.ExpireAt.IsZero() {
+		body["time_expire"] = req.ExpireAt.Format(time.RFC3339)
+	}
+	if req.Attach != "" {
+		body["attach"] = req.Attach
+	}
+	var result struct {
+		PrepayID string `json:"prepay_id"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/v3/pay/transactions/jsapi", body, &result); err != nil {
+		return nil, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := nonceStr()
+	if err != nil {
+		return nil, err
+	}
+	pkg := "prepay_id=" + result.PrepayID
+	paySign, err := p.sign(p.config.AppID + "\n" + timestamp + "\n" + nonce + "\n" + pkg + "\n")
+	if err != nil {
+		return nil, err
+	}
+	return &PrepayResponse{
+		PrepayID: result.PrepayID,
+		Params: map[string]string{
+			"appId":     p.config.AppID,
+			"timeStamp": timestamp,
+			"nonceStr":  nonce,
+			"package":   pkg,
+			"signType":  "RSA",
+			"paySign":   paySign,
+		},
+	}, nil
+}
+
+// VerifyNotify 校验微信支付回调签名，并解密回调资源
+func (p *WechatProvider) VerifyNotify(ctx context.Context, header http.Header, body []byte) (*Notify, error) {
+	if err := p.verify(header, body); err != nil {
+		return nil, err
+	}
+	var notify struct {
+		EventType string `json:"event_type"`
+		Resource  struct {
+			Algorithm      string `json:"algorithm"`
+			Ciphertext     string `json:"ciphertext"`
+			AssociatedData string `json:"associated_data"`
+			Nonce          string `json:"nonce"`
+		} `json:"resource"`
+	}
+	if err := json.Unmarshal(body, &notify); err != nil {
+		return nil, err
+	}
+	plaintext, err := p.decrypt(notify.Resource.Ciphertext, notify.Resource.Nonce, notify.Resource.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Notify{EventType: notify.EventType}
+	switch notify.EventType {
+	case EventTransactionSuccess:
+		var transaction wechatTransaction
+		if err := json.Unmarshal(plaintext, &transaction); err != nil {
+			return nil, err
+		}
+		result.Transaction = transaction.toTransaction()
+	case EventRefundSuccess, EventRefundAbnormal, EventRefundClosed:
+		var refund wechatRefund
+		if err := json.Unmarshal(plaintext, &refund); err != nil {
+			return nil, err
+		}
+		result.Refund = refund.toRefundResult()
+	}
+	return result, nil
+}
+
+// Query 按商户订单号查询订单
+func (p *WechatProvider) Query(ctx context.Context, outTradeNo string) (*Transaction, error) {
+	path := "/v3/pay/transactions/out-trade-no/" + url.PathEscape(outTradeNo) + "?mchid=" + url.QueryEscape(p.config.MchID)
+	var transaction wechatTransaction
+	if err := p.do(ctx, http.MethodGet, path, nil, &transaction); err != nil {
+		return nil, err
+	}
+	return transaction.toTransaction(), nil
+}
+
+// Refund 申请退款
+func (p *WechatProvider) Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error) {
+	body := map[string]interface{}{
+		"out_trade_no":  req.OutTradeNo,
+		"out_refund_no": req.OutRefundNo,
+		"reason":        req.Reason,
+		"amount": map[string]interface{}{
+			"refund":   req.Refund,
+			"total":    req.Total,
+			"currency": "CNY",
+		},
+	}
+	if p.config.RefundNotifyURL != "" {
+		body["notify_url"] = p.config.RefundNotifyURL
+	}
+	var refund wechatRefund
+	if err := p.do(ctx, http.MethodPost, "/v3/refund/domestic/refunds", body, &refund); err != nil {
+		return nil, err
+	}
+	return refund.toRefundResult(), nil
+}
+
+// do 发送签名请求，并校验应答签名
+func (p *WechatProvider) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce, err := nonceStr()
+	if err != nil {
+		return err
+	}
+	signature, err := p.sign(method + "\n" + path + "\n" + timestamp + "\n" + nonce + "\n" + string(payload) + "\n")
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, wechatBaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Wechatpay-Serial", p.config.PublicKeyID)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		`WECHATPAY2-SHA256-RSA2048 mchid="%s",nonce_str="%s",signature="%s",timestamp="%s",serial_no="%s"`,
+		p.config.MchID, nonce, signature, timestamp, p.config.SerialNo,
+	))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var wechatErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &wechatErr)
+		return fmt.Errorf("微信支付请求失败: status=%d code=%s message=%s", resp.StatusCode, wechatErr.Code, wechatErr.Message)
+	}
+	if err := p.verify(resp.Header, respBody); err != nil {
+		return err
+	}
+	if result == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// sign 使用商户私钥进行 SHA256-RSA 签名
+func (p *WechatProvider) sign(message string) (string, error) {
+	hashed := sha256.Sum256([]byte(message))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.config.PrivateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// verify 使用微信支付公钥校验应答或回调签名
+func (p *WechatProvider) verify(header http.Header, body []byte) error {
+	timestamp := header.Get("Wechatpay-Timestamp")
+	nonce := header.Get("Wechatpay-Nonce")
+	signature := header.Get("Wechatpay-Signature")
+	serial := header.Get("Wechatpay-Serial")
+	if timestamp == "" || nonce == "" || signature == "" || serial != p.config.PublicKeyID {
+		return ErrInvalidSignature
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if diff := time.Since(time.Unix(ts, 0)); diff > wechatNotifyTolerance || diff < -wechatNotifyTolerance {
+		return ErrInvalidSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	hashed := sha256.Sum256([]byte(timestamp + "\n" + nonce + "\n" + string(body) + "\n"))
+	if err := rsa.VerifyPKCS1v15(p.config.PublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// decrypt 使用 APIv3 密钥解密 AEAD_AES_256_GCM 资源
+func (p *WechatProvider) decrypt(ciphertext, nonce, associatedData string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher([]byte(p.config.APIv3Key))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, []byte(nonce), data, []byte(associatedData))
+}
+
+type wechatTransaction struct {
+	OutTradeNo    string `json:"out_trade_no"`
+	TransactionID string `json:"transaction_id"`
+	TradeState    string `json:"trade_state"`
+	Attach        string `json:"attach"`
+	SuccessTime   string `json:"success_time"`
+	Amount        struct {
+		Total      int64 `json:"total"`
+		PayerTotal int64 `json:"payer_total"`
+	} `json:"amount"`
+}
+
+func (t *wechatTransaction) toTransaction() *Transaction {
+	paidAt, _ := time.Parse(time.RFC3339, t.SuccessTime)
+	return &Transaction{
+		OutTradeNo:    t.OutTradeNo,
+		TransactionID: t.TransactionID,
+		TradeState:    t.TradeState,
+		Amount:        t.Amount.Total,
+		Attach:        t.Attach,
+		PaidAt:        paidAt,
+	}
+}
+
+type wechatRefund struct {
+	OutTradeNo   string `json:"out_trade_no"`
+	OutRefundNo  string `json:"out_refund_no"`
+	RefundID     string `json:"refund_id"`
+	Status       string `json:"status"`
+	RefundStatus string `json:"refund_status"` // 回调中的退款状态字段
+	SuccessTime  string `json:"success_time"`
+	Amount       struct {
+		Refund int64 `json:"refund"`
+	} `json:"amount"`
+}
+
+func (r *wechatRefund) toRefundResult() *RefundResult {
+	status := r.Status
+	if status == "" {
+		status = r.RefundStatus
+	}
+	successAt, _ := time.Parse(time.RFC3339, r.SuccessTime)
+	return &RefundResult{
+		OutTradeNo:  r.OutTradeNo,
+		OutRefundNo: r.OutRefundNo,
+		RefundID:    r.RefundID,
+		Status:      status,
+		Refund:      r.Amount.Refund,
+		SuccessAt:   successAt,
+	}
+}
+
+func nonceStr() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func loadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("商户私钥不是RSA密钥")
+	}
+	return privateKey, nil
+}
+
+func loadPublicKey(path string) (*rsa.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("微信支付公钥不是RSA密钥")
+	}
+	return publicKey, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("无法解析PEM文件: " + path)
+	}
+	return block, nil
+}